@@ -0,0 +1,134 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// MaxAddressBackfillBlocks is the largest block range that can be scanned for the
+// transactions of a single address.
+const MaxAddressBackfillBlocks = 1000
+
+const (
+	// DefaultAddressPageSize is how many transactions of an address are returned
+	// unless a limit is given.
+	DefaultAddressPageSize = 100
+	// MaxAddressPageSize is the largest limit of a page of transactions.
+	MaxAddressPageSize = 1000
+)
+
+// AddressActivity summarizes the cached transactions of an address and holds a page
+// of them. NextCursor continues with the next page, it is empty on the last page.
+type AddressActivity struct {
+	models.AddressStats
+	Address      string
+	Transactions []*models.Transaction
+	NextCursor   string
+}
+
+// GetAddressActivity retrieves the stats of the cached transactions where the address
+// is the sender, the recipient or the created contract, and up to limit of them after
+// the cursor. A zero limit returns DefaultAddressPageSize transactions.
+func (a *App) GetAddressActivity(address, cursor string, limit int) (*AddressActivity, error) {
+	if !common.IsHexAddress(address) {
+		return nil, ErrBadRequest
+	}
+	address = normalizeAddress(address)
+	if limit == 0 {
+		limit = DefaultAddressPageSize
+	}
+	if limit < 0 || limit > MaxAddressPageSize {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrBadRequest, MaxAddressPageSize)
+	}
+	after, err := parseTransactionCursor(cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := a.db.GetAddressStats(address)
+	if err != nil {
+		return nil, err
+	}
+	// one more transaction tells whether there is a next page
+	transactions, err := a.db.GetTransactionsByAddress(address, after, limit+1)
+	if err != nil {
+		return nil, err
+	}
+
+	activity := &AddressActivity{AddressStats: *stats, Address: address, Transactions: transactions}
+	if len(transactions) > limit {
+		activity.Transactions = transactions[:limit]
+		last := activity.Transactions[limit-1]
+		activity.NextCursor = fmt.Sprintf("%d_%s", last.BlockNumber, last.TxHash)
+	}
+
+	return activity, nil
+}
+
+// parseTransactionCursor parses the NextCursor of an AddressActivity, an empty cursor
+// starts at the first transaction.
+func parseTransactionCursor(cursor string) (*models.TransactionCursor, error) {
+	if cursor == "" {
+		return nil, nil
+	}
+	blockNumber, txHash, ok := strings.Cut(cursor, "_")
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrBadRequest)
+	}
+	number, err := strconv.ParseInt(blockNumber, 10, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrBadRequest)
+	}
+	hash, ok := NormalizeHash(txHash)
+	if !ok {
+		return nil, fmt.Errorf("%w: invalid cursor", ErrBadRequest)
+	}
+
+	return &models.TransactionCursor{BlockNumber: number, TxHash: hash}, nil
+}
+
+// BackfillAddress starts a backfill job that scans the blocks in the inclusive range
+// on the eth node and stores the transactions involving the address. Analysts and
+// admins can backfill addresses, the job is visible to the user that started it.
+func (a *App) BackfillAddress(userID, address string, fromBlock, toBlock int64) (*models.BackfillJob, error) {
+	if !a.hasRole(userID, models.RoleAnalyst) {
+		return nil, ErrForbidden
+	}
+	if !common.IsHexAddress(address) {
+		return nil, ErrBadRequest
+	}
+	if fromBlock < 0 || toBlock < fromBlock {
+		return nil, fmt.Errorf("%w: invalid block range %d-%d", ErrBadRequest, fromBlock, toBlock)
+	}
+	if toBlock-fromBlock+1 > MaxAddressBackfillBlocks {
+		return nil, fmt.Errorf("%w: block range exceeds %d blocks", ErrBadRequest, MaxAddressBackfillBlocks)
+	}
+
+	job := &models.BackfillJob{
+		UserID:     userID,
+		Address:    normalizeAddress(address),
+		FromBlock:  fromBlock,
+		ToBlock:    toBlock,
+		Checkpoint: fromBlock,
+		Status:     models.BackfillStatusRunning,
+	}
+	err := a.db.SaveBackfillJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *job
+	a.runBackfill(job)
+
+	return &started, nil
+}
+
+func involvesAddress(tx *models.Transaction, address string) bool {
+	return normalizeAddress(tx.From) == address ||
+		(tx.To.Valid && normalizeAddress(tx.To.String) == address) ||
+		(tx.ContractAddress.Valid && normalizeAddress(tx.ContractAddress.String) == address)
+}
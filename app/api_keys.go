@@ -0,0 +1,140 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+const (
+	// APIKeyPrefix starts every API key so that leaked keys are easy to recognize.
+	APIKeyPrefix = "ethf_"
+	// apiKeyPrefixLength is the length of the part of a key shown in listings.
+	apiKeyPrefixLength = len(APIKeyPrefix) + 8
+	// maxAPIKeys is the number of API keys a user can have.
+	maxAPIKeys = 20
+	// maxAPIKeyNameLength is the longest name of an API key.
+	maxAPIKeyNameLength = 64
+	// apiKeyTouchInterval is how often the last use of a key is written at most.
+	apiKeyTouchInterval = time.Minute
+)
+
+// CreateAPIKey creates a named API key for the authenticated user, limited to the
+// scopes if there are any and valid until expiresAt if it is set. callerScopes are the
+// scopes of the API key the request is authenticated with, nil if it is not limited.
+// A limited caller can only create keys limited to scopes it holds. The key itself is
+// only returned here, the store keeps its hash.
+func (a *App) CreateAPIKey(userID string, callerScopes []models.Scope, name string, scopes []models.Scope, expiresAt *time.Time) (*models.APIKey, string, error) {
+	if userID == "" {
+		return nil, "", ErrUnauthorized
+	}
+	name = strings.TrimSpace(name)
+	if name == "" || len(name) > maxAPIKeyNameLength {
+		return nil, "", fmt.Errorf("%w: API key names have 1 to %d characters", ErrBadRequest, maxAPIKeyNameLength)
+	}
+	for _, scope := range scopes {
+		if !scope.Valid() {
+			return nil, "", fmt.Errorf("%w: scopes must be one of %v", ErrBadRequest, models.Scopes)
+		}
+	}
+	if callerScopes != nil {
+		if len(scopes) == 0 {
+			return nil, "", fmt.Errorf("%w: an API key limited to scopes cannot create a key with all scopes", ErrForbidden)
+		}
+		for _, scope := range scopes {
+			if !slices.Contains(callerScopes, scope) {
+				return nil, "", fmt.Errorf("%w: the API key lacks the %s scope", ErrForbidden, scope)
+			}
+		}
+	}
+	if expiresAt != nil && !expiresAt.After(time.Now()) {
+		return nil, "", fmt.Errorf("%w: expiresAt is in the past", ErrBadRequest)
+	}
+
+	keys, err := a.db.GetAPIKeys(userID)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(keys) >= maxAPIKeys {
+		return nil, "", fmt.Errorf("%w: users can have at most %d API keys", ErrBadRequest, maxAPIKeys)
+	}
+
+	secret, err := newSecret(APIKeyPrefix)
+	if err != nil {
+		return nil, "", err
+	}
+	scopes = slices.Clone(scopes)
+	slices.Sort(scopes)
+
+	key := &models.APIKey{
+		UserID:    userID,
+		Name:      name,
+		Prefix:    secret[:apiKeyPrefixLength],
+		Hash:      hashSecret(secret),
+		Scopes:    slices.Compact(scopes),
+		ExpiresAt: expiresAt,
+	}
+	err = a.db.CreateAPIKey(key)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return key, secret, nil
+}
+
+// ListAPIKeys returns the API keys of the authenticated user.
+func (a *App) ListAPIKeys(userID string) ([]*models.APIKey, error) {
+	if userID == "" {
+		return nil, ErrUnauthorized
+	}
+
+	return a.db.GetAPIKeys(userID)
+}
+
+// RevokeAPIKey deletes an API key of the authenticated user.
+func (a *App) RevokeAPIKey(userID, id string) error {
+	keys, err := a.ListAPIKeys(userID)
+	if err != nil {
+		return err
+	}
+	if !slices.ContainsFunc(keys, func(key *models.APIKey) bool { return key.ID == id }) {
+		return fmt.Errorf("API key %s %w", id, ErrNotFound)
+	}
+
+	return a.db.DeleteAPIKey(userID, id)
+}
+
+// VerifyAPIKey returns the API key and its user if the key exists, has not expired
+// and the user is not disabled. The last use of the key is recorded.
+func (a *App) VerifyAPIKey(secret string) (*models.APIKey, *models.User, error) {
+	if !strings.HasPrefix(secret, APIKeyPrefix) {
+		return nil, nil, fmt.Errorf("%w: malformed API key", ErrUnauthorized)
+	}
+	key, err := a.db.GetAPIKeyByHash(hashSecret(secret))
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unknown API key", ErrUnauthorized)
+	}
+	now := time.Now()
+	if key.Expired(now) {
+		return nil, nil, fmt.Errorf("%w: API key %s expired", ErrUnauthorized, key.Prefix)
+	}
+	user, err := a.db.GetUserByID(key.UserID)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: unknown API key", ErrUnauthorized)
+	}
+	if user.Disabled {
+		return nil, nil, fmt.Errorf("%w: user %s is disabled", ErrUnauthorized, user.Username)
+	}
+
+	if key.LastUsedAt == nil || now.Sub(*key.LastUsedAt) >= apiKeyTouchInterval {
+		err = a.db.TouchAPIKey(key.ID, now.UTC())
+		if err != nil {
+			a.Log.Errorf("error recording the use of API key %s: %v", key.Prefix, err)
+		}
+		key.LastUsedAt = &now
+	}
+
+	return key, user, nil
+}
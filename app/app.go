@@ -1,55 +1,197 @@
 package app
 
 import (
+	"context"
 	"eth-fetcher/database/models"
+	"fmt"
 	"sync"
+	"time"
 
 	"errors"
 
 	"go.uber.org/zap"
 	"golang.org/x/crypto/bcrypt"
+	"golang.org/x/sync/singleflight"
 )
 
 var ErrUnauthorized = errors.New("unauthorized")
 
 var ErrBadRequest = errors.New("bad request")
 
+var ErrForbidden = errors.New("forbidden")
+
+var ErrNotFound = errors.New("not found")
+
+// ErrOverloaded is returned when too many eth node fetches are pending.
+var ErrOverloaded = errors.New("too many pending eth node fetches")
+
+// ErrTooManyRequests is returned when a user has too many requests in progress.
+var ErrTooManyRequests = errors.New("too many requests")
+
 // App represents the application.
 type App struct {
 	db  DB
 	tg  TransactionGetter
 	Log *zap.SugaredLogger
+
+	// inflight deduplicates concurrent eth node fetches of the same hash.
+	inflight singleflight.Group
+	// limiter bounds the concurrent eth node fetches globally and per user.
+	limiter *fetchLimiter
+
+	backfillConcurrency int
+	backfillsMu         sync.Mutex
+	backfills           map[string]context.CancelCauseFunc
+	backfillsWG         sync.WaitGroup
+
+	lookupJobsMu   sync.Mutex
+	lookupJobs     map[string]*LookupJob
+	lookupJobsWG   sync.WaitGroup
+	lookupJobsDone chan struct{}
+
+	// registration allows anyone to create a user.
+	registration bool
+
+	refreshTokenTTL time.Duration
+
+	retention     RetentionPolicy
+	retentionDone chan struct{}
+	retentionWG   sync.WaitGroup
+
+	signing         SigningKeyPolicy
+	signingKeysMu   sync.RWMutex
+	signingKeys     []*signingKey
+	signingKeysDone chan struct{}
+	signingKeysWG   sync.WaitGroup
+}
+
+// Option configures optional settings of the App.
+type Option func(*App)
+
+// WithBackfillConcurrency sets the number of blocks a backfill job fetches in parallel.
+func WithBackfillConcurrency(n int) Option {
+	return func(a *App) {
+		if n > 0 {
+			a.backfillConcurrency = n
+		}
+	}
+}
+
+// WithNodeFetchLimits limits the concurrent eth node fetches to global in total and
+// to perUser for a single user. Fetches beyond those wait, requests that would make
+// more than maxPending fetches wait or run are rejected with ErrOverloaded.
+func WithNodeFetchLimits(global, perUser, maxPending int) Option {
+	return func(a *App) {
+		a.SetNodeFetchLimits(global, perUser, maxPending)
+	}
+}
+
+// SetNodeFetchLimits changes the limits of WithNodeFetchLimits at runtime, zero values
+// use the defaults. Fetches that already started are not affected.
+func (a *App) SetNodeFetchLimits(global, perUser, maxPending int) {
+	if global <= 0 {
+		global = defaultMaxNodeFetches
+	}
+	if perUser <= 0 {
+		perUser = defaultMaxNodeFetchesPerUser
+	}
+	if maxPending <= 0 {
+		maxPending = defaultMaxPendingNodeFetches
+	}
+	a.limiter.setLimits(global, perUser, maxPending)
 }
 
 // TransactionGetter is an interface for getting transactions.
 type TransactionGetter interface {
 	GetTransaction(txID string) (*models.Transaction, error)
+	GetBlockTransactions(blockNumber int64) ([]*models.Transaction, error)
 	Close()
 }
 
 // DB is an interface for interacting with the database.
 type DB interface {
-	SaveTransaction(transaction *models.Transaction) error
+	SaveTransactions(transactions []*models.Transaction) error
 	GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error)
 	GetAllTransactions() ([]*models.Transaction, error)
+	GetTransactionsPage(after string, limit int) ([]*models.Transaction, error)
+	GetTransactionsByAddress(address string, after *models.TransactionCursor, limit int) ([]*models.Transaction, error)
+	GetAddressStats(address string) (*models.AddressStats, error)
 	AddUserTransactions(userID string, transactions []*models.Transaction) error
 	GetUserTransactions(userID string) ([]*models.Transaction, error)
+	GetUserViews(userID string) ([]*models.TransactionView, error)
+	RestoreUserViews(userID string, views []*models.TransactionView) error
+	PruneTransactions(notViewedSince time.Time, dryRun bool) (int64, error)
+	TrimUserTransactions(maxPerUser int, dryRun bool) (int64, error)
 	GetUserByUsername(username string) (*models.User, error)
+	GetUserByID(userID string) (*models.User, error)
+	GetUserByExternalID(issuer, subject string) (*models.User, error)
+	GetUsers() ([]*models.User, error)
+	CreateUser(user *models.User) error
+	UpdateUserPassword(userID, password string) error
+	SetUserRole(userID string, role models.Role) error
+	SetUserDisabled(userID string, disabled bool) error
+	DeleteUser(userID string) error
+	CreateAPIKey(key *models.APIKey) error
+	GetAPIKeys(userID string) ([]*models.APIKey, error)
+	GetAPIKeyByHash(hash string) (*models.APIKey, error)
+	TouchAPIKey(id string, usedAt time.Time) error
+	DeleteAPIKey(userID, id string) error
+	CreateRefreshToken(token *models.RefreshToken) error
+	GetRefreshTokenByHash(hash string) (*models.RefreshToken, error)
+	UseRefreshToken(id string, usedAt time.Time) error
+	DeleteRefreshTokenFamily(familyID string) error
+	DeleteUserRefreshTokens(userID string) error
+	RevokeToken(token *models.RevokedToken) error
+	IsTokenRevoked(id string) (bool, error)
+	PruneTokens(now time.Time) (int64, error)
+	CreateSigningKey(key *models.SigningKey) error
+	GetSigningKeys() ([]*models.SigningKey, error)
+	DeleteSigningKey(id string) error
+	SaveAddressLabels(labels []*models.AddressLabel) error
+	GetAddressLabels(userID string) ([]*models.AddressLabel, error)
+	FindAddressLabels(userID string, addresses []string) ([]*models.AddressLabel, error)
+	DeleteAddressLabel(userID, address string) error
+	SaveBackfillJob(job *models.BackfillJob) error
+	GetBackfillJob(id string) (*models.BackfillJob, error)
+	GetBackfillJobs(status string) ([]*models.BackfillJob, error)
 	Close() error
 }
 
 // NewApp creates a new instance of the App.
-func NewApp(db DB, tg TransactionGetter, log *zap.SugaredLogger) *App {
-	return &App{
+func NewApp(db DB, tg TransactionGetter, log *zap.SugaredLogger, opts ...Option) *App {
+	a := &App{
 		db:  db,
 		tg:  tg,
 		Log: log,
+
+		limiter: newFetchLimiter(defaultMaxNodeFetches, defaultMaxNodeFetchesPerUser, defaultMaxPendingNodeFetches),
+
+		backfillConcurrency: defaultBackfillConcurrency,
+		refreshTokenTTL:     defaultRefreshTokenTTL,
+		backfills:           make(map[string]context.CancelCauseFunc),
+
+		lookupJobs:     make(map[string]*LookupJob),
+		lookupJobsDone: make(chan struct{}),
+
+		retentionDone:   make(chan struct{}),
+		signingKeysDone: make(chan struct{}),
+	}
+	for _, opt := range opts {
+		opt(a)
 	}
+	a.startRetention()
+	a.startSigningKeyRotation()
+
+	return a
 }
 
 // Shutdown shuts down the application.
 func (a *App) Shutdown() {
 	a.Log.Info("shutting down app")
+	a.stopBackfills()
+	a.stopLookupJobs()
+	a.stopRetention()
+	a.stopSigningKeyRotation()
 	err := a.db.Close()
 	if err != nil {
 		a.Log.Errorf("error closing db: %v", err)
@@ -59,12 +201,19 @@ func (a *App) Shutdown() {
 }
 
 // GetTransactionsByHashes retrieves transactions by their hashes from the database or
-// asynchronously from the eth node.
-func (a *App) GetTransactionsByHashes(transactionHashes []string) ([]*models.Transaction, error) {
+// asynchronously from the eth node. Hashes are normalized to lowercase 0x prefixed hex,
+// malformed hashes are reported with an InvalidHashesError. The node fetches count
+// against the limits of the user, ErrOverloaded is returned if they cannot be queued.
+func (a *App) GetTransactionsByHashes(userID string, transactionHashes []string) ([]*models.Transaction, error) {
 	if len(transactionHashes) == 0 {
 		return nil, ErrBadRequest
 	}
 
+	transactionHashes, err := normalizeHashes(transactionHashes)
+	if err != nil {
+		return nil, err
+	}
+
 	txHashesMap := make(map[string]struct{})
 	for _, txHash := range transactionHashes {
 		txHashesMap[txHash] = struct{}{}
@@ -79,6 +228,15 @@ func (a *App) GetTransactionsByHashes(transactionHashes []string) ([]*models.Tra
 		delete(txHashesMap, tx.TxHash)
 	}
 
+	if len(txHashesMap) == 0 {
+		return txs, nil
+	}
+	err = a.limiter.reserve(len(txHashesMap))
+	if err != nil {
+		return nil, err
+	}
+	defer a.limiter.unreserve(len(txHashesMap))
+
 	var wg sync.WaitGroup
 	wg.Add(len(txHashesMap))
 
@@ -89,7 +247,7 @@ func (a *App) GetTransactionsByHashes(transactionHashes []string) ([]*models.Tra
 		// get transaction from the eth node asynchronously
 		go func(transactionHash string, tChan chan *models.Transaction, ec chan error) {
 			defer wg.Done()
-			transaction, err := a.tg.GetTransaction(transactionHash)
+			transaction, err := a.fetchTransaction(userID, transactionHash)
 			if err != nil {
 				ec <- err
 				return
@@ -102,22 +260,46 @@ func (a *App) GetTransactionsByHashes(transactionHashes []string) ([]*models.Tra
 	close(tChan)
 	close(ec)
 
+	fetched := make([]*models.Transaction, 0, len(tChan))
 	for tx := range tChan {
-		err = a.db.SaveTransaction(tx)
+		fetched = append(fetched, tx)
+	}
+
+	for err := range ec {
+		a.Log.Error(err)
+	}
+
+	// save all fetched transactions with a single upsert
+	err = a.db.SaveTransactions(fetched)
+	if err != nil {
+		a.Log.Errorf("error saving transactions: %v", err)
+	}
 
+	return append(txs, fetched...), nil
+}
+
+// fetchTransaction gets a transaction from the eth node. Concurrent
+// calls for the same hash, also from different requests, share a single fetch which
+// counts against the limits of the user that started it.
+func (a *App) fetchTransaction(userID, transactionHash string) (*models.Transaction, error) {
+	v, err, _ := a.inflight.Do(transactionHash, func() (any, error) {
+		release := a.limiter.acquire(userID)
+		transaction, err := a.tg.GetTransaction(transactionHash)
+		release()
 		if err != nil {
-			a.Log.Errorf("error saving transaction %s: %v", tx.TxHash, err)
-			continue
+			return nil, err
 		}
 
-		txs = append(txs, tx)
+		return transaction, nil
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	for err := range ec {
-		a.Log.Error(err)
-	}
+	// The callers sharing the fetch save and label the transaction, each gets a copy
+	transaction := *v.(*models.Transaction)
 
-	return txs, nil
+	return &transaction, nil
 }
 
 // GetAllTransactions retrieves all transactions.
@@ -149,6 +331,26 @@ func (a *App) CheckUserCredentials(username, password string) (*models.User, err
 	if err != nil {
 		return nil, ErrUnauthorized
 	}
+	if user.Disabled {
+		return nil, fmt.Errorf("%w: user %s is disabled", ErrUnauthorized, username)
+	}
 
 	return user, nil
 }
+
+// isAdmin reports whether the user exists and has admin rights.
+func (a *App) isAdmin(userID string) bool {
+	return a.hasRole(userID, models.RoleAdmin)
+}
+
+// hasRole reports whether the user is enabled and has the permissions of the role.
+func (a *App) hasRole(userID string, role models.Role) bool {
+	if userID == "" {
+		return false
+	}
+	user, err := a.db.GetUserByID(userID)
+	if err != nil {
+		return false
+	}
+	return user.Role.Includes(role) && !user.Disabled
+}
@@ -1,10 +1,20 @@
 package app_test
 
 import (
+	"crypto/ecdsa"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"go.uber.org/zap"
+	"golang.org/x/crypto/bcrypt"
 	"gopkg.in/guregu/null.v4"
 
 	"eth-fetcher/app"
@@ -12,8 +22,14 @@ import (
 	"eth-fetcher/database/models"
 )
 
+const (
+	hash1 = "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"
+	hash2 = "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"
+	hash3 = "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057"
+)
+
 var tx1 = &models.Transaction{
-	TxHash:      "hash1",
+	TxHash:      hash1,
 	TxStatus:    1,
 	BlockHash:   "blockHash1",
 	BlockNumber: 7976373,
@@ -25,7 +41,7 @@ var tx1 = &models.Transaction{
 }
 
 var tx2 = &models.Transaction{
-	TxHash:      "hash2",
+	TxHash:      hash2,
 	TxStatus:    1,
 	BlockHash:   "blockHash2",
 	BlockNumber: 7976373,
@@ -37,7 +53,7 @@ var tx2 = &models.Transaction{
 }
 
 var tx3 = &models.Transaction{
-	TxHash:      "hash3",
+	TxHash:      hash3,
 	TxStatus:    1,
 	BlockHash:   "blockHash3",
 	BlockNumber: 7976373,
@@ -63,7 +79,7 @@ func TestApp_GetTransactionsByHashes(t *testing.T) {
 	db, tg, app := Setup(t)
 
 	// Set up test data
-	transactionHashes := []string{"hash1", "hash2", "hash3"}
+	transactionHashes := []string{hash1, hash2, hash3}
 
 	// Mock the database's GetTransactionsByHashes method
 	db.EXPECT().GetTransactionsByHashes(transactionHashes).Return([]*models.Transaction{
@@ -71,18 +87,18 @@ func TestApp_GetTransactionsByHashes(t *testing.T) {
 		tx2,
 	}, nil)
 
-	tg.EXPECT().GetTransaction("hash3").Return(tx3, nil)
+	tg.EXPECT().GetTransaction(hash3).Return(tx3, nil)
 
-	db.EXPECT().SaveTransaction(tx3).Return(nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx3}).Return(nil)
 
 	// Call the GetTransactionsByHashes method
-	transactions, err := app.GetTransactionsByHashes(transactionHashes)
+	transactions, err := app.GetTransactionsByHashes("", transactionHashes)
 	assert.NoError(t, err)
 	assert.NotNil(t, transactions)
 	assert.Len(t, transactions, 3)
-	assert.Equal(t, "hash1", transactions[0].TxHash)
-	assert.Equal(t, "hash2", transactions[1].TxHash)
-	assert.Equal(t, "hash3", transactions[2].TxHash)
+	assert.Equal(t, hash1, transactions[0].TxHash)
+	assert.Equal(t, hash2, transactions[1].TxHash)
+	assert.Equal(t, hash3, transactions[2].TxHash)
 
 	// Verify that the mock database's GetTransactionsByHashes method was called
 	db.AssertExpectations(t)
@@ -99,7 +115,7 @@ func TestApp_GetTransactionsByHashes_EmptyHashes(t *testing.T) {
 	transactionHashes := []string{}
 
 	// Call the GetTransactionsByHashes method
-	transactions, err := app.GetTransactionsByHashes(transactionHashes)
+	transactions, err := app.GetTransactionsByHashes("", transactionHashes)
 	assert.Error(t, err)
 	assert.Nil(t, transactions)
 
@@ -115,16 +131,16 @@ func TestApp_GetTransactionsByHashes_GetTransactionsByHashesError(t *testing.T)
 	db, tg, app := Setup(t)
 
 	// Set up test data
-	transactionHashes := []string{"hash1"}
+	transactionHashes := []string{hash1}
 
 	// Mock the database's GetTransactionsByHashes method
 	expected := assert.AnError
 	db.EXPECT().GetTransactionsByHashes(transactionHashes).Return(nil, expected)
-	tg.EXPECT().GetTransaction("hash1").Return(tx1, nil)
-	db.EXPECT().SaveTransaction(tx1).Return(nil)
+	tg.EXPECT().GetTransaction(hash1).Return(tx1, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx1}).Return(nil)
 
 	// Call the GetTransactionsByHashes method
-	transactions, err := app.GetTransactionsByHashes(transactionHashes)
+	transactions, err := app.GetTransactionsByHashes("", transactionHashes)
 	assert.NoError(t, err)
 	assert.NotNil(t, transactions)
 	// Verify that the mock database's GetTransactionsByHashes method was called
@@ -134,6 +150,244 @@ func TestApp_GetTransactionsByHashes_GetTransactionsByHashesError(t *testing.T)
 	tg.AssertExpectations(t)
 }
 
+func TestApp_GetTransactionsByHashes_Normalizes(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	// Set up test data with mixed case, a missing 0x prefix and a duplicate
+	transactionHashes := []string{
+		"0x9B2F6A3C2E1AED2CCCF92BA666C22D053AD0D8A5DA7AA1FD5477DCD6577B4524",
+		"5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2",
+		hash1,
+	}
+
+	// Mock the database's GetTransactionsByHashes method with the canonical hashes
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2}).Return([]*models.Transaction{
+		tx1,
+		tx2,
+	}, nil)
+
+	// Call the GetTransactionsByHashes method
+	transactions, err := app.GetTransactionsByHashes("", transactionHashes)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	db.AssertExpectations(t)
+	tg.AssertNotCalled(t, "GetTransaction", mock.Anything)
+}
+
+func TestApp_GetTransactionsByHashes_InvalidHashes(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, a := Setup(t)
+
+	// Set up test data
+	transactionHashes := []string{hash1, "0x1234", "0xzz2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}
+
+	// Call the GetTransactionsByHashes method
+	transactions, err := a.GetTransactionsByHashes("", transactionHashes)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	assert.Nil(t, transactions)
+
+	var invalid *app.InvalidHashesError
+	assert.ErrorAs(t, err, &invalid)
+	assert.Equal(t, transactionHashes[1:], invalid.Hashes)
+
+	// Verify that neither the database nor the eth node were called
+	db.AssertNotCalled(t, "GetTransactionsByHashes", mock.Anything)
+	tg.AssertNotCalled(t, "GetTransaction", mock.Anything)
+}
+
+func TestApp_GetTransactionsByHashes_Coalesced(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	// Neither request finds the transaction in the database
+	db.EXPECT().GetTransactionsByHashes([]string{hash3}).Return(nil, nil).Twice()
+
+	// Block the eth node until both requests are waiting for the transaction
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	tg.EXPECT().GetTransaction(hash3).RunAndReturn(func(string) (*models.Transaction, error) {
+		close(fetching)
+		<-release
+		return tx3, nil
+	}).Once()
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx3}).Return(nil).Twice()
+
+	results := make(chan []*models.Transaction, 2)
+	request := func() {
+		transactions, err := app.GetTransactionsByHashes("", []string{hash3})
+		assert.NoError(t, err)
+		results <- transactions
+	}
+
+	go request()
+	<-fetching
+	go request()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	// Both requests get the transaction from the single fetch
+	for i := 0; i < 2; i++ {
+		assert.Equal(t, []*models.Transaction{tx3}, <-results)
+	}
+
+	db.AssertExpectations(t)
+	tg.AssertExpectations(t)
+}
+
+func TestApp_GetTransactionsByHashes_CoalescedCopies(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash3}).Return(nil, nil).Twice()
+
+	// Hold the fetch until both requests share it
+	fetching := make(chan struct{})
+	release := make(chan struct{})
+	tg.EXPECT().GetTransaction(hash3).RunAndReturn(func(string) (*models.Transaction, error) {
+		close(fetching)
+		<-release
+		return &models.Transaction{TxHash: hash3, From: "0xABC"}, nil
+	}).Once()
+
+	// Saving rewrites the transaction in place like the hooks of the store
+	db.EXPECT().SaveTransactions(mock.Anything).RunAndReturn(func(transactions []*models.Transaction) error {
+		for _, tx := range transactions {
+			tx.LowercaseAddresses()
+		}
+		return nil
+	}).Twice()
+
+	results := make(chan *models.Transaction, 2)
+	request := func() {
+		transactions, err := app.GetTransactionsByHashes("", []string{hash3})
+		assert.NoError(t, err)
+		require.Len(t, transactions, 1)
+		// Read the transaction while the other request saves its own
+		_ = transactions[0].From
+		results <- transactions[0]
+	}
+
+	go request()
+	<-fetching
+	go request()
+	time.Sleep(100 * time.Millisecond)
+	close(release)
+
+	first, second := <-results, <-results
+	assert.NotSame(t, first, second)
+	assert.Equal(t, "0xabc", first.From)
+	assert.Equal(t, "0xabc", second.From)
+
+	tg.AssertExpectations(t)
+}
+
+func TestApp_GetTransactionsByHashes_Overloaded(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 1))
+
+	// The first request holds the only pending fetch until it is released
+	fetching, release := make(chan struct{}), make(chan struct{})
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return(nil, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx1}).Return(nil)
+	tg.EXPECT().GetTransaction(hash1).RunAndReturn(func(string) (*models.Transaction, error) {
+		close(fetching)
+		<-release
+		return tx1, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = a.GetTransactionsByHashes("user1", []string{hash1})
+	}()
+	<-fetching
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash2}).Return(nil, nil)
+	transactions, err := a.GetTransactionsByHashes("user1", []string{hash2})
+	assert.ErrorIs(t, err, app.ErrOverloaded)
+	assert.Nil(t, transactions)
+
+	close(release)
+	<-done
+	tg.AssertNotCalled(t, "GetTransaction", hash2)
+}
+
+func TestApp_GetTransactionsByHashes_MoreThanMaxPending(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 1))
+
+	// Two missing transactions never fit into a queue of one
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2}).Return(nil, nil)
+
+	transactions, err := a.GetTransactionsByHashes("user1", []string{hash1, hash2})
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	assert.ErrorContains(t, err, "at most 1")
+	assert.Nil(t, transactions)
+
+	tg.AssertNotCalled(t, "GetTransaction", mock.Anything)
+}
+
+func TestApp_SetNodeFetchLimits(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 1))
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2}).Return(nil, nil)
+	_, err := a.GetTransactionsByHashes("user1", []string{hash1, hash2})
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	// The raised limits admit the same request
+	a.SetNodeFetchLimits(2, 2, 2)
+	tg.EXPECT().GetTransaction(hash1).Return(tx1, nil)
+	tg.EXPECT().GetTransaction(hash2).Return(tx2, nil)
+	db.EXPECT().SaveTransactions(mock.Anything).Return(nil).Once()
+
+	transactions, err := a.GetTransactionsByHashes("user1", []string{hash1, hash2})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, tx2}, transactions)
+}
+
+func TestApp_GetTransactionsByHashes_PerUserLimit(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(4, 1, 10))
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2, hash3}).Return(nil, nil)
+	db.EXPECT().SaveTransactions(mock.Anything).Return(nil).Once()
+
+	// Track how many fetches of the user run at the same time
+	var running, maxRunning atomic.Int32
+	fetch := func(tx *models.Transaction) func(string) (*models.Transaction, error) {
+		return func(string) (*models.Transaction, error) {
+			n := running.Add(1)
+			defer running.Add(-1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			return tx, nil
+		}
+	}
+	tg.EXPECT().GetTransaction(hash1).RunAndReturn(fetch(tx1))
+	tg.EXPECT().GetTransaction(hash2).RunAndReturn(fetch(tx2))
+	tg.EXPECT().GetTransaction(hash3).RunAndReturn(fetch(tx3))
+
+	transactions, err := a.GetTransactionsByHashes("user1", []string{hash1, hash2, hash3})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, tx2, tx3}, transactions)
+	assert.Equal(t, int32(1), maxRunning.Load())
+}
+
 func TestApp_CheckUserCredentials(t *testing.T) {
 	// Create mock instances of the database and transaction generator
 	db, _, app := Setup(t)
@@ -197,8 +451,8 @@ func TestApp_GetUserTransactions(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, transactions)
 	assert.Len(t, transactions, 2)
-	assert.Equal(t, "hash1", transactions[0].TxHash)
-	assert.Equal(t, "hash2", transactions[1].TxHash)
+	assert.Equal(t, hash1, transactions[0].TxHash)
+	assert.Equal(t, hash2, transactions[1].TxHash)
 
 	// Verify that the mock database's GetUserTransactions method was called
 	db.AssertExpectations(t)
@@ -235,8 +489,8 @@ func TestApp_GetAllTransactions(t *testing.T) {
 	assert.NoError(t, err)
 	assert.NotNil(t, transactions)
 	assert.Len(t, transactions, 2)
-	assert.Equal(t, "hash1", transactions[0].TxHash)
-	assert.Equal(t, "hash2", transactions[1].TxHash)
+	assert.Equal(t, hash1, transactions[0].TxHash)
+	assert.Equal(t, hash2, transactions[1].TxHash)
 
 	// Verify that the mock database's GetAllTransactions method was called
 	db.AssertExpectations(t)
@@ -263,3 +517,1410 @@ func TestApp_AddUserTransactions(t *testing.T) {
 	// Verify that the mock database's AddUserTransactions method was called
 	db.AssertExpectations(t)
 }
+
+func TestApp_LabelTransactions(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, app := Setup(t)
+
+	// Set up test data
+	tx := &models.Transaction{
+		TxHash:          hash1,
+		From:            "0x28C6c06298d514Db089934071355E5743bf21d60",
+		To:              null.NewString("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", true),
+		ContractAddress: null.NewString("0x3664F6c1178E19Bb775b597d6584CaA3B88a1C35", true),
+	}
+	addresses := []string{
+		"0x28c6c06298d514db089934071355e5743bf21d60",
+		"0x7a250d5630b4cf539739df2c5dacb4c659f2488d",
+		"0x3664f6c1178e19bb775b597d6584caa3b88a1c35",
+	}
+
+	// Mock the database's FindAddressLabels method, the private label overrides the global one
+	db.EXPECT().FindAddressLabels("user1", addresses).Return([]*models.AddressLabel{
+		{Address: "0x28c6c06298d514db089934071355e5743bf21d60", Name: "Binance 14"},
+		{Address: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", Name: "my router", UserID: "user1"},
+		{Address: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", Name: "Uniswap V2: Router 2"},
+	}, nil)
+
+	// Call the LabelTransactions method
+	transactions := app.LabelTransactions("user1", []*models.Transaction{tx})
+	assert.Len(t, transactions, 1)
+	assert.Equal(t, "Binance 14", transactions[0].FromLabel)
+	assert.Equal(t, "my router", transactions[0].ToLabel)
+	assert.Empty(t, transactions[0].ContractAddressLabel)
+
+	// Verify that the original transaction was not modified
+	assert.Empty(t, tx.FromLabel)
+
+	db.AssertExpectations(t)
+}
+
+func TestApp_LabelTransactions_FindAddressLabelsError(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, app := Setup(t)
+
+	// Mock the database's FindAddressLabels method
+	db.EXPECT().FindAddressLabels("", []string{"from1", "to1"}).Return(nil, assert.AnError)
+
+	// Call the LabelTransactions method
+	transactions := app.LabelTransactions("", []*models.Transaction{tx1})
+	assert.Equal(t, []*models.Transaction{tx1}, transactions)
+
+	db.AssertExpectations(t)
+}
+
+func TestApp_AddAddressLabel(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, app := Setup(t)
+
+	// Mock the database's SaveAddressLabels method
+	db.EXPECT().SaveAddressLabels([]*models.AddressLabel{
+		{Address: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", Name: "router", UserID: "user1"},
+	}).Return(nil)
+
+	// Call the AddAddressLabel method
+	err := app.AddAddressLabel("user1", &models.AddressLabel{Address: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", Name: " router "}, false)
+	assert.NoError(t, err)
+
+	db.AssertExpectations(t)
+}
+
+func TestApp_AddAddressLabel_GlobalNotAdmin(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Mock the database's GetUserByID method
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1"}, nil)
+
+	// Call the AddAddressLabel method
+	err := a.AddAddressLabel("user1", &models.AddressLabel{Address: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", Name: "router"}, true)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	// Verify that the label was not saved
+	db.AssertNotCalled(t, "SaveAddressLabels", mock.Anything)
+}
+
+func TestApp_ImportAddressLabels_InvalidAddress(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Call the ImportAddressLabels method
+	err := a.ImportAddressLabels([]*models.AddressLabel{{Address: "0x123", Name: "router"}})
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	// Verify that the labels were not saved
+	db.AssertNotCalled(t, "SaveAddressLabels", mock.Anything)
+}
+
+const (
+	address1 = "0x28c6c06298d514db089934071355e5743bf21d60"
+	address2 = "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"
+)
+
+func TestApp_GetAddressActivity(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Set up test data
+	sent := &models.Transaction{TxHash: hash1, BlockNumber: 10, From: address1, To: null.NewString(address2, true), Value: 5}
+	received := &models.Transaction{TxHash: hash2, BlockNumber: 12, From: address2, To: null.NewString(address1, true), Value: 7}
+	stats := models.NewAddressStats()
+	stats.Add(sent, address1)
+	stats.Add(received, address1)
+
+	// Mock the database's GetAddressStats and GetTransactionsByAddress methods
+	db.EXPECT().GetAddressStats(address1).Return(stats, nil)
+	db.EXPECT().GetTransactionsByAddress(address1, (*models.TransactionCursor)(nil), app.DefaultAddressPageSize+1).Return([]*models.Transaction{sent, received}, nil)
+
+	// Call the GetAddressActivity method
+	activity, err := a.GetAddressActivity("0x28C6c06298d514Db089934071355E5743bf21d60", "", 0)
+	assert.NoError(t, err)
+	assert.Equal(t, address1, activity.Address)
+	assert.Len(t, activity.Transactions, 2)
+	assert.Empty(t, activity.NextCursor)
+	assert.Equal(t, 2, activity.TransactionCount)
+	assert.Equal(t, 1, activity.SentCount)
+	assert.Equal(t, 1, activity.ReceivedCount)
+	assert.Equal(t, int64(10), activity.FirstSeenBlock)
+	assert.Equal(t, int64(12), activity.LastSeenBlock)
+	assert.Equal(t, int64(7), activity.TotalValueIn.Int64())
+	assert.Equal(t, int64(5), activity.TotalValueOut.Int64())
+
+	db.AssertExpectations(t)
+}
+
+func TestApp_GetAddressActivity_Pages(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Set up test data
+	first := &models.Transaction{TxHash: hash1, BlockNumber: 10, From: address1}
+	second := &models.Transaction{TxHash: hash2, BlockNumber: 12, From: address1}
+	third := &models.Transaction{TxHash: hash3, BlockNumber: 12, From: address1}
+	stats := models.NewAddressStats()
+	for _, tx := range []*models.Transaction{first, second, third} {
+		stats.Add(tx, address1)
+	}
+	db.EXPECT().GetAddressStats(address1).Return(stats, nil)
+
+	// The first page holds one transaction more than the limit
+	db.EXPECT().GetTransactionsByAddress(address1, (*models.TransactionCursor)(nil), 3).Return([]*models.Transaction{first, second, third}, nil)
+	activity, err := a.GetAddressActivity(address1, "", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{first, second}, activity.Transactions)
+	assert.Equal(t, 3, activity.TransactionCount)
+	assert.Equal(t, "12_"+hash2, activity.NextCursor)
+
+	// The next page starts after the cursor
+	after := &models.TransactionCursor{BlockNumber: 12, TxHash: hash2}
+	db.EXPECT().GetTransactionsByAddress(address1, after, 3).Return([]*models.Transaction{third}, nil)
+	activity, err = a.GetAddressActivity(address1, activity.NextCursor, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{third}, activity.Transactions)
+	assert.Empty(t, activity.NextCursor)
+}
+
+func TestApp_GetAddressActivity_InvalidPage(t *testing.T) {
+	tests := map[string]struct {
+		cursor string
+		limit  int
+	}{
+		"negative limit":       {"", -1},
+		"limit too large":      {"", app.MaxAddressPageSize + 1},
+		"cursor without hash":  {"12", 0},
+		"invalid block number": {"x_" + hash1, 0},
+		"invalid hash":         {"12_0x9b", 0},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, _, a := Setup(t)
+
+			activity, err := a.GetAddressActivity(address1, tt.cursor, tt.limit)
+			assert.ErrorIs(t, err, app.ErrBadRequest)
+			assert.Nil(t, activity)
+
+			db.AssertNotCalled(t, "GetTransactionsByAddress", mock.Anything, mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestApp_GetAddressActivity_InvalidAddress(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, app := Setup(t)
+
+	// Call the GetAddressActivity method
+	activity, err := app.GetAddressActivity("address1", "", 0)
+	assert.Error(t, err)
+	assert.Nil(t, activity)
+
+	db.AssertNotCalled(t, "GetTransactionsByAddress", mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestApp_BackfillAddress(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	// Set up test data
+	related := &models.Transaction{TxHash: hash1, BlockNumber: 10, From: address2, To: null.NewString(address1, true)}
+	unrelated := &models.Transaction{TxHash: hash2, BlockNumber: 11, From: address2, To: null.NewString(address2, true)}
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Role: models.RoleAnalyst}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(10)).Return([]*models.Transaction{related}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(11)).Return([]*models.Transaction{unrelated}, nil)
+
+	// Only the transactions of the address are stored
+	db.EXPECT().SaveTransactions([]*models.Transaction{related}).Return(nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{}).Return(nil)
+
+	// Capture the job once the backfill has finished
+	finished := make(chan models.BackfillJob, 1)
+	db.EXPECT().SaveBackfillJob(mock.Anything).RunAndReturn(func(job *models.BackfillJob) error {
+		if job.ID == "" {
+			job.ID = "job1"
+		}
+		if job.Status != models.BackfillStatusRunning {
+			finished <- *job
+		}
+		return nil
+	})
+
+	// Call the BackfillAddress method
+	job, err := app.BackfillAddress("user1", "0x28C6c06298d514Db089934071355E5743bf21d60", 10, 11)
+	assert.NoError(t, err)
+	assert.Equal(t, "job1", job.ID)
+	assert.Equal(t, "user1", job.UserID)
+	assert.Equal(t, address1, job.Address)
+
+	select {
+	case job := <-finished:
+		assert.Equal(t, models.BackfillStatusCompleted, job.Status)
+		assert.Equal(t, int64(1), job.Transactions)
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill did not finish")
+	}
+}
+
+func TestApp_BackfillAddress_Reader(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Role: models.RoleReader}, nil)
+
+	// Call the BackfillAddress method
+	_, err := a.BackfillAddress("user1", address1, 10, 11)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	db.AssertNotCalled(t, "SaveBackfillJob", mock.Anything)
+}
+
+func TestApp_BackfillAddress_RangeTooLarge(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, a := Setup(t)
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Role: models.RoleAnalyst}, nil)
+
+	// Call the BackfillAddress method
+	_, err := a.BackfillAddress("user1", address1, 1, app.MaxAddressBackfillBlocks+1)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	tg.AssertNotCalled(t, "GetBlockTransactions", mock.Anything)
+}
+
+func TestApp_GetBackfillJob_AddressBackfill(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Role: models.RoleAnalyst}, nil)
+	db.EXPECT().GetUserByID("user2").Return(&models.User{ID: "user2", Role: models.RoleAnalyst}, nil)
+	db.EXPECT().GetBackfillJob("job1").Return(&models.BackfillJob{ID: "job1", UserID: "user1", Address: address1}, nil)
+	db.EXPECT().GetBackfillJob("job2").Return(&models.BackfillJob{ID: "job2", UserID: "user1"}, nil)
+
+	// The analyst sees the address backfill they started
+	job, err := a.GetBackfillJob("user1", "job1")
+	assert.NoError(t, err)
+	assert.Equal(t, "job1", job.ID)
+
+	// Other analysts and backfills of all transactions are hidden
+	_, err = a.GetBackfillJob("user2", "job1")
+	assert.ErrorIs(t, err, app.ErrNotFound)
+	_, err = a.GetBackfillJob("user1", "job2")
+	assert.ErrorIs(t, err, app.ErrNotFound)
+}
+
+func TestApp_StartBackfill(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	// Set up test data
+	first := &models.Transaction{TxHash: hash1, BlockNumber: 10}
+	second := &models.Transaction{TxHash: hash2, BlockNumber: 11}
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(10)).Return([]*models.Transaction{first}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(11)).Return([]*models.Transaction{second}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(12)).Return([]*models.Transaction{}, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{first}).Return(nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{second}).Return(nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{}).Return(nil)
+
+	// Capture the job once the backfill has finished
+	finished := make(chan models.BackfillJob, 1)
+	db.EXPECT().SaveBackfillJob(mock.Anything).RunAndReturn(func(job *models.BackfillJob) error {
+		if job.ID == "" {
+			job.ID = "job1"
+		}
+		if job.Status != models.BackfillStatusRunning {
+			finished <- *job
+		}
+		return nil
+	})
+
+	// Call the StartBackfill method
+	job, err := app.StartBackfill("admin", 10, 12)
+	assert.NoError(t, err)
+	assert.Equal(t, "job1", job.ID)
+	assert.Equal(t, models.BackfillStatusRunning, job.Status)
+
+	select {
+	case job := <-finished:
+		assert.Equal(t, models.BackfillStatusCompleted, job.Status)
+		assert.Equal(t, int64(13), job.Checkpoint)
+		assert.Equal(t, int64(2), job.Transactions)
+		assert.Equal(t, float64(1), job.Progress())
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill did not finish")
+	}
+
+	db.AssertExpectations(t)
+	tg.AssertExpectations(t)
+}
+
+func TestApp_StartBackfill_BlockError(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+	tg.EXPECT().GetBlockTransactions(int64(10)).Return(nil, assert.AnError)
+
+	// Capture the job once the backfill has finished
+	finished := make(chan models.BackfillJob, 1)
+	db.EXPECT().SaveBackfillJob(mock.Anything).RunAndReturn(func(job *models.BackfillJob) error {
+		if job.Status != models.BackfillStatusRunning {
+			finished <- *job
+		}
+		return nil
+	})
+
+	// Call the StartBackfill method
+	_, err := app.StartBackfill("admin", 10, 10)
+	assert.NoError(t, err)
+
+	select {
+	case job := <-finished:
+		assert.Equal(t, models.BackfillStatusFailed, job.Status)
+		assert.Equal(t, int64(10), job.Checkpoint)
+		assert.Contains(t, job.Error, assert.AnError.Error())
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill did not finish")
+	}
+
+	// Verify that the block was retried
+	tg.AssertNumberOfCalls(t, "GetBlockTransactions", 3)
+}
+
+func TestApp_StartBackfill_NotAdmin(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1"}, nil)
+
+	// Call the StartBackfill method
+	job, err := a.StartBackfill("user1", 10, 12)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+	assert.Nil(t, job)
+
+	db.AssertNotCalled(t, "SaveBackfillJob", mock.Anything)
+}
+
+func TestApp_CancelBackfill(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, app := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+
+	// Block the first block until the job is cancelled
+	release := make(chan struct{})
+	tg.EXPECT().GetBlockTransactions(mock.Anything).RunAndReturn(func(int64) ([]*models.Transaction, error) {
+		<-release
+		return nil, nil
+	}).Maybe()
+	// Blocks fetched before the cancellation are still saved
+	db.EXPECT().SaveTransactions(mock.Anything).Return(nil).Maybe()
+
+	finished := make(chan models.BackfillJob, 1)
+	db.EXPECT().SaveBackfillJob(mock.Anything).RunAndReturn(func(job *models.BackfillJob) error {
+		if job.ID == "" {
+			job.ID = "job1"
+		}
+		if job.Status != models.BackfillStatusRunning {
+			finished <- *job
+		}
+		return nil
+	})
+
+	job, err := app.StartBackfill("admin", 0, 1000000)
+	assert.NoError(t, err)
+
+	// Call the CancelBackfill method
+	err = app.CancelBackfill("admin", job.ID)
+	assert.NoError(t, err)
+	close(release)
+
+	select {
+	case job := <-finished:
+		assert.Equal(t, models.BackfillStatusCancelled, job.Status)
+		assert.Less(t, job.Checkpoint, int64(1000000))
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill was not cancelled")
+	}
+}
+
+func TestApp_ResumeBackfill_Concurrent(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, a := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+
+	// Hold the first resume while it reads the job
+	reading := make(chan struct{})
+	read := make(chan struct{})
+	db.EXPECT().GetBackfillJob("job1").RunAndReturn(func(string) (*models.BackfillJob, error) {
+		close(reading)
+		<-read
+		return &models.BackfillJob{ID: "job1", FromBlock: 10, ToBlock: 10, Checkpoint: 10, Status: models.BackfillStatusCancelled}, nil
+	}).Once()
+	tg.EXPECT().GetBlockTransactions(int64(10)).Return([]*models.Transaction{}, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{}).Return(nil)
+
+	finished := make(chan models.BackfillJob, 1)
+	db.EXPECT().SaveBackfillJob(mock.Anything).RunAndReturn(func(job *models.BackfillJob) error {
+		if job.Status != models.BackfillStatusRunning {
+			finished <- *job
+		}
+		return nil
+	})
+
+	resumed := make(chan error, 1)
+	go func() {
+		_, err := a.ResumeBackfill("admin", "job1")
+		resumed <- err
+	}()
+	<-reading
+
+	// The job is claimed by the first resume, the second one is rejected
+	job, err := a.ResumeBackfill("admin", "job1")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	assert.Nil(t, job)
+
+	close(read)
+	assert.NoError(t, <-resumed)
+
+	select {
+	case job := <-finished:
+		assert.Equal(t, models.BackfillStatusCompleted, job.Status)
+	case <-time.After(5 * time.Second):
+		t.Fatal("backfill did not finish")
+	}
+}
+
+func TestApp_ResumeBackfill_NotResumable(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+	db.EXPECT().GetBackfillJob("job1").Return(&models.BackfillJob{ID: "job1", Status: models.BackfillStatusCompleted}, nil)
+
+	_, err := a.ResumeBackfill("admin", "job1")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	// The job is released, so resuming it reads it again
+	_, err = a.ResumeBackfill("admin", "job1")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	db.AssertNumberOfCalls(t, "GetBackfillJob", 2)
+	db.AssertNotCalled(t, "SaveBackfillJob", mock.Anything)
+}
+
+func TestApp_StartLookupJob(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Mock the database's GetTransactionsByHashes and AddUserTransactions methods
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2}).Return([]*models.Transaction{tx1, tx2}, nil)
+	added := make(chan struct{})
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1, tx2}).RunAndReturn(func(string, []*models.Transaction) error {
+		close(added)
+		return nil
+	})
+
+	// Call the StartLookupJob method
+	job, err := a.StartLookupJob("user1", []string{hash1, hash2})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, job.ID)
+	assert.Equal(t, 2, job.Total)
+
+	select {
+	case <-added:
+	case <-time.After(5 * time.Second):
+		t.Fatal("lookup job did not finish")
+	}
+
+	// Poll the job until it is marked as completed
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status == app.LookupJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, []*models.Transaction{tx1, tx2}, job.Transactions)
+
+	db.AssertExpectations(t)
+}
+
+func TestApp_GetLookupJob_OtherUser(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return([]*models.Transaction{tx1}, nil)
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1}).Return(nil)
+
+	job, err := a.StartLookupJob("user1", []string{hash1})
+	assert.NoError(t, err)
+
+	// Call the GetLookupJob method as another user
+	_, err = a.GetLookupJob("user2", job.ID)
+	assert.ErrorIs(t, err, app.ErrNotFound)
+
+	// Wait for the job so the expectations are met
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status == app.LookupJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestApp_StartLookupJob_Overloaded(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 1))
+
+	// Another request holds the only pending fetch until the job was rejected once
+	fetching, release := make(chan struct{}), make(chan struct{})
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return(nil, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx1}).Return(nil)
+	tg.EXPECT().GetTransaction(hash1).RunAndReturn(func(string) (*models.Transaction, error) {
+		close(fetching)
+		<-release
+		return tx1, nil
+	})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		_, _ = a.GetTransactionsByHashes("user2", []string{hash1})
+	}()
+	<-fetching
+
+	var lookups atomic.Int32
+	db.EXPECT().GetTransactionsByHashes([]string{hash2}).RunAndReturn(func([]string) ([]*models.Transaction, error) {
+		if lookups.Add(1) == 1 {
+			close(release)
+		}
+		return nil, nil
+	})
+	tg.EXPECT().GetTransaction(hash2).Return(tx2, nil)
+	db.EXPECT().SaveTransactions([]*models.Transaction{tx2}).Return(nil)
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx2}).Return(nil)
+
+	job, err := a.StartLookupJob("user1", []string{hash2})
+	assert.NoError(t, err)
+
+	// The job retries the batch once the queue has room
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status != app.LookupJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, app.LookupJobCompleted, job.Status)
+	assert.Equal(t, []*models.Transaction{tx2}, job.Transactions)
+	assert.Empty(t, job.FailedHashes)
+	assert.Greater(t, lookups.Load(), int32(1))
+	<-done
+}
+
+func TestApp_StartLookupJob_Failed(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 1))
+
+	// Two missing transactions never fit into a queue of one
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2}).Return(nil, nil)
+
+	job, err := a.StartLookupJob("user1", []string{hash1, hash2})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status != app.LookupJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, app.LookupJobFailed, job.Status)
+	assert.Equal(t, 2, job.Processed)
+	assert.Equal(t, []string{hash1, hash2}, job.FailedHashes)
+	tg.AssertNotCalled(t, "GetTransaction", mock.Anything)
+}
+
+func TestApp_StartLookupJob_Partial(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithNodeFetchLimits(1, 1, 10))
+
+	// The first batch is missing from the db and larger than the queue, the second
+	// batch is found in the db
+	hashes := make([]string, 0, 51)
+	for i := 0; i < 50; i++ {
+		hashes = append(hashes, fmt.Sprintf("0x%064x", i))
+	}
+	hashes = append(hashes, hash1)
+	db.EXPECT().GetTransactionsByHashes(hashes[:50]).Return(nil, nil)
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return([]*models.Transaction{tx1}, nil)
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1}).Return(nil)
+
+	job, err := a.StartLookupJob("user1", hashes)
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status != app.LookupJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, app.LookupJobPartial, job.Status)
+	assert.Equal(t, 51, job.Processed)
+	assert.Equal(t, []*models.Transaction{tx1}, job.Transactions)
+	assert.Equal(t, hashes[:50], job.FailedHashes)
+}
+
+func TestApp_StartLookupJob_NodeFailures(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, tg, a := Setup(t)
+
+	// The node fails to fetch one hash and does not know the other
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash2, hash3}).Return([]*models.Transaction{tx1}, nil)
+	tg.EXPECT().GetTransaction(hash2).Return(nil, assert.AnError)
+	tg.EXPECT().GetTransaction(hash3).Return(nil, errors.New("not found"))
+	db.EXPECT().SaveTransactions(mock.Anything).Return(nil)
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1}).Return(nil)
+
+	job, err := a.StartLookupJob("user1", []string{hash1, hash2, hash3})
+	assert.NoError(t, err)
+
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status != app.LookupJobRunning
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, app.LookupJobPartial, job.Status)
+	assert.Equal(t, []*models.Transaction{tx1}, job.Transactions)
+	assert.ElementsMatch(t, []string{hash2, hash3}, job.FailedHashes)
+}
+
+func TestApp_StartLookupJob_TooManyRunning(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	// Hold the jobs in their first batch
+	release := make(chan struct{})
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).RunAndReturn(func([]string) ([]*models.Transaction, error) {
+		<-release
+		return []*models.Transaction{tx1}, nil
+	})
+	db.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1}).Return(nil)
+
+	for i := 0; i < app.MaxRunningLookupJobs; i++ {
+		_, err := a.StartLookupJob("user1", []string{hash1})
+		require.NoError(t, err)
+	}
+
+	// The user has to wait for a job to finish, other users are not limited by it
+	_, err := a.StartLookupJob("user1", []string{hash1})
+	assert.ErrorIs(t, err, app.ErrTooManyRequests)
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash2}).Return([]*models.Transaction{tx2}, nil)
+	db.EXPECT().AddUserTransactions("user2", []*models.Transaction{tx2}).Return(nil)
+	job, err := a.StartLookupJob("user2", []string{hash2})
+	assert.NoError(t, err)
+
+	close(release)
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user2", job.ID)
+		return err == nil && job.Status == app.LookupJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		job, err = a.StartLookupJob("user1", []string{hash1})
+		return err == nil
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, func() bool {
+		job, err = a.GetLookupJob("user1", job.ID)
+		return err == nil && job.Status == app.LookupJobCompleted
+	}, 5*time.Second, 10*time.Millisecond)
+}
+
+func TestApp_StartLookupJob_EmptyHashes(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	_, _, app := Setup(t)
+
+	// Call the StartLookupJob method
+	job, err := app.StartLookupJob("", []string{})
+	assert.Error(t, err)
+	assert.Nil(t, job)
+}
+
+func TestApp_Prune(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithRetention(app.RetentionPolicy{
+		TransactionTTL: 90 * 24 * time.Hour,
+		MaxUserHistory: 100,
+	}))
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Role: models.RoleAdmin}, nil)
+	db.EXPECT().TrimUserTransactions(100, true).Return(3, nil)
+	db.EXPECT().PruneTransactions(mock.MatchedBy(func(cutoff time.Time) bool {
+		return time.Since(cutoff).Round(time.Hour) == 90*24*time.Hour
+	}), true).Return(5, nil)
+
+	// Call the Prune method
+	report, err := a.Prune("admin", true)
+	assert.NoError(t, err)
+	assert.Equal(t, &app.PruneReport{DryRun: true, UserTransactions: 3, Transactions: 5}, report)
+}
+
+func TestApp_Prune_Forbidden(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1"}, nil)
+
+	// Call the Prune method as a regular user
+	report, err := a.Prune("user1", false)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+	assert.Nil(t, report)
+}
+
+func TestApp_Retention(t *testing.T) {
+	db := mocks.NewDB(t)
+	tg := mocks.NewTransactionGetter(t)
+	logger, _ := zap.NewProduction()
+
+	// The policy is applied in the background without a dry run
+	pruned := make(chan struct{}, 1)
+	db.EXPECT().PruneTransactions(mock.Anything, false).RunAndReturn(func(time.Time, bool) (int64, error) {
+		select {
+		case pruned <- struct{}{}:
+		default:
+		}
+		return 1, nil
+	})
+	db.EXPECT().Close().Return(nil)
+	tg.EXPECT().Close()
+
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithRetention(app.RetentionPolicy{
+		TransactionTTL: time.Hour,
+		Interval:       10 * time.Millisecond,
+	}))
+
+	select {
+	case <-pruned:
+	case <-time.After(5 * time.Second):
+		t.Fatal("retention policy was not applied")
+	}
+
+	a.Shutdown()
+}
+
+func TestApp_CreateUser(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByUsername("erin").Return(nil, app.ErrNotFound)
+	db.EXPECT().CreateUser(mock.Anything).RunAndReturn(func(user *models.User) error {
+		user.ID = "user5"
+		return nil
+	})
+
+	// Call the CreateUser method
+	user, err := a.CreateUser(" erin ", "supersecret", models.RoleAdmin)
+	assert.NoError(t, err)
+	assert.Equal(t, "user5", user.ID)
+	assert.Equal(t, "erin", user.Username)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("supersecret")))
+}
+
+func TestApp_CreateUser_Invalid(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByUsername("alice").Return(&models.User{ID: "user1", Username: "alice"}, nil)
+	db.EXPECT().GetUserByUsername("erin").Return(nil, app.ErrNotFound)
+
+	// Usernames are unique
+	_, err := a.CreateUser("alice", "supersecret", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	_, err = a.CreateUser("", "supersecret", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	_, err = a.CreateUser("erin", "short", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+}
+
+func TestApp_ResetPassword(t *testing.T) {
+	// Create mock instances of the database and transaction generator
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByUsername("bob").Return(&models.User{ID: "user2", Username: "bob"}, nil)
+	db.EXPECT().UpdateUserPassword("user2", mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword")) == nil
+	})).Return(nil)
+	db.EXPECT().DeleteUserRefreshTokens("user2").Return(nil)
+	db.EXPECT().GetUserByUsername("mallory").Return(nil, app.ErrNotFound)
+
+	// Call the ResetPassword method
+	err := a.ResetPassword("bob", "newpassword")
+	assert.NoError(t, err)
+
+	err = a.ResetPassword("mallory", "newpassword")
+	assert.ErrorIs(t, err, app.ErrNotFound)
+}
+
+func TestApp_CreateUser_PasswordPolicy(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByUsername("erin").Return(nil, app.ErrNotFound)
+
+	for _, password := range []string{"short", "erin-2024", "Password1", strings.Repeat("x", 73)} {
+		_, err := a.CreateUser("erin", password, models.RoleReader)
+		assert.ErrorIs(t, err, app.ErrBadRequest, password)
+	}
+
+	_, err := a.CreateUser("e r", "supersecret", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+}
+
+func TestApp_CheckUserCredentials_Disabled(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByUsername("user1").Return(&models.User{
+		Username: "user1",
+		Password: "$2a$10$RmIhxSs.xMrqUT0xU4v/wuAdH97Kmb.l50AcSQVfkg/nPVyGxp1cu",
+		Disabled: true,
+	}, nil)
+
+	_, err := a.CheckUserCredentials("user1", "password1")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_Register(t *testing.T) {
+	db, tg, a := Setup(t)
+
+	// Registration is disabled by default
+	_, err := a.Register("erin", "supersecret")
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	logger, _ := zap.NewProduction()
+	a = app.NewApp(db, tg, logger.Sugar(), app.WithRegistration(true))
+	db.EXPECT().GetUserByUsername("erin").Return(nil, app.ErrNotFound)
+	db.EXPECT().CreateUser(mock.Anything).Return(nil)
+
+	user, err := a.Register("erin", "supersecret")
+	assert.NoError(t, err)
+	assert.Equal(t, "erin", user.Username)
+	assert.Equal(t, models.RoleReader, user.Role)
+}
+
+func TestApp_ChangePassword(t *testing.T) {
+	db, _, a := Setup(t)
+
+	user := &models.User{
+		ID:       "user1",
+		Username: "user1",
+		Password: "$2a$10$RmIhxSs.xMrqUT0xU4v/wuAdH97Kmb.l50AcSQVfkg/nPVyGxp1cu",
+	}
+	db.EXPECT().GetUserByID("user1").Return(user, nil)
+	db.EXPECT().UpdateUserPassword("user1", mock.MatchedBy(func(hash string) bool {
+		return bcrypt.CompareHashAndPassword([]byte(hash), []byte("newpassword")) == nil
+	})).Return(nil).Once()
+	db.EXPECT().DeleteUserRefreshTokens("user1").Return(nil).Once()
+
+	err := a.ChangePassword("user1", "wrong", "newpassword")
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	err = a.ChangePassword("user1", "password1", "short")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	err = a.ChangePassword("user1", "password1", "newpassword")
+	assert.NoError(t, err)
+
+	err = a.ChangePassword("", "password1", "newpassword")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_ManageUsers(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Username: "alice", Role: models.RoleAdmin}, nil)
+	db.EXPECT().GetUserByID("user2").Return(&models.User{ID: "user2", Username: "bob"}, nil)
+	db.EXPECT().GetUserByID("unknown").Return(nil, app.ErrNotFound)
+
+	// Only admins manage users
+	_, err := a.ListUsers("user2")
+	assert.ErrorIs(t, err, app.ErrForbidden)
+	err = a.DeleteUser("user2", "admin")
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	db.EXPECT().SetUserDisabled("user2", true).Return(nil)
+	user, err := a.SetUserDisabled("admin", "user2", true)
+	assert.NoError(t, err)
+	assert.True(t, user.Disabled)
+
+	db.EXPECT().UpdateUserPassword("user2", mock.Anything).Return(nil)
+	db.EXPECT().DeleteUserRefreshTokens("user2").Return(nil)
+	err = a.ResetUserPassword("admin", "user2", "newpassword")
+	assert.NoError(t, err)
+
+	db.EXPECT().DeleteUser("user2").Return(nil)
+	err = a.DeleteUser("admin", "user2")
+	assert.NoError(t, err)
+
+	err = a.DeleteUser("admin", "unknown")
+	assert.ErrorIs(t, err, app.ErrNotFound)
+
+	// Admins cannot lock themselves out
+	_, err = a.SetUserDisabled("admin", "admin", true)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	err = a.DeleteUser("admin", "admin")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+}
+
+func TestApp_SetUserRole(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUserByID("admin").Return(&models.User{ID: "admin", Username: "alice", Role: models.RoleAdmin}, nil)
+	db.EXPECT().GetUserByID("user2").Return(&models.User{ID: "user2", Username: "bob", Role: models.RoleReader}, nil)
+
+	// Only admins change roles
+	_, err := a.SetUserRole("user2", "user2", models.RoleAdmin)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	_, err = a.SetUserRole("admin", "user2", "owner")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	db.EXPECT().SetUserRole("user2", models.RoleAnalyst).Return(nil)
+	user, err := a.SetUserRole("admin", "user2", models.RoleAnalyst)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAnalyst, user.Role)
+
+	// Admins cannot demote themselves
+	_, err = a.SetUserRole("admin", "admin", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	_, err = a.CreateUser("erin", "supersecret", "owner")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+}
+
+func TestApp_CreateAPIKey(t *testing.T) {
+	db, _, a := Setup(t)
+
+	var stored *models.APIKey
+	db.EXPECT().GetAPIKeys("user1").Return(nil, nil)
+	db.EXPECT().CreateAPIKey(mock.Anything).RunAndReturn(func(key *models.APIKey) error {
+		stored = key
+		return nil
+	})
+
+	expiresAt := time.Now().Add(time.Hour)
+	scopes := []models.Scope{models.ScopeTransactions, models.ScopeLabels, models.ScopeTransactions}
+	key, secret, err := a.CreateAPIKey("user1", nil, " batch ", scopes, &expiresAt)
+	assert.NoError(t, err)
+	assert.Same(t, stored, key)
+	assert.True(t, strings.HasPrefix(secret, app.APIKeyPrefix))
+	assert.Equal(t, secret[:len(key.Prefix)], key.Prefix)
+	assert.Equal(t, "batch", key.Name)
+	assert.Equal(t, []models.Scope{models.ScopeLabels, models.ScopeTransactions}, key.Scopes)
+	// Only the hash of the key is stored
+	assert.NotEqual(t, secret, key.Hash)
+	assert.Len(t, key.Hash, 64)
+
+	_, _, err = a.CreateAPIKey("user1", nil, "", nil, nil)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	_, _, err = a.CreateAPIKey("user1", nil, "batch", []models.Scope{"everything"}, nil)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	past := time.Now().Add(-time.Hour)
+	_, _, err = a.CreateAPIKey("user1", nil, "batch", nil, &past)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+	_, _, err = a.CreateAPIKey("", nil, "batch", nil, nil)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_CreateAPIKey_ScopedCaller(t *testing.T) {
+	db, _, a := Setup(t)
+	caller := []models.Scope{models.ScopeAccount, models.ScopeLabels}
+
+	// A limited key cannot create a key with all scopes or with scopes it lacks
+	_, _, err := a.CreateAPIKey("user1", caller, "batch", nil, nil)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+	_, _, err = a.CreateAPIKey("user1", caller, "batch", []models.Scope{}, nil)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+	_, _, err = a.CreateAPIKey("user1", caller, "batch", []models.Scope{models.ScopeLabels, models.ScopeAdmin}, nil)
+	assert.ErrorIs(t, err, app.ErrForbidden)
+
+	db.EXPECT().GetAPIKeys("user1").Return(nil, nil)
+	db.EXPECT().CreateAPIKey(mock.Anything).Return(nil)
+	key, _, err := a.CreateAPIKey("user1", caller, "batch", []models.Scope{models.ScopeLabels}, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []models.Scope{models.ScopeLabels}, key.Scopes)
+}
+
+func TestApp_VerifyAPIKey(t *testing.T) {
+	db, _, a := Setup(t)
+
+	var stored *models.APIKey
+	db.EXPECT().GetAPIKeys("user1").Return(nil, nil)
+	db.EXPECT().CreateAPIKey(mock.Anything).RunAndReturn(func(key *models.APIKey) error {
+		key.ID = "key1"
+		stored = key
+		return nil
+	})
+	_, secret, err := a.CreateAPIKey("user1", nil, "batch", nil, nil)
+	assert.NoError(t, err)
+
+	db.EXPECT().GetAPIKeyByHash(stored.Hash).RunAndReturn(func(string) (*models.APIKey, error) {
+		key := *stored
+		return &key, nil
+	})
+	db.EXPECT().GetAPIKeyByHash(mock.Anything).Return(nil, app.ErrNotFound)
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Username: "carol", Role: models.RoleReader}, nil).Once()
+
+	// The first use is recorded
+	db.EXPECT().TouchAPIKey("key1", mock.Anything).RunAndReturn(func(_ string, usedAt time.Time) error {
+		stored.LastUsedAt = &usedAt
+		return nil
+	}).Once()
+	key, user, err := a.VerifyAPIKey(secret)
+	assert.NoError(t, err)
+	assert.Equal(t, "key1", key.ID)
+	assert.Equal(t, "user1", user.ID)
+
+	// Uses within a minute are not recorded again
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Username: "carol", Role: models.RoleReader}, nil).Once()
+	_, _, err = a.VerifyAPIKey(secret)
+	assert.NoError(t, err)
+
+	_, _, err = a.VerifyAPIKey(app.APIKeyPrefix + "unknown")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+	_, _, err = a.VerifyAPIKey("not-a-key")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	// Keys of disabled users are rejected
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Username: "carol", Disabled: true}, nil).Once()
+	_, _, err = a.VerifyAPIKey(secret)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	// Expired keys are rejected
+	expired := time.Now().Add(-time.Second)
+	stored.ExpiresAt = &expired
+	_, _, err = a.VerifyAPIKey(secret)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_RevokeAPIKey(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetAPIKeys("user1").Return([]*models.APIKey{{ID: "key1", UserID: "user1"}}, nil)
+	db.EXPECT().DeleteAPIKey("user1", "key1").Return(nil)
+
+	assert.NoError(t, a.RevokeAPIKey("user1", "key1"))
+	assert.ErrorIs(t, a.RevokeAPIKey("user1", "key2"), app.ErrNotFound)
+	assert.ErrorIs(t, a.RevokeAPIKey("", "key1"), app.ErrUnauthorized)
+}
+
+// storeRefreshTokens keeps the refresh tokens created through the DB mock by hash.
+func storeRefreshTokens(db *mocks.DB) map[string]*models.RefreshToken {
+	tokens := map[string]*models.RefreshToken{}
+	db.EXPECT().CreateRefreshToken(mock.Anything).RunAndReturn(func(token *models.RefreshToken) error {
+		token.ID = fmt.Sprintf("token%d", len(tokens)+1)
+		tokens[token.Hash] = token
+		return nil
+	})
+	db.EXPECT().GetRefreshTokenByHash(mock.Anything).RunAndReturn(func(hash string) (*models.RefreshToken, error) {
+		token, ok := tokens[hash]
+		if !ok {
+			return nil, app.ErrNotFound
+		}
+		stored := *token
+		return &stored, nil
+	})
+	return tokens
+}
+
+func TestApp_RefreshSession(t *testing.T) {
+	db, _, a := Setup(t)
+
+	tokens := storeRefreshTokens(db)
+	db.EXPECT().PruneTokens(mock.Anything).Return(0, nil)
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Username: "carol", Role: models.RoleReader}, nil)
+	db.EXPECT().UseRefreshToken(mock.Anything, mock.Anything).RunAndReturn(func(id string, usedAt time.Time) error {
+		for _, token := range tokens {
+			if token.ID == id {
+				token.UsedAt = &usedAt
+			}
+		}
+		return nil
+	})
+
+	first, err := a.IssueRefreshToken("user1")
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(first, app.RefreshTokenPrefix))
+
+	// Refreshing rotates the token within its family
+	user, second, err := a.RefreshSession(first)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", user.ID)
+	assert.NotEqual(t, first, second)
+	assert.Len(t, tokens, 2)
+	familyID := tokens[hashOf(t, tokens, "token1")].FamilyID
+	assert.Equal(t, familyID, tokens[hashOf(t, tokens, "token2")].FamilyID)
+
+	// Reusing a token revokes its family
+	db.EXPECT().DeleteRefreshTokenFamily(familyID).Return(nil).Once()
+	_, _, err = a.RefreshSession(first)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	_, _, err = a.RefreshSession(app.RefreshTokenPrefix + "unknown")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+	_, _, err = a.RefreshSession("not-a-token")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+
+	// Expired tokens are rejected
+	tokens[hashOf(t, tokens, "token2")].ExpiresAt = time.Now().Add(-time.Second)
+	_, _, err = a.RefreshSession(second)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+// hashOf returns the hash of the stored refresh token with the ID.
+func hashOf(t *testing.T, tokens map[string]*models.RefreshToken, id string) string {
+	for hash, token := range tokens {
+		if token.ID == id {
+			return hash
+		}
+	}
+	t.Fatalf("refresh token %s not found", id)
+	return ""
+}
+
+func TestApp_RefreshSession_DisabledUser(t *testing.T) {
+	db, _, a := Setup(t)
+
+	storeRefreshTokens(db)
+	db.EXPECT().PruneTokens(mock.Anything).Return(0, assert.AnError)
+	db.EXPECT().GetUserByID("user1").Return(&models.User{ID: "user1", Username: "carol", Disabled: true}, nil)
+
+	// Pruning errors do not fail the login
+	token, err := a.IssueRefreshToken("user1")
+	assert.NoError(t, err)
+
+	_, _, err = a.RefreshSession(token)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_Logout(t *testing.T) {
+	db, _, a := Setup(t)
+
+	storeRefreshTokens(db)
+	db.EXPECT().PruneTokens(mock.Anything).Return(0, nil)
+	refreshToken, err := a.IssueRefreshToken("user1")
+	assert.NoError(t, err)
+
+	expiresAt := time.Now().Add(15 * time.Minute)
+	db.EXPECT().DeleteRefreshTokenFamily(mock.Anything).Return(nil).Once()
+	db.EXPECT().RevokeToken(&models.RevokedToken{ID: "jti1", ExpiresAt: expiresAt.UTC()}).Return(nil).Once()
+	err = a.Logout("user1", "jti1", expiresAt, refreshToken)
+	assert.NoError(t, err)
+
+	// Refresh tokens of other users are not revoked
+	err = a.Logout("user2", "jti2", expiresAt, refreshToken)
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	// Sessions without a token ID only revoke the refresh token
+	err = a.Logout("user1", "", time.Time{}, "")
+	assert.NoError(t, err)
+
+	err = a.Logout("", "jti1", expiresAt, "")
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+// storeSigningKeys keeps the signing keys created through the DB mock, oldest first.
+func storeSigningKeys(db *mocks.DB) *[]*models.SigningKey {
+	keys := &[]*models.SigningKey{}
+	db.EXPECT().GetSigningKeys().RunAndReturn(func() ([]*models.SigningKey, error) {
+		return slices.Clone(*keys), nil
+	}).Maybe()
+	db.EXPECT().CreateSigningKey(mock.Anything).RunAndReturn(func(key *models.SigningKey) error {
+		key.ID = fmt.Sprintf("key%d", len(*keys)+1)
+		*keys = append(*keys, key)
+		return nil
+	}).Maybe()
+	db.EXPECT().DeleteSigningKey(mock.Anything).RunAndReturn(func(id string) error {
+		*keys = slices.DeleteFunc(*keys, func(key *models.SigningKey) bool { return key.ID == id })
+		return nil
+	}).Maybe()
+	return keys
+}
+
+func TestApp_RotateSigningKeys(t *testing.T) {
+	db, tg, _ := Setup(t)
+	keys := storeSigningKeys(db)
+	logger, _ := zap.NewProduction()
+	policy := app.SigningKeyPolicy{Algorithm: "ES256", Rotation: time.Hour, TokenTTL: 15 * time.Minute, Secret: "secret"}
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithSigningKeys(policy))
+	defer a.Shutdown()
+	db.EXPECT().Close().Return(nil)
+	tg.EXPECT().Close()
+
+	// The first key is created right away
+	require.NoError(t, a.RotateSigningKeys())
+	require.Len(t, *keys, 1)
+	key, private, err := a.CurrentSigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key1", key.ID)
+	assert.Equal(t, "ES256", key.Algorithm)
+	assert.IsType(t, &ecdsa.PrivateKey{}, private)
+	assert.NotContains(t, string((*keys)[0].PrivateKey), "PRIVATE KEY")
+
+	// The key is kept until it is due
+	require.NoError(t, a.RotateSigningKeys())
+	assert.Len(t, *keys, 1)
+
+	// A new key only signs tokens once the other instances loaded it
+	(*keys)[0].CreatedAt = time.Now().Add(-2 * time.Hour)
+	require.NoError(t, a.RotateSigningKeys())
+	require.Len(t, *keys, 2)
+	key, _, err = a.CurrentSigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key1", key.ID)
+
+	(*keys)[1].CreatedAt = time.Now().Add(-5 * time.Minute)
+	require.NoError(t, a.RotateSigningKeys())
+	key, _, err = a.CurrentSigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key2", key.ID)
+
+	// Replaced keys verify tokens until they expired
+	assert.Len(t, a.SigningKeys(), 2)
+	_, err = a.SigningKey("key1")
+	assert.NoError(t, err)
+
+	(*keys)[1].CreatedAt = time.Now().Add(-30 * time.Minute)
+	require.NoError(t, a.RotateSigningKeys())
+	assert.Len(t, *keys, 1)
+	assert.Equal(t, []*models.SigningKey{(*keys)[0]}, a.SigningKeys())
+
+	// Unknown keys are not found until the rotation loads the keys of other instances
+	_, err = a.SigningKey("key9")
+	assert.ErrorIs(t, err, app.ErrNotFound)
+
+	*keys = append(*keys, &models.SigningKey{ID: "key9", Algorithm: "ES256", CreatedAt: time.Now()})
+	require.NoError(t, a.RotateSigningKeys())
+	key, err = a.SigningKey("key9")
+	assert.NoError(t, err)
+	assert.Equal(t, "key9", key.ID)
+}
+
+func TestApp_RotateSigningKeys_SecretChanged(t *testing.T) {
+	db, tg, _ := Setup(t)
+	keys := storeSigningKeys(db)
+	logger, _ := zap.NewProduction()
+	policy := app.SigningKeyPolicy{Algorithm: "RS256", Rotation: time.Hour, TokenTTL: 15 * time.Minute, Secret: "secret"}
+
+	require.NoError(t, app.NewApp(db, tg, logger.Sugar(), app.WithSigningKeys(policy)).RotateSigningKeys())
+
+	// Keys that cannot be decrypted only verify tokens, a new key signs them
+	policy.Secret = "other-secret"
+	a := app.NewApp(db, tg, logger.Sugar(), app.WithSigningKeys(policy))
+	require.NoError(t, a.RotateSigningKeys())
+	require.Len(t, *keys, 2)
+	key, _, err := a.CurrentSigningKey()
+	require.NoError(t, err)
+	assert.Equal(t, "key2", key.ID)
+	assert.Len(t, a.SigningKeys(), 2)
+}
+
+func TestApp_RotateSigningKeys_Disabled(t *testing.T) {
+	_, _, a := Setup(t)
+
+	assert.NoError(t, a.RotateSigningKeys())
+	_, _, err := a.CurrentSigningKey()
+	assert.Error(t, err)
+	assert.Empty(t, a.SigningKeys())
+}
+
+func TestApp_ProvisionExternalUser(t *testing.T) {
+	db, _, a := Setup(t)
+	issuer := "https://sso.example.com"
+
+	db.EXPECT().GetUserByExternalID(issuer, "00u1").Return(nil, app.ErrNotFound).Once()
+	db.EXPECT().GetUserByUsername("alice").Return(&models.User{ID: "user1", Username: "alice"}, nil)
+	db.EXPECT().GetUserByUsername("alice-2").Return(nil, app.ErrNotFound)
+	db.EXPECT().CreateUser(mock.Anything).Return(nil).Once()
+
+	// The first login creates the user, taken usernames are numbered
+	user, err := a.ProvisionExternalUser(issuer, "00u1", "alice@example.com", models.RoleAnalyst)
+	require.NoError(t, err)
+	assert.Equal(t, "alice-2", user.Username)
+	assert.Equal(t, models.RoleAnalyst, user.Role)
+	assert.Equal(t, issuer, user.Issuer.String)
+	assert.Equal(t, "00u1", user.Subject.String)
+	assert.Empty(t, user.Password)
+
+	// Later logins sync the role with the groups
+	user.ID = "user2"
+	db.EXPECT().GetUserByExternalID(issuer, "00u1").Return(user, nil)
+	db.EXPECT().SetUserRole("user2", models.RoleAdmin).Return(nil).Once()
+	user, err = a.ProvisionExternalUser(issuer, "00u1", "alice@example.com", models.RoleAdmin)
+	require.NoError(t, err)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+
+	_, err = a.ProvisionExternalUser(issuer, "", "alice", models.RoleReader)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+	_, err = a.ProvisionExternalUser(issuer, "00u1", "alice", "owner")
+	assert.ErrorIs(t, err, app.ErrBadRequest)
+
+	// Disabled users cannot log in
+	user.Disabled = true
+	_, err = a.ProvisionExternalUser(issuer, "00u1", "alice", models.RoleAdmin)
+	assert.ErrorIs(t, err, app.ErrUnauthorized)
+}
+
+func TestApp_ProvisionExternalUser_ConcurrentLogin(t *testing.T) {
+	db, _, a := Setup(t)
+	issuer := "https://sso.example.com"
+	existing := &models.User{ID: "user1", Username: "bob", Role: models.RoleReader}
+
+	// Another instance created the user first
+	db.EXPECT().GetUserByExternalID(issuer, "00u2").Return(nil, app.ErrNotFound).Once()
+	db.EXPECT().GetUserByUsername("bob").Return(nil, app.ErrNotFound)
+	db.EXPECT().CreateUser(mock.Anything).Return(errors.New("duplicate key")).Once()
+	db.EXPECT().GetUserByExternalID(issuer, "00u2").Return(existing, nil).Once()
+
+	user, err := a.ProvisionExternalUser(issuer, "00u2", "bob", models.RoleReader)
+	require.NoError(t, err)
+	assert.Equal(t, "user1", user.ID)
+}
+
+func TestApp_HasAdmin(t *testing.T) {
+	db, _, a := Setup(t)
+
+	db.EXPECT().GetUsers().Return([]*models.User{
+		{ID: "user1", Role: models.RoleAnalyst},
+		{ID: "user2", Role: models.RoleAdmin, Disabled: true},
+	}, nil).Once()
+	hasAdmin, err := a.HasAdmin()
+	assert.NoError(t, err)
+	assert.False(t, hasAdmin)
+
+	db.EXPECT().GetUsers().Return([]*models.User{{ID: "user1", Role: models.RoleAdmin}}, nil).Once()
+	hasAdmin, err = a.HasAdmin()
+	assert.NoError(t, err)
+	assert.True(t, hasAdmin)
+}
+
+func TestApp_DisableSeedUsers(t *testing.T) {
+	db, _, a := Setup(t)
+
+	seeded, err := bcrypt.GenerateFromPassword([]byte("alice"), bcrypt.MinCost)
+	require.NoError(t, err)
+	changed, err := bcrypt.GenerateFromPassword([]byte("a better password"), bcrypt.MinCost)
+	require.NoError(t, err)
+
+	db.EXPECT().GetUserByUsername("alice").Return(&models.User{ID: "user1", Username: "alice", Password: string(seeded), Role: models.RoleAdmin}, nil)
+	db.EXPECT().GetUserByUsername("bob").Return(&models.User{ID: "user2", Username: "bob", Password: string(changed)}, nil)
+	db.EXPECT().GetUserByUsername("carol").Return(&models.User{ID: "user3", Username: "carol", Password: string(seeded), Disabled: true}, nil)
+	db.EXPECT().GetUserByUsername("dave").Return(nil, app.ErrNotFound)
+	db.EXPECT().SetUserDisabled("user1", true).Return(nil)
+
+	// Only the users still logging in with their name are disabled
+	disabled, err := a.DisableSeedUsers()
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"alice"}, disabled)
+
+	db.EXPECT().SetUserDisabled("user1", false).Return(nil)
+	assert.NoError(t, a.EnableUser("alice"))
+}
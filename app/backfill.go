@@ -0,0 +1,343 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"eth-fetcher/database/models"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+)
+
+const (
+	defaultBackfillConcurrency = 4
+
+	// backfillBlockAttempts is how many times a block is fetched before the job fails.
+	backfillBlockAttempts = 3
+	backfillRetryDelay    = 250 * time.Millisecond
+
+	// backfillCheckpointInterval is how often the progress of a running job is persisted.
+	backfillCheckpointInterval = 5 * time.Second
+)
+
+var (
+	errBackfillCancelled = errors.New("backfill cancelled")
+	errShuttingDown      = errors.New("shutting down")
+)
+
+type backfillResult struct {
+	blockNumber  int64
+	transactions int
+	err          error
+}
+
+// StartBackfill creates a job that fetches all blocks in the inclusive range from the
+// eth node in the background and stores their transactions.
+func (a *App) StartBackfill(userID string, fromBlock, toBlock int64) (*models.BackfillJob, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+	if fromBlock < 0 || toBlock < fromBlock {
+		return nil, fmt.Errorf("%w: invalid block range %d-%d", ErrBadRequest, fromBlock, toBlock)
+	}
+
+	job := &models.BackfillJob{
+		UserID:     userID,
+		FromBlock:  fromBlock,
+		ToBlock:    toBlock,
+		Checkpoint: fromBlock,
+		Status:     models.BackfillStatusRunning,
+	}
+	err := a.db.SaveBackfillJob(job)
+	if err != nil {
+		return nil, err
+	}
+
+	started := *job
+	a.runBackfill(job)
+
+	return &started, nil
+}
+
+// ResumeBackfill restarts a cancelled or failed job from its last checkpoint.
+func (a *App) ResumeBackfill(userID, id string) (*models.BackfillJob, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+
+	// Claim the job before reading it, so concurrent resumes cannot both run it and
+	// a cancelled runner that is still saving its checkpoint is not replaced.
+	ctx, cancel, ok := a.claimBackfill(id)
+	if !ok {
+		return nil, fmt.Errorf("%w: backfill job %s is running", ErrBadRequest, id)
+	}
+
+	job, err := a.db.GetBackfillJob(id)
+	if err != nil {
+		a.releaseBackfill(id, cancel)
+		return nil, err
+	}
+	if job.Status != models.BackfillStatusCancelled && job.Status != models.BackfillStatusFailed {
+		a.releaseBackfill(id, cancel)
+		return nil, fmt.Errorf("%w: backfill job is %s", ErrBadRequest, job.Status)
+	}
+
+	job.Status = models.BackfillStatusRunning
+	job.Error = ""
+	err = a.db.SaveBackfillJob(job)
+	if err != nil {
+		a.releaseBackfill(id, cancel)
+		return nil, err
+	}
+
+	resumed := *job
+	a.startBackfill(ctx, cancel, job)
+
+	return &resumed, nil
+}
+
+// ResumeRunningBackfills restarts the jobs that were still running when the
+// application was last stopped.
+func (a *App) ResumeRunningBackfills() error {
+	jobs, err := a.db.GetBackfillJobs(models.BackfillStatusRunning)
+	if err != nil {
+		return err
+	}
+
+	for _, job := range jobs {
+		a.Log.Infof("resuming backfill job %s from block %d", job.ID, job.Checkpoint)
+		a.runBackfill(job)
+	}
+
+	return nil
+}
+
+// CancelBackfill stops a running job. Its checkpoint is kept so it can be resumed.
+func (a *App) CancelBackfill(userID, id string) error {
+	if !a.isAdmin(userID) {
+		return ErrForbidden
+	}
+
+	a.backfillsMu.Lock()
+	cancel, ok := a.backfills[id]
+	a.backfillsMu.Unlock()
+	if !ok {
+		return fmt.Errorf("%w: backfill job %s is not running", ErrBadRequest, id)
+	}
+
+	cancel(errBackfillCancelled)
+
+	return nil
+}
+
+// GetBackfillJob retrieves a job with its progress. Admins see all jobs, other users
+// only the address backfills they started.
+func (a *App) GetBackfillJob(userID, id string) (*models.BackfillJob, error) {
+	if a.isAdmin(userID) {
+		return a.db.GetBackfillJob(id)
+	}
+	if !a.hasRole(userID, models.RoleAnalyst) {
+		return nil, ErrForbidden
+	}
+
+	job, err := a.db.GetBackfillJob(id)
+	if err != nil {
+		return nil, err
+	}
+	if job.UserID != userID || job.Address == "" {
+		return nil, fmt.Errorf("backfill job %s %w", id, ErrNotFound)
+	}
+
+	return job, nil
+}
+
+// GetBackfillJobs retrieves all jobs, newest first.
+func (a *App) GetBackfillJobs(userID string) ([]*models.BackfillJob, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+	return a.db.GetBackfillJobs("")
+}
+
+// runBackfill runs a job that has no runner yet, e.g. a new one.
+func (a *App) runBackfill(job *models.BackfillJob) {
+	ctx, cancel, ok := a.claimBackfill(job.ID)
+	if !ok {
+		a.Log.Errorf("backfill job %s is already running", job.ID)
+		return
+	}
+	a.startBackfill(ctx, cancel, job)
+}
+
+// claimBackfill registers a runner for the job, false if the job has one. The runner
+// must be started with startBackfill or released with releaseBackfill.
+func (a *App) claimBackfill(id string) (context.Context, context.CancelCauseFunc, bool) {
+	a.backfillsMu.Lock()
+	defer a.backfillsMu.Unlock()
+
+	if _, ok := a.backfills[id]; ok {
+		return nil, nil, false
+	}
+	ctx, cancel := context.WithCancelCause(context.Background())
+	a.backfills[id] = cancel
+	a.backfillsWG.Add(1)
+
+	return ctx, cancel, true
+}
+
+// releaseBackfill unregisters the runner of the job.
+func (a *App) releaseBackfill(id string, cancel context.CancelCauseFunc) {
+	a.backfillsMu.Lock()
+	delete(a.backfills, id)
+	a.backfillsMu.Unlock()
+	cancel(nil)
+	a.backfillsWG.Done()
+}
+
+// startBackfill runs the job with the runner claimed by claimBackfill.
+func (a *App) startBackfill(ctx context.Context, cancel context.CancelCauseFunc, job *models.BackfillJob) {
+	go func() {
+		defer a.releaseBackfill(job.ID, cancel)
+
+		a.backfill(ctx, cancel, job)
+	}()
+}
+
+// backfill fetches the remaining blocks of the job with a bounded number of workers.
+// Blocks can complete out of order, the checkpoint only advances over the blocks
+// that completed without a gap.
+func (a *App) backfill(ctx context.Context, cancel context.CancelCauseFunc, job *models.BackfillJob) {
+	blocks := make(chan int64)
+	results := make(chan backfillResult)
+
+	go func() {
+		defer close(blocks)
+		for blockNumber := job.Checkpoint; blockNumber <= job.ToBlock; blockNumber++ {
+			select {
+			case <-ctx.Done():
+				return
+			case blocks <- blockNumber:
+			}
+		}
+	}()
+
+	var wg sync.WaitGroup
+	wg.Add(a.backfillConcurrency)
+	for i := 0; i < a.backfillConcurrency; i++ {
+		go func() {
+			defer wg.Done()
+			for blockNumber := range blocks {
+				count, err := a.backfillBlock(ctx, job.Address, blockNumber)
+				results <- backfillResult{blockNumber: blockNumber, transactions: count, err: err}
+			}
+		}()
+	}
+
+	go func() {
+		wg.Wait()
+		close(results)
+	}()
+
+	done := make(map[int64]int)
+	lastSave := time.Now()
+	for result := range results {
+		if result.err != nil {
+			if ctx.Err() == nil {
+				cancel(fmt.Errorf("block %d: %w", result.blockNumber, result.err))
+			}
+			continue
+		}
+
+		done[result.blockNumber] = result.transactions
+		for {
+			count, ok := done[job.Checkpoint]
+			if !ok {
+				break
+			}
+			delete(done, job.Checkpoint)
+			job.Checkpoint++
+			job.Transactions += int64(count)
+		}
+
+		if time.Since(lastSave) >= backfillCheckpointInterval {
+			a.saveBackfillJob(job)
+			lastSave = time.Now()
+		}
+	}
+
+	cause := context.Cause(ctx)
+	switch {
+	case job.Checkpoint > job.ToBlock:
+		job.Status = models.BackfillStatusCompleted
+	case errors.Is(cause, errShuttingDown):
+		// keep the job running so it is resumed on the next start
+	case errors.Is(cause, errBackfillCancelled):
+		job.Status = models.BackfillStatusCancelled
+	default:
+		job.Status = models.BackfillStatusFailed
+		job.Error = cause.Error()
+	}
+
+	a.saveBackfillJob(job)
+	a.Log.Infof("backfill job %s %s at block %d", job.ID, job.Status, job.Checkpoint)
+}
+
+// backfillBlock fetches a block and stores its transactions, or only the ones of the
+// address if it is not empty. The ones already in the database are overwritten so
+// they reflect the block currently on the node.
+func (a *App) backfillBlock(ctx context.Context, address string, blockNumber int64) (int, error) {
+	var (
+		transactions []*models.Transaction
+		err          error
+	)
+	for attempt := 0; attempt < backfillBlockAttempts; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-ctx.Done():
+				return 0, ctx.Err()
+			case <-time.After(backfillRetryDelay * time.Duration(attempt)):
+			}
+		}
+
+		release := a.limiter.acquireGlobal()
+		transactions, err = a.tg.GetBlockTransactions(blockNumber)
+		release()
+		if err == nil {
+			break
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
+	if address != "" {
+		transactions = slices.DeleteFunc(transactions, func(tx *models.Transaction) bool {
+			return !involvesAddress(tx, address)
+		})
+	}
+
+	err = a.db.SaveTransactions(transactions)
+	if err != nil {
+		return 0, fmt.Errorf("error saving transactions: %w", err)
+	}
+
+	return len(transactions), nil
+}
+
+func (a *App) saveBackfillJob(job *models.BackfillJob) {
+	err := a.db.SaveBackfillJob(job)
+	if err != nil {
+		a.Log.Errorf("error saving backfill job %s: %v", job.ID, err)
+	}
+}
+
+// stopBackfills interrupts the running jobs and waits for them to persist their checkpoints.
+func (a *App) stopBackfills() {
+	a.backfillsMu.Lock()
+	for _, cancel := range a.backfills {
+		cancel(errShuttingDown)
+	}
+	a.backfillsMu.Unlock()
+
+	a.backfillsWG.Wait()
+}
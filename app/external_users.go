@@ -0,0 +1,110 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/segmentio/ksuid"
+	"gopkg.in/guregu/null.v4"
+)
+
+// maxUsernameAttempts is how many numbered usernames are tried for a new external
+// user before a random suffix is used.
+const maxUsernameAttempts = 100
+
+var usernameInvalidChars = regexp.MustCompile(`[^a-zA-Z0-9_.-]+`)
+
+// ProvisionExternalUser returns the local user of the subject of an external identity
+// provider, creating it on the first login with a username derived from name. The
+// role follows the groups of the user at every login. External users have no password
+// and cannot log in with one.
+func (a *App) ProvisionExternalUser(issuer, subject, name string, role models.Role) (*models.User, error) {
+	if issuer == "" || subject == "" {
+		return nil, fmt.Errorf("%w: the token has no issuer or subject", ErrUnauthorized)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: role must be one of %v", ErrBadRequest, models.Roles)
+	}
+
+	user, err := a.db.GetUserByExternalID(issuer, subject)
+	if err != nil {
+		user, err = a.createExternalUser(issuer, subject, name, role)
+		if err != nil {
+			return nil, err
+		}
+	}
+	if user.Disabled {
+		return nil, fmt.Errorf("%w: user %s is disabled", ErrUnauthorized, user.Username)
+	}
+
+	if user.Role != role {
+		err = a.db.SetUserRole(user.ID, role)
+		if err != nil {
+			return nil, err
+		}
+		a.Log.Infof("role of user %s changed from %s to %s by the identity provider", user.Username, user.Role, role)
+		user.Role = role
+	}
+
+	return user, nil
+}
+
+// createExternalUser creates the user with the first free username. If another
+// instance created the user concurrently, that user is returned.
+func (a *App) createExternalUser(issuer, subject, name string, role models.Role) (*models.User, error) {
+	base := externalUsername(name)
+	username := base
+	for i := 2; a.usernameTaken(username); i++ {
+		suffix := fmt.Sprintf("-%d", i)
+		if i > maxUsernameAttempts {
+			id := ksuid.New().String()
+			suffix = "-" + id[len(id)-8:]
+		}
+		username = truncate(base, 32-len(suffix)) + suffix
+	}
+
+	user := &models.User{
+		Username: username,
+		Role:     role,
+		Issuer:   null.StringFrom(issuer),
+		Subject:  null.StringFrom(subject),
+	}
+	err := a.db.CreateUser(user)
+	if err != nil {
+		existing, lookupErr := a.db.GetUserByExternalID(issuer, subject)
+		if lookupErr != nil {
+			return nil, fmt.Errorf("creating user %s: %w", username, err)
+		}
+		return existing, nil
+	}
+	a.Log.Infof("created user %s for %s of %s", username, subject, issuer)
+
+	return user, nil
+}
+
+func (a *App) usernameTaken(username string) bool {
+	_, err := a.db.GetUserByUsername(username)
+	return err == nil
+}
+
+// externalUsername turns a name from the claims of a token into a valid username.
+// The domain of email addresses is dropped.
+func externalUsername(name string) string {
+	name, _, _ = strings.Cut(name, "@")
+	name = strings.Trim(usernameInvalidChars.ReplaceAllString(name, "_"), "_")
+	name = truncate(name, 32)
+	if len(name) < 3 {
+		return "user"
+	}
+
+	return name
+}
+
+func truncate(s string, n int) string {
+	if len(s) > n {
+		return s[:n]
+	}
+	return s
+}
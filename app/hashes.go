@@ -0,0 +1,64 @@
+package app
+
+import (
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// InvalidHashesError is returned when some of the requested transaction hashes are
+// not 32 byte hex strings.
+type InvalidHashesError struct {
+	Hashes []string
+}
+
+func (e *InvalidHashesError) Error() string {
+	return fmt.Sprintf("invalid transaction hashes: %s", strings.Join(e.Hashes, ", "))
+}
+
+func (e *InvalidHashesError) Is(target error) bool {
+	return target == ErrBadRequest
+}
+
+// NormalizeHash returns the lowercase 0x prefixed form of a 32 byte hex hash.
+func NormalizeHash(hash string) (string, bool) {
+	h := hash
+	if len(h) >= 2 && h[0] == '0' && (h[1] == 'x' || h[1] == 'X') {
+		h = h[2:]
+	}
+	if len(h) != 64 {
+		return "", false
+	}
+	if _, err := hex.DecodeString(h); err != nil {
+		return "", false
+	}
+
+	return "0x" + strings.ToLower(h), true
+}
+
+// normalizeHashes normalizes the hashes and removes duplicates, keeping the order of
+// their first occurrence. All invalid hashes are reported in an InvalidHashesError.
+func normalizeHashes(hashes []string) ([]string, error) {
+	normalized := make([]string, 0, len(hashes))
+	seen := make(map[string]struct{}, len(hashes))
+	var invalid []string
+
+	for _, hash := range hashes {
+		h, ok := NormalizeHash(hash)
+		if !ok {
+			invalid = append(invalid, hash)
+			continue
+		}
+		if _, ok := seen[h]; ok {
+			continue
+		}
+		seen[h] = struct{}{}
+		normalized = append(normalized, h)
+	}
+
+	if len(invalid) > 0 {
+		return nil, &InvalidHashesError{Hashes: invalid}
+	}
+
+	return normalized, nil
+}
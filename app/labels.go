@@ -0,0 +1,130 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+// AddAddressLabel stores a label for an address. Private labels are owned by the
+// user, global labels are visible to everyone and can only be managed by admins.
+func (a *App) AddAddressLabel(userID string, label *models.AddressLabel, global bool) error {
+	if userID == "" {
+		return ErrUnauthorized
+	}
+	if global && !a.isAdmin(userID) {
+		return ErrForbidden
+	}
+
+	label.UserID = userID
+	if global {
+		label.UserID = ""
+	}
+
+	return a.saveAddressLabels([]*models.AddressLabel{label})
+}
+
+// ImportAddressLabels stores the labels as global labels.
+func (a *App) ImportAddressLabels(labels []*models.AddressLabel) error {
+	for _, label := range labels {
+		label.UserID = ""
+	}
+	return a.saveAddressLabels(labels)
+}
+
+func (a *App) saveAddressLabels(labels []*models.AddressLabel) error {
+	for _, label := range labels {
+		label.Name = strings.TrimSpace(label.Name)
+		if !common.IsHexAddress(label.Address) || label.Name == "" {
+			return ErrBadRequest
+		}
+		label.Address = normalizeAddress(label.Address)
+	}
+
+	return a.db.SaveAddressLabels(labels)
+}
+
+// GetAddressLabels retrieves the global labels and the private labels of the user.
+func (a *App) GetAddressLabels(userID string) ([]*models.AddressLabel, error) {
+	return a.db.GetAddressLabels(userID)
+}
+
+// DeleteAddressLabel removes a private label of the user or, for admins, a global label.
+func (a *App) DeleteAddressLabel(userID, address string, global bool) error {
+	if userID == "" {
+		return ErrUnauthorized
+	}
+	if global && !a.isAdmin(userID) {
+		return ErrForbidden
+	}
+	if !common.IsHexAddress(address) {
+		return ErrBadRequest
+	}
+
+	owner := userID
+	if global {
+		owner = ""
+	}
+
+	return a.db.DeleteAddressLabel(owner, normalizeAddress(address))
+}
+
+// LabelTransactions returns copies of the transactions annotated with the names of
+// their from, to and contract addresses. Private labels of the user take precedence
+// over global ones. Lookup errors are logged and the transactions are returned as is.
+func (a *App) LabelTransactions(userID string, transactions []*models.Transaction) []*models.Transaction {
+	addresses := make([]string, 0, len(transactions)*2)
+	for _, tx := range transactions {
+		addresses = append(addresses, normalizeAddress(tx.From))
+		if tx.To.Valid {
+			addresses = append(addresses, normalizeAddress(tx.To.String))
+		}
+		if tx.ContractAddress.Valid {
+			addresses = append(addresses, normalizeAddress(tx.ContractAddress.String))
+		}
+	}
+	if len(addresses) == 0 {
+		return transactions
+	}
+
+	labels, err := a.db.FindAddressLabels(userID, addresses)
+	if err != nil {
+		a.Log.Errorf("error getting address labels: %v", err)
+		return transactions
+	}
+	if len(labels) == 0 {
+		return transactions
+	}
+
+	names := make(map[string]string, len(labels))
+	for _, label := range labels {
+		if _, ok := names[label.Address]; ok && label.UserID == "" {
+			continue
+		}
+		names[label.Address] = label.Name
+	}
+
+	labelled := make([]*models.Transaction, len(transactions))
+	for i, tx := range transactions {
+		t := *tx
+		t.FromLabel = names[normalizeAddress(tx.From)]
+		if tx.To.Valid {
+			t.ToLabel = names[normalizeAddress(tx.To.String)]
+		}
+		if tx.ContractAddress.Valid {
+			t.ContractAddressLabel = names[normalizeAddress(tx.ContractAddress.String)]
+		}
+		labelled[i] = &t
+	}
+
+	return labelled
+}
+
+// normalizeAddress returns the lowercase 0x prefixed form used to store and look up addresses.
+func normalizeAddress(address string) string {
+	if common.IsHexAddress(address) {
+		return strings.ToLower(common.HexToAddress(address).Hex())
+	}
+	return strings.ToLower(address)
+}
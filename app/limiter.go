@@ -0,0 +1,125 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+)
+
+const (
+	defaultMaxNodeFetches        = 32
+	defaultMaxNodeFetchesPerUser = 8
+	defaultMaxPendingNodeFetches = 1000
+
+	// anonymousUser is the bucket shared by all unauthenticated requests.
+	anonymousUser = "anonymous"
+)
+
+// fetchLimiter bounds the eth node fetches across all requests. Requests reserve
+// their fetches up front and are rejected when too many are pending, the reserved
+// fetches then wait for a free global and a free per user slot.
+type fetchLimiter struct {
+	global     chan struct{}
+	perUser    int
+	maxPending int
+
+	mu      sync.Mutex
+	pending int
+	users   map[string]*userSlots
+}
+
+type userSlots struct {
+	slots chan struct{}
+	refs  int
+}
+
+func newFetchLimiter(global, perUser, maxPending int) *fetchLimiter {
+	return &fetchLimiter{
+		global:     make(chan struct{}, global),
+		perUser:    perUser,
+		maxPending: maxPending,
+		users:      make(map[string]*userSlots),
+	}
+}
+
+// setLimits changes the limits of the fetches acquired from now on, running fetches
+// release the slots of the previous limits.
+func (l *fetchLimiter) setLimits(global, perUser, maxPending int) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.global = make(chan struct{}, global)
+	l.perUser = perUser
+	l.maxPending = maxPending
+	l.users = make(map[string]*userSlots)
+}
+
+// reserve admits n fetches or returns ErrOverloaded if that would exceed the
+// pending limit. More fetches than the limit are never admitted, they are rejected
+// with ErrBadRequest. Every admitted fetch must be released with unreserve.
+func (l *fetchLimiter) reserve(n int) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if n > l.maxPending {
+		return fmt.Errorf("%w: %d transactions must be fetched from the eth node, at most %d can be fetched at once", ErrBadRequest, n, l.maxPending)
+	}
+	if l.pending+n > l.maxPending {
+		return ErrOverloaded
+	}
+	l.pending += n
+
+	return nil
+}
+
+func (l *fetchLimiter) unreserve(n int) {
+	l.mu.Lock()
+	l.pending -= n
+	l.mu.Unlock()
+}
+
+// acquire waits for a per user slot and a global slot. The returned function
+// releases both.
+func (l *fetchLimiter) acquire(userID string) func() {
+	if userID == "" {
+		userID = anonymousUser
+	}
+
+	l.mu.Lock()
+	u, ok := l.users[userID]
+	if !ok {
+		u = &userSlots{slots: make(chan struct{}, l.perUser)}
+		l.users[userID] = u
+	}
+	u.refs++
+	global := l.global
+	l.mu.Unlock()
+
+	u.slots <- struct{}{}
+	global <- struct{}{}
+
+	return func() {
+		<-global
+		<-u.slots
+
+		l.mu.Lock()
+		u.refs--
+		if u.refs == 0 && l.users[userID] == u {
+			delete(l.users, userID)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// acquireGlobal waits for a global slot only, it is used by background jobs that
+// bound their own concurrency.
+func (l *fetchLimiter) acquireGlobal() func() {
+	l.mu.Lock()
+	global := l.global
+	l.mu.Unlock()
+
+	global <- struct{}{}
+
+	return func() {
+		<-global
+	}
+}
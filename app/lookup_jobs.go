@@ -0,0 +1,239 @@
+package app
+
+import (
+	"errors"
+	"eth-fetcher/database/models"
+	"fmt"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	LookupJobRunning   = "running"
+	LookupJobCompleted = "completed"
+	LookupJobCancelled = "cancelled"
+	// LookupJobPartial is the status of a finished job that failed to look up some
+	// of its hashes, they are listed in FailedHashes.
+	LookupJobPartial = "partial"
+	// LookupJobFailed is the status of a finished job that failed to look up all of
+	// its hashes.
+	LookupJobFailed = "failed"
+
+	// MaxLookupJobHashes is the largest hash list a single lookup job accepts.
+	MaxLookupJobHashes = 100000
+	// MaxRunningLookupJobs is how many jobs a user can run at once, anonymous
+	// callers share the limit.
+	MaxRunningLookupJobs = 3
+
+	// lookupJobBatchSize is how many hashes are looked up at once, results become
+	// visible after every batch.
+	lookupJobBatchSize = 50
+
+	// lookupJobBatchAttempts is how many times a batch is looked up while the node
+	// fetch queue is full before its hashes are recorded as failed.
+	lookupJobBatchAttempts = 5
+	lookupJobRetryDelay    = 250 * time.Millisecond
+
+	// lookupJobTTL is how long finished jobs are kept in memory.
+	lookupJobTTL = time.Hour
+)
+
+// LookupJob looks up a large list of transaction hashes in the background.
+type LookupJob struct {
+	ID           string
+	UserID       string
+	Status       string
+	Total        int
+	Processed    int
+	Transactions []*models.Transaction
+	// FailedHashes are the hashes that could not be looked up.
+	FailedHashes []string
+	CreatedAt    time.Time
+	FinishedAt   time.Time
+}
+
+// StartLookupJob starts looking up the hashes in the background and returns the job
+// without waiting for the results.
+func (a *App) StartLookupJob(userID string, transactionHashes []string) (*LookupJob, error) {
+	if len(transactionHashes) == 0 {
+		return nil, ErrBadRequest
+	}
+	if len(transactionHashes) > MaxLookupJobHashes {
+		return nil, fmt.Errorf("%w: more than %d hashes", ErrBadRequest, MaxLookupJobHashes)
+	}
+
+	transactionHashes, err := normalizeHashes(transactionHashes)
+	if err != nil {
+		return nil, err
+	}
+
+	job := &LookupJob{
+		ID:        ksuid.New().String(),
+		UserID:    userID,
+		Status:    LookupJobRunning,
+		Total:     len(transactionHashes),
+		CreatedAt: time.Now(),
+	}
+
+	a.lookupJobsMu.Lock()
+	a.pruneLookupJobs()
+	if a.runningLookupJobs(userID) >= MaxRunningLookupJobs {
+		a.lookupJobsMu.Unlock()
+		return nil, fmt.Errorf("%w: %d lookup jobs are running, wait for one to finish", ErrTooManyRequests, MaxRunningLookupJobs)
+	}
+	a.lookupJobs[job.ID] = job
+	snapshot := job.snapshot()
+	a.lookupJobsMu.Unlock()
+
+	a.lookupJobsWG.Add(1)
+	go func() {
+		defer a.lookupJobsWG.Done()
+		a.runLookupJob(job, transactionHashes)
+	}()
+
+	return snapshot, nil
+}
+
+// GetLookupJob returns the current state of a job including the transactions found
+// so far. Jobs started by a user are only visible to that user.
+func (a *App) GetLookupJob(userID, id string) (*LookupJob, error) {
+	a.lookupJobsMu.Lock()
+	defer a.lookupJobsMu.Unlock()
+
+	job, ok := a.lookupJobs[id]
+	if !ok || (job.UserID != "" && job.UserID != userID) {
+		return nil, fmt.Errorf("lookup job %s %w", id, ErrNotFound)
+	}
+
+	return job.snapshot(), nil
+}
+
+func (a *App) runLookupJob(job *LookupJob, transactionHashes []string) {
+	var (
+		found  []*models.Transaction
+		failed int
+	)
+
+	for start := 0; start < len(transactionHashes); start += lookupJobBatchSize {
+		end := min(start+lookupJobBatchSize, len(transactionHashes))
+		transactions, err := a.lookupBatch(job, transactionHashes[start:end])
+		if errors.Is(err, errShuttingDown) {
+			a.finishLookupJob(job, LookupJobCancelled)
+			return
+		}
+		var missing []string
+		if err != nil {
+			a.Log.Errorf("error looking up transactions for job %s: %v", job.ID, err)
+			missing = transactionHashes[start:end]
+		} else {
+			// Hashes the node failed to fetch or does not know are missing
+			missing = missingHashes(transactionHashes[start:end], transactions)
+		}
+		failed += len(missing)
+		found = append(found, transactions...)
+
+		a.lookupJobsMu.Lock()
+		job.Processed = end
+		job.Transactions = append(job.Transactions, transactions...)
+		job.FailedHashes = append(job.FailedHashes, missing...)
+		a.lookupJobsMu.Unlock()
+	}
+
+	if job.UserID != "" && len(found) > 0 {
+		err := a.AddUserTransactions(job.UserID, found)
+		if err != nil {
+			a.Log.Errorf("error adding user transactions for job %s: %v", job.ID, err)
+		}
+	}
+
+	switch {
+	case failed == 0:
+		a.finishLookupJob(job, LookupJobCompleted)
+	case failed < len(transactionHashes):
+		a.finishLookupJob(job, LookupJobPartial)
+	default:
+		a.finishLookupJob(job, LookupJobFailed)
+	}
+}
+
+// lookupBatch looks up a batch of hashes, retrying with a growing delay while the
+// node fetch queue is full. errShuttingDown is returned if the jobs are stopped
+// meanwhile.
+func (a *App) lookupBatch(job *LookupJob, transactionHashes []string) ([]*models.Transaction, error) {
+	var err error
+	for attempt := 0; attempt < lookupJobBatchAttempts; attempt++ {
+		select {
+		case <-a.lookupJobsDone:
+			return nil, errShuttingDown
+		case <-time.After(lookupJobRetryDelay * time.Duration(attempt)):
+		}
+
+		var transactions []*models.Transaction
+		transactions, err = a.GetTransactionsByHashes(job.UserID, transactionHashes)
+		if !errors.Is(err, ErrOverloaded) {
+			return transactions, err
+		}
+	}
+
+	return nil, err
+}
+
+func (a *App) finishLookupJob(job *LookupJob, status string) {
+	a.lookupJobsMu.Lock()
+	job.Status = status
+	job.FinishedAt = time.Now()
+	a.lookupJobsMu.Unlock()
+}
+
+// missingHashes returns the hashes without a transaction.
+func missingHashes(transactionHashes []string, transactions []*models.Transaction) []string {
+	found := make(map[string]bool, len(transactions))
+	for _, tx := range transactions {
+		found[tx.TxHash] = true
+	}
+
+	var missing []string
+	for _, hash := range transactionHashes {
+		if !found[hash] {
+			missing = append(missing, hash)
+		}
+	}
+
+	return missing
+}
+
+// runningLookupJobs counts the running jobs of the user. The caller must hold
+// lookupJobsMu.
+func (a *App) runningLookupJobs(userID string) int {
+	n := 0
+	for _, job := range a.lookupJobs {
+		if job.UserID == userID && job.Status == LookupJobRunning {
+			n++
+		}
+	}
+	return n
+}
+
+// pruneLookupJobs removes the jobs that finished more than lookupJobTTL ago.
+// The caller must hold lookupJobsMu.
+func (a *App) pruneLookupJobs() {
+	for id, job := range a.lookupJobs {
+		if job.Status != LookupJobRunning && time.Since(job.FinishedAt) > lookupJobTTL {
+			delete(a.lookupJobs, id)
+		}
+	}
+}
+
+// stopLookupJobs stops the running jobs after their current batch.
+func (a *App) stopLookupJobs() {
+	close(a.lookupJobsDone)
+	a.lookupJobsWG.Wait()
+}
+
+func (job *LookupJob) snapshot() *LookupJob {
+	s := *job
+	s.Transactions = append([]*models.Transaction(nil), job.Transactions...)
+	s.FailedHashes = append([]string(nil), job.FailedHashes...)
+	return &s
+}
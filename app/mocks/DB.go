@@ -6,6 +6,8 @@ import (
 	models "eth-fetcher/database/models"
 
 	mock "github.com/stretchr/testify/mock"
+
+	time "time"
 )
 
 // DB is an autogenerated mock type for the DB type
@@ -113,248 +115,2228 @@ func (_c *DB_Close_Call) RunAndReturn(run func() error) *DB_Close_Call {
 	return _c
 }
 
-// GetAllTransactions provides a mock function with given fields:
-func (_m *DB) GetAllTransactions() ([]*models.Transaction, error) {
-	ret := _m.Called()
+// CreateAPIKey provides a mock function with given fields: key
+func (_m *DB) CreateAPIKey(key *models.APIKey) error {
+	ret := _m.Called(key)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAllTransactions")
+		panic("no return value specified for CreateAPIKey")
 	}
 
-	var r0 []*models.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*models.Transaction, error)); ok {
-		return rf()
-	}
-	if rf, ok := ret.Get(0).(func() []*models.Transaction); ok {
-		r0 = rf()
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.APIKey) error); ok {
+		r0 = rf(key)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	return r0
+}
+
+// DB_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type DB_CreateAPIKey_Call struct {
+	*mock.Call
+}
+
+// CreateAPIKey is a helper method to define mock.On call
+//   - key *models.APIKey
+func (_e *DB_Expecter) CreateAPIKey(key interface{}) *DB_CreateAPIKey_Call {
+	return &DB_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", key)}
+}
+
+func (_c *DB_CreateAPIKey_Call) Run(run func(key *models.APIKey)) *DB_CreateAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.APIKey))
+	})
+	return _c
+}
+
+func (_c *DB_CreateAPIKey_Call) Return(_a0 error) *DB_CreateAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_CreateAPIKey_Call) RunAndReturn(run func(*models.APIKey) error) *DB_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateRefreshToken provides a mock function with given fields: token
+func (_m *DB) CreateRefreshToken(token *models.RefreshToken) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateRefreshToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.RefreshToken) error); ok {
+		r0 = rf(token)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// DB_GetAllTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTransactions'
-type DB_GetAllTransactions_Call struct {
+// DB_CreateRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateRefreshToken'
+type DB_CreateRefreshToken_Call struct {
 	*mock.Call
 }
 
-// GetAllTransactions is a helper method to define mock.On call
-func (_e *DB_Expecter) GetAllTransactions() *DB_GetAllTransactions_Call {
-	return &DB_GetAllTransactions_Call{Call: _e.mock.On("GetAllTransactions")}
+// CreateRefreshToken is a helper method to define mock.On call
+//   - token *models.RefreshToken
+func (_e *DB_Expecter) CreateRefreshToken(token interface{}) *DB_CreateRefreshToken_Call {
+	return &DB_CreateRefreshToken_Call{Call: _e.mock.On("CreateRefreshToken", token)}
 }
 
-func (_c *DB_GetAllTransactions_Call) Run(run func()) *DB_GetAllTransactions_Call {
+func (_c *DB_CreateRefreshToken_Call) Run(run func(token *models.RefreshToken)) *DB_CreateRefreshToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(*models.RefreshToken))
 	})
 	return _c
 }
 
-func (_c *DB_GetAllTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetAllTransactions_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *DB_CreateRefreshToken_Call) Return(_a0 error) *DB_CreateRefreshToken_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *DB_GetAllTransactions_Call) RunAndReturn(run func() ([]*models.Transaction, error)) *DB_GetAllTransactions_Call {
+func (_c *DB_CreateRefreshToken_Call) RunAndReturn(run func(*models.RefreshToken) error) *DB_CreateRefreshToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetTransactionsByHashes provides a mock function with given fields: hashes
-func (_m *DB) GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error) {
-	ret := _m.Called(hashes)
+// CreateSigningKey provides a mock function with given fields: key
+func (_m *DB) CreateSigningKey(key *models.SigningKey) error {
+	ret := _m.Called(key)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetTransactionsByHashes")
+		panic("no return value specified for CreateSigningKey")
 	}
 
-	var r0 []*models.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func([]string) ([]*models.Transaction, error)); ok {
-		return rf(hashes)
-	}
-	if rf, ok := ret.Get(0).(func([]string) []*models.Transaction); ok {
-		r0 = rf(hashes)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.SigningKey) error); ok {
+		r0 = rf(key)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func([]string) error); ok {
-		r1 = rf(hashes)
+	return r0
+}
+
+// DB_CreateSigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateSigningKey'
+type DB_CreateSigningKey_Call struct {
+	*mock.Call
+}
+
+// CreateSigningKey is a helper method to define mock.On call
+//   - key *models.SigningKey
+func (_e *DB_Expecter) CreateSigningKey(key interface{}) *DB_CreateSigningKey_Call {
+	return &DB_CreateSigningKey_Call{Call: _e.mock.On("CreateSigningKey", key)}
+}
+
+func (_c *DB_CreateSigningKey_Call) Run(run func(key *models.SigningKey)) *DB_CreateSigningKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.SigningKey))
+	})
+	return _c
+}
+
+func (_c *DB_CreateSigningKey_Call) Return(_a0 error) *DB_CreateSigningKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_CreateSigningKey_Call) RunAndReturn(run func(*models.SigningKey) error) *DB_CreateSigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// CreateUser provides a mock function with given fields: user
+func (_m *DB) CreateUser(user *models.User) error {
+	ret := _m.Called(user)
+
+	if len(ret) == 0 {
+		panic("no return value specified for CreateUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.User) error); ok {
+		r0 = rf(user)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// DB_GetTransactionsByHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsByHashes'
-type DB_GetTransactionsByHashes_Call struct {
+// DB_CreateUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateUser'
+type DB_CreateUser_Call struct {
 	*mock.Call
 }
 
-// GetTransactionsByHashes is a helper method to define mock.On call
-//   - hashes []string
-func (_e *DB_Expecter) GetTransactionsByHashes(hashes interface{}) *DB_GetTransactionsByHashes_Call {
-	return &DB_GetTransactionsByHashes_Call{Call: _e.mock.On("GetTransactionsByHashes", hashes)}
+// CreateUser is a helper method to define mock.On call
+//   - user *models.User
+func (_e *DB_Expecter) CreateUser(user interface{}) *DB_CreateUser_Call {
+	return &DB_CreateUser_Call{Call: _e.mock.On("CreateUser", user)}
 }
 
-func (_c *DB_GetTransactionsByHashes_Call) Run(run func(hashes []string)) *DB_GetTransactionsByHashes_Call {
+func (_c *DB_CreateUser_Call) Run(run func(user *models.User)) *DB_CreateUser_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]string))
+		run(args[0].(*models.User))
 	})
 	return _c
 }
 
-func (_c *DB_GetTransactionsByHashes_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetTransactionsByHashes_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *DB_CreateUser_Call) Return(_a0 error) *DB_CreateUser_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *DB_GetTransactionsByHashes_Call) RunAndReturn(run func([]string) ([]*models.Transaction, error)) *DB_GetTransactionsByHashes_Call {
+func (_c *DB_CreateUser_Call) RunAndReturn(run func(*models.User) error) *DB_CreateUser_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserByUsername provides a mock function with given fields: username
-func (_m *DB) GetUserByUsername(username string) (*models.User, error) {
-	ret := _m.Called(username)
+// DeleteAPIKey provides a mock function with given fields: userID, id
+func (_m *DB) DeleteAPIKey(userID string, id string) error {
+	ret := _m.Called(userID, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserByUsername")
+		panic("no return value specified for DeleteAPIKey")
 	}
 
-	var r0 *models.User
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
-		return rf(username)
-	}
-	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
-		r0 = rf(username)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(username)
+	return r0
+}
+
+// DB_DeleteAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAPIKey'
+type DB_DeleteAPIKey_Call struct {
+	*mock.Call
+}
+
+// DeleteAPIKey is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *DB_Expecter) DeleteAPIKey(userID interface{}, id interface{}) *DB_DeleteAPIKey_Call {
+	return &DB_DeleteAPIKey_Call{Call: _e.mock.On("DeleteAPIKey", userID, id)}
+}
+
+func (_c *DB_DeleteAPIKey_Call) Run(run func(userID string, id string)) *DB_DeleteAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *DB_DeleteAPIKey_Call) Return(_a0 error) *DB_DeleteAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_DeleteAPIKey_Call) RunAndReturn(run func(string, string) error) *DB_DeleteAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAddressLabel provides a mock function with given fields: userID, address
+func (_m *DB) DeleteAddressLabel(userID string, address string) error {
+	ret := _m.Called(userID, address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAddressLabel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, address)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// DB_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
-type DB_GetUserByUsername_Call struct {
+// DB_DeleteAddressLabel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAddressLabel'
+type DB_DeleteAddressLabel_Call struct {
 	*mock.Call
 }
 
-// GetUserByUsername is a helper method to define mock.On call
-//   - username string
-func (_e *DB_Expecter) GetUserByUsername(username interface{}) *DB_GetUserByUsername_Call {
-	return &DB_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", username)}
+// DeleteAddressLabel is a helper method to define mock.On call
+//   - userID string
+//   - address string
+func (_e *DB_Expecter) DeleteAddressLabel(userID interface{}, address interface{}) *DB_DeleteAddressLabel_Call {
+	return &DB_DeleteAddressLabel_Call{Call: _e.mock.On("DeleteAddressLabel", userID, address)}
 }
 
-func (_c *DB_GetUserByUsername_Call) Run(run func(username string)) *DB_GetUserByUsername_Call {
+func (_c *DB_DeleteAddressLabel_Call) Run(run func(userID string, address string)) *DB_DeleteAddressLabel_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *DB_GetUserByUsername_Call) Return(_a0 *models.User, _a1 error) *DB_GetUserByUsername_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *DB_DeleteAddressLabel_Call) Return(_a0 error) *DB_DeleteAddressLabel_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *DB_GetUserByUsername_Call) RunAndReturn(run func(string) (*models.User, error)) *DB_GetUserByUsername_Call {
+func (_c *DB_DeleteAddressLabel_Call) RunAndReturn(run func(string, string) error) *DB_DeleteAddressLabel_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserTransactions provides a mock function with given fields: userID
-func (_m *DB) GetUserTransactions(userID string) ([]*models.Transaction, error) {
-	ret := _m.Called(userID)
+// DeleteRefreshTokenFamily provides a mock function with given fields: familyID
+func (_m *DB) DeleteRefreshTokenFamily(familyID string) error {
+	ret := _m.Called(familyID)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserTransactions")
+		panic("no return value specified for DeleteRefreshTokenFamily")
 	}
 
-	var r0 []*models.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Transaction, error)); ok {
-		return rf(userID)
-	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Transaction); ok {
-		r0 = rf(userID)
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(familyID)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userID)
+	return r0
+}
+
+// DB_DeleteRefreshTokenFamily_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteRefreshTokenFamily'
+type DB_DeleteRefreshTokenFamily_Call struct {
+	*mock.Call
+}
+
+// DeleteRefreshTokenFamily is a helper method to define mock.On call
+//   - familyID string
+func (_e *DB_Expecter) DeleteRefreshTokenFamily(familyID interface{}) *DB_DeleteRefreshTokenFamily_Call {
+	return &DB_DeleteRefreshTokenFamily_Call{Call: _e.mock.On("DeleteRefreshTokenFamily", familyID)}
+}
+
+func (_c *DB_DeleteRefreshTokenFamily_Call) Run(run func(familyID string)) *DB_DeleteRefreshTokenFamily_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_DeleteRefreshTokenFamily_Call) Return(_a0 error) *DB_DeleteRefreshTokenFamily_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_DeleteRefreshTokenFamily_Call) RunAndReturn(run func(string) error) *DB_DeleteRefreshTokenFamily_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteSigningKey provides a mock function with given fields: id
+func (_m *DB) DeleteSigningKey(id string) error {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteSigningKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(id)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// DB_GetUserTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserTransactions'
-type DB_GetUserTransactions_Call struct {
+// DB_DeleteSigningKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteSigningKey'
+type DB_DeleteSigningKey_Call struct {
 	*mock.Call
 }
 
-// GetUserTransactions is a helper method to define mock.On call
-//   - userID string
-func (_e *DB_Expecter) GetUserTransactions(userID interface{}) *DB_GetUserTransactions_Call {
-	return &DB_GetUserTransactions_Call{Call: _e.mock.On("GetUserTransactions", userID)}
+// DeleteSigningKey is a helper method to define mock.On call
+//   - id string
+func (_e *DB_Expecter) DeleteSigningKey(id interface{}) *DB_DeleteSigningKey_Call {
+	return &DB_DeleteSigningKey_Call{Call: _e.mock.On("DeleteSigningKey", id)}
 }
 
-func (_c *DB_GetUserTransactions_Call) Run(run func(userID string)) *DB_GetUserTransactions_Call {
+func (_c *DB_DeleteSigningKey_Call) Run(run func(id string)) *DB_DeleteSigningKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
 		run(args[0].(string))
 	})
 	return _c
 }
 
-func (_c *DB_GetUserTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetUserTransactions_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *DB_DeleteSigningKey_Call) Return(_a0 error) *DB_DeleteSigningKey_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *DB_GetUserTransactions_Call) RunAndReturn(run func(string) ([]*models.Transaction, error)) *DB_GetUserTransactions_Call {
+func (_c *DB_DeleteSigningKey_Call) RunAndReturn(run func(string) error) *DB_DeleteSigningKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUser provides a mock function with given fields: userID
+func (_m *DB) DeleteUser(userID string) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type DB_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) DeleteUser(userID interface{}) *DB_DeleteUser_Call {
+	return &DB_DeleteUser_Call{Call: _e.mock.On("DeleteUser", userID)}
+}
+
+func (_c *DB_DeleteUser_Call) Run(run func(userID string)) *DB_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_DeleteUser_Call) Return(_a0 error) *DB_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_DeleteUser_Call) RunAndReturn(run func(string) error) *DB_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUserRefreshTokens provides a mock function with given fields: userID
+func (_m *DB) DeleteUserRefreshTokens(userID string) error {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUserRefreshTokens")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string) error); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_DeleteUserRefreshTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUserRefreshTokens'
+type DB_DeleteUserRefreshTokens_Call struct {
+	*mock.Call
+}
+
+// DeleteUserRefreshTokens is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) DeleteUserRefreshTokens(userID interface{}) *DB_DeleteUserRefreshTokens_Call {
+	return &DB_DeleteUserRefreshTokens_Call{Call: _e.mock.On("DeleteUserRefreshTokens", userID)}
+}
+
+func (_c *DB_DeleteUserRefreshTokens_Call) Run(run func(userID string)) *DB_DeleteUserRefreshTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_DeleteUserRefreshTokens_Call) Return(_a0 error) *DB_DeleteUserRefreshTokens_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_DeleteUserRefreshTokens_Call) RunAndReturn(run func(string) error) *DB_DeleteUserRefreshTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// FindAddressLabels provides a mock function with given fields: userID, addresses
+func (_m *DB) FindAddressLabels(userID string, addresses []string) ([]*models.AddressLabel, error) {
+	ret := _m.Called(userID, addresses)
+
+	if len(ret) == 0 {
+		panic("no return value specified for FindAddressLabels")
+	}
+
+	var r0 []*models.AddressLabel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) ([]*models.AddressLabel, error)); ok {
+		return rf(userID, addresses)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) []*models.AddressLabel); ok {
+		r0 = rf(userID, addresses)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AddressLabel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(userID, addresses)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_FindAddressLabels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FindAddressLabels'
+type DB_FindAddressLabels_Call struct {
+	*mock.Call
+}
+
+// FindAddressLabels is a helper method to define mock.On call
+//   - userID string
+//   - addresses []string
+func (_e *DB_Expecter) FindAddressLabels(userID interface{}, addresses interface{}) *DB_FindAddressLabels_Call {
+	return &DB_FindAddressLabels_Call{Call: _e.mock.On("FindAddressLabels", userID, addresses)}
+}
+
+func (_c *DB_FindAddressLabels_Call) Run(run func(userID string, addresses []string)) *DB_FindAddressLabels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *DB_FindAddressLabels_Call) Return(_a0 []*models.AddressLabel, _a1 error) *DB_FindAddressLabels_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_FindAddressLabels_Call) RunAndReturn(run func(string, []string) ([]*models.AddressLabel, error)) *DB_FindAddressLabels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeyByHash provides a mock function with given fields: hash
+func (_m *DB) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeyByHash")
+	}
+
+	var r0 *models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.APIKey, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.APIKey); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetAPIKeyByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeyByHash'
+type DB_GetAPIKeyByHash_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeyByHash is a helper method to define mock.On call
+//   - hash string
+func (_e *DB_Expecter) GetAPIKeyByHash(hash interface{}) *DB_GetAPIKeyByHash_Call {
+	return &DB_GetAPIKeyByHash_Call{Call: _e.mock.On("GetAPIKeyByHash", hash)}
+}
+
+func (_c *DB_GetAPIKeyByHash_Call) Run(run func(hash string)) *DB_GetAPIKeyByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetAPIKeyByHash_Call) Return(_a0 *models.APIKey, _a1 error) *DB_GetAPIKeyByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetAPIKeyByHash_Call) RunAndReturn(run func(string) (*models.APIKey, error)) *DB_GetAPIKeyByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAPIKeys provides a mock function with given fields: userID
+func (_m *DB) GetAPIKeys(userID string) ([]*models.APIKey, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAPIKeys")
+	}
+
+	var r0 []*models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.APIKey, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.APIKey); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAPIKeys'
+type DB_GetAPIKeys_Call struct {
+	*mock.Call
+}
+
+// GetAPIKeys is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) GetAPIKeys(userID interface{}) *DB_GetAPIKeys_Call {
+	return &DB_GetAPIKeys_Call{Call: _e.mock.On("GetAPIKeys", userID)}
+}
+
+func (_c *DB_GetAPIKeys_Call) Run(run func(userID string)) *DB_GetAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetAPIKeys_Call) Return(_a0 []*models.APIKey, _a1 error) *DB_GetAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetAPIKeys_Call) RunAndReturn(run func(string) ([]*models.APIKey, error)) *DB_GetAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAddressLabels provides a mock function with given fields: userID
+func (_m *DB) GetAddressLabels(userID string) ([]*models.AddressLabel, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddressLabels")
+	}
+
+	var r0 []*models.AddressLabel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.AddressLabel, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.AddressLabel); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AddressLabel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetAddressLabels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAddressLabels'
+type DB_GetAddressLabels_Call struct {
+	*mock.Call
+}
+
+// GetAddressLabels is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) GetAddressLabels(userID interface{}) *DB_GetAddressLabels_Call {
+	return &DB_GetAddressLabels_Call{Call: _e.mock.On("GetAddressLabels", userID)}
+}
+
+func (_c *DB_GetAddressLabels_Call) Run(run func(userID string)) *DB_GetAddressLabels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetAddressLabels_Call) Return(_a0 []*models.AddressLabel, _a1 error) *DB_GetAddressLabels_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetAddressLabels_Call) RunAndReturn(run func(string) ([]*models.AddressLabel, error)) *DB_GetAddressLabels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAddressStats provides a mock function with given fields: address
+func (_m *DB) GetAddressStats(address string) (*models.AddressStats, error) {
+	ret := _m.Called(address)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddressStats")
+	}
+
+	var r0 *models.AddressStats
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.AddressStats, error)); ok {
+		return rf(address)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.AddressStats); ok {
+		r0 = rf(address)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.AddressStats)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(address)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetAddressStats_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAddressStats'
+type DB_GetAddressStats_Call struct {
+	*mock.Call
+}
+
+// GetAddressStats is a helper method to define mock.On call
+//   - address string
+func (_e *DB_Expecter) GetAddressStats(address interface{}) *DB_GetAddressStats_Call {
+	return &DB_GetAddressStats_Call{Call: _e.mock.On("GetAddressStats", address)}
+}
+
+func (_c *DB_GetAddressStats_Call) Run(run func(address string)) *DB_GetAddressStats_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetAddressStats_Call) Return(_a0 *models.AddressStats, _a1 error) *DB_GetAddressStats_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetAddressStats_Call) RunAndReturn(run func(string) (*models.AddressStats, error)) *DB_GetAddressStats_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllTransactions provides a mock function with given fields:
+func (_m *DB) GetAllTransactions() ([]*models.Transaction, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllTransactions")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Transaction, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Transaction); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetAllTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTransactions'
+type DB_GetAllTransactions_Call struct {
+	*mock.Call
+}
+
+// GetAllTransactions is a helper method to define mock.On call
+func (_e *DB_Expecter) GetAllTransactions() *DB_GetAllTransactions_Call {
+	return &DB_GetAllTransactions_Call{Call: _e.mock.On("GetAllTransactions")}
+}
+
+func (_c *DB_GetAllTransactions_Call) Run(run func()) *DB_GetAllTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *DB_GetAllTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetAllTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetAllTransactions_Call) RunAndReturn(run func() ([]*models.Transaction, error)) *DB_GetAllTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBackfillJob provides a mock function with given fields: id
+func (_m *DB) GetBackfillJob(id string) (*models.BackfillJob, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBackfillJob")
+	}
+
+	var r0 *models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.BackfillJob, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.BackfillJob); ok {
+		r0 = rf(id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetBackfillJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBackfillJob'
+type DB_GetBackfillJob_Call struct {
+	*mock.Call
+}
+
+// GetBackfillJob is a helper method to define mock.On call
+//   - id string
+func (_e *DB_Expecter) GetBackfillJob(id interface{}) *DB_GetBackfillJob_Call {
+	return &DB_GetBackfillJob_Call{Call: _e.mock.On("GetBackfillJob", id)}
+}
+
+func (_c *DB_GetBackfillJob_Call) Run(run func(id string)) *DB_GetBackfillJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetBackfillJob_Call) Return(_a0 *models.BackfillJob, _a1 error) *DB_GetBackfillJob_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetBackfillJob_Call) RunAndReturn(run func(string) (*models.BackfillJob, error)) *DB_GetBackfillJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBackfillJobs provides a mock function with given fields: status
+func (_m *DB) GetBackfillJobs(status string) ([]*models.BackfillJob, error) {
+	ret := _m.Called(status)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBackfillJobs")
+	}
+
+	var r0 []*models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.BackfillJob, error)); ok {
+		return rf(status)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.BackfillJob); ok {
+		r0 = rf(status)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(status)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetBackfillJobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBackfillJobs'
+type DB_GetBackfillJobs_Call struct {
+	*mock.Call
+}
+
+// GetBackfillJobs is a helper method to define mock.On call
+//   - status string
+func (_e *DB_Expecter) GetBackfillJobs(status interface{}) *DB_GetBackfillJobs_Call {
+	return &DB_GetBackfillJobs_Call{Call: _e.mock.On("GetBackfillJobs", status)}
+}
+
+func (_c *DB_GetBackfillJobs_Call) Run(run func(status string)) *DB_GetBackfillJobs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetBackfillJobs_Call) Return(_a0 []*models.BackfillJob, _a1 error) *DB_GetBackfillJobs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetBackfillJobs_Call) RunAndReturn(run func(string) ([]*models.BackfillJob, error)) *DB_GetBackfillJobs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetRefreshTokenByHash provides a mock function with given fields: hash
+func (_m *DB) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	ret := _m.Called(hash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetRefreshTokenByHash")
+	}
+
+	var r0 *models.RefreshToken
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.RefreshToken, error)); ok {
+		return rf(hash)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.RefreshToken); ok {
+		r0 = rf(hash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.RefreshToken)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(hash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetRefreshTokenByHash_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetRefreshTokenByHash'
+type DB_GetRefreshTokenByHash_Call struct {
+	*mock.Call
+}
+
+// GetRefreshTokenByHash is a helper method to define mock.On call
+//   - hash string
+func (_e *DB_Expecter) GetRefreshTokenByHash(hash interface{}) *DB_GetRefreshTokenByHash_Call {
+	return &DB_GetRefreshTokenByHash_Call{Call: _e.mock.On("GetRefreshTokenByHash", hash)}
+}
+
+func (_c *DB_GetRefreshTokenByHash_Call) Run(run func(hash string)) *DB_GetRefreshTokenByHash_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetRefreshTokenByHash_Call) Return(_a0 *models.RefreshToken, _a1 error) *DB_GetRefreshTokenByHash_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetRefreshTokenByHash_Call) RunAndReturn(run func(string) (*models.RefreshToken, error)) *DB_GetRefreshTokenByHash_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetSigningKeys provides a mock function with given fields:
+func (_m *DB) GetSigningKeys() ([]*models.SigningKey, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetSigningKeys")
+	}
+
+	var r0 []*models.SigningKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.SigningKey, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.SigningKey); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.SigningKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetSigningKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetSigningKeys'
+type DB_GetSigningKeys_Call struct {
+	*mock.Call
+}
+
+// GetSigningKeys is a helper method to define mock.On call
+func (_e *DB_Expecter) GetSigningKeys() *DB_GetSigningKeys_Call {
+	return &DB_GetSigningKeys_Call{Call: _e.mock.On("GetSigningKeys")}
+}
+
+func (_c *DB_GetSigningKeys_Call) Run(run func()) *DB_GetSigningKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *DB_GetSigningKeys_Call) Return(_a0 []*models.SigningKey, _a1 error) *DB_GetSigningKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetSigningKeys_Call) RunAndReturn(run func() ([]*models.SigningKey, error)) *DB_GetSigningKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTransactionsByAddress provides a mock function with given fields: address, after, limit
+func (_m *DB) GetTransactionsByAddress(address string, after *models.TransactionCursor, limit int) ([]*models.Transaction, error) {
+	ret := _m.Called(address, after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsByAddress")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, *models.TransactionCursor, int) ([]*models.Transaction, error)); ok {
+		return rf(address, after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, *models.TransactionCursor, int) []*models.Transaction); ok {
+		r0 = rf(address, after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, *models.TransactionCursor, int) error); ok {
+		r1 = rf(address, after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetTransactionsByAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsByAddress'
+type DB_GetTransactionsByAddress_Call struct {
+	*mock.Call
+}
+
+// GetTransactionsByAddress is a helper method to define mock.On call
+//   - address string
+//   - after *models.TransactionCursor
+//   - limit int
+func (_e *DB_Expecter) GetTransactionsByAddress(address interface{}, after interface{}, limit interface{}) *DB_GetTransactionsByAddress_Call {
+	return &DB_GetTransactionsByAddress_Call{Call: _e.mock.On("GetTransactionsByAddress", address, after, limit)}
+}
+
+func (_c *DB_GetTransactionsByAddress_Call) Run(run func(address string, after *models.TransactionCursor, limit int)) *DB_GetTransactionsByAddress_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*models.TransactionCursor), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *DB_GetTransactionsByAddress_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetTransactionsByAddress_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetTransactionsByAddress_Call) RunAndReturn(run func(string, *models.TransactionCursor, int) ([]*models.Transaction, error)) *DB_GetTransactionsByAddress_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTransactionsByHashes provides a mock function with given fields: hashes
+func (_m *DB) GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error) {
+	ret := _m.Called(hashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsByHashes")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func([]string) ([]*models.Transaction, error)); ok {
+		return rf(hashes)
+	}
+	if rf, ok := ret.Get(0).(func([]string) []*models.Transaction); ok {
+		r0 = rf(hashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func([]string) error); ok {
+		r1 = rf(hashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetTransactionsByHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsByHashes'
+type DB_GetTransactionsByHashes_Call struct {
+	*mock.Call
+}
+
+// GetTransactionsByHashes is a helper method to define mock.On call
+//   - hashes []string
+func (_e *DB_Expecter) GetTransactionsByHashes(hashes interface{}) *DB_GetTransactionsByHashes_Call {
+	return &DB_GetTransactionsByHashes_Call{Call: _e.mock.On("GetTransactionsByHashes", hashes)}
+}
+
+func (_c *DB_GetTransactionsByHashes_Call) Run(run func(hashes []string)) *DB_GetTransactionsByHashes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]string))
+	})
+	return _c
+}
+
+func (_c *DB_GetTransactionsByHashes_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetTransactionsByHashes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetTransactionsByHashes_Call) RunAndReturn(run func([]string) ([]*models.Transaction, error)) *DB_GetTransactionsByHashes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTransactionsPage provides a mock function with given fields: after, limit
+func (_m *DB) GetTransactionsPage(after string, limit int) ([]*models.Transaction, error) {
+	ret := _m.Called(after, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsPage")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int) ([]*models.Transaction, error)); ok {
+		return rf(after, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, int) []*models.Transaction); ok {
+		r0 = rf(after, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int) error); ok {
+		r1 = rf(after, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetTransactionsPage_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsPage'
+type DB_GetTransactionsPage_Call struct {
+	*mock.Call
+}
+
+// GetTransactionsPage is a helper method to define mock.On call
+//   - after string
+//   - limit int
+func (_e *DB_Expecter) GetTransactionsPage(after interface{}, limit interface{}) *DB_GetTransactionsPage_Call {
+	return &DB_GetTransactionsPage_Call{Call: _e.mock.On("GetTransactionsPage", after, limit)}
+}
+
+func (_c *DB_GetTransactionsPage_Call) Run(run func(after string, limit int)) *DB_GetTransactionsPage_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int))
+	})
+	return _c
+}
+
+func (_c *DB_GetTransactionsPage_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetTransactionsPage_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetTransactionsPage_Call) RunAndReturn(run func(string, int) ([]*models.Transaction, error)) *DB_GetTransactionsPage_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByExternalID provides a mock function with given fields: issuer, subject
+func (_m *DB) GetUserByExternalID(issuer string, subject string) (*models.User, error) {
+	ret := _m.Called(issuer, subject)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByExternalID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*models.User, error)); ok {
+		return rf(issuer, subject)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *models.User); ok {
+		r0 = rf(issuer, subject)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(issuer, subject)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUserByExternalID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByExternalID'
+type DB_GetUserByExternalID_Call struct {
+	*mock.Call
+}
+
+// GetUserByExternalID is a helper method to define mock.On call
+//   - issuer string
+//   - subject string
+func (_e *DB_Expecter) GetUserByExternalID(issuer interface{}, subject interface{}) *DB_GetUserByExternalID_Call {
+	return &DB_GetUserByExternalID_Call{Call: _e.mock.On("GetUserByExternalID", issuer, subject)}
+}
+
+func (_c *DB_GetUserByExternalID_Call) Run(run func(issuer string, subject string)) *DB_GetUserByExternalID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetUserByExternalID_Call) Return(_a0 *models.User, _a1 error) *DB_GetUserByExternalID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUserByExternalID_Call) RunAndReturn(run func(string, string) (*models.User, error)) *DB_GetUserByExternalID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByID provides a mock function with given fields: userID
+func (_m *DB) GetUserByID(userID string) (*models.User, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByID")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUserByID_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByID'
+type DB_GetUserByID_Call struct {
+	*mock.Call
+}
+
+// GetUserByID is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) GetUserByID(userID interface{}) *DB_GetUserByID_Call {
+	return &DB_GetUserByID_Call{Call: _e.mock.On("GetUserByID", userID)}
+}
+
+func (_c *DB_GetUserByID_Call) Run(run func(userID string)) *DB_GetUserByID_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetUserByID_Call) Return(_a0 *models.User, _a1 error) *DB_GetUserByID_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUserByID_Call) RunAndReturn(run func(string) (*models.User, error)) *DB_GetUserByID_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserByUsername provides a mock function with given fields: username
+func (_m *DB) GetUserByUsername(username string) (*models.User, error) {
+	ret := _m.Called(username)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserByUsername")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
+		return rf(username)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
+		r0 = rf(username)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(username)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUserByUsername_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserByUsername'
+type DB_GetUserByUsername_Call struct {
+	*mock.Call
+}
+
+// GetUserByUsername is a helper method to define mock.On call
+//   - username string
+func (_e *DB_Expecter) GetUserByUsername(username interface{}) *DB_GetUserByUsername_Call {
+	return &DB_GetUserByUsername_Call{Call: _e.mock.On("GetUserByUsername", username)}
+}
+
+func (_c *DB_GetUserByUsername_Call) Run(run func(username string)) *DB_GetUserByUsername_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetUserByUsername_Call) Return(_a0 *models.User, _a1 error) *DB_GetUserByUsername_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUserByUsername_Call) RunAndReturn(run func(string) (*models.User, error)) *DB_GetUserByUsername_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserTransactions provides a mock function with given fields: userID
+func (_m *DB) GetUserTransactions(userID string) ([]*models.Transaction, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTransactions")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.Transaction, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.Transaction); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUserTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserTransactions'
+type DB_GetUserTransactions_Call struct {
+	*mock.Call
+}
+
+// GetUserTransactions is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) GetUserTransactions(userID interface{}) *DB_GetUserTransactions_Call {
+	return &DB_GetUserTransactions_Call{Call: _e.mock.On("GetUserTransactions", userID)}
+}
+
+func (_c *DB_GetUserTransactions_Call) Run(run func(userID string)) *DB_GetUserTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetUserTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *DB_GetUserTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUserTransactions_Call) RunAndReturn(run func(string) ([]*models.Transaction, error)) *DB_GetUserTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserViews provides a mock function with given fields: userID
+func (_m *DB) GetUserViews(userID string) ([]*models.TransactionView, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserViews")
+	}
+
+	var r0 []*models.TransactionView
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.TransactionView, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.TransactionView); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.TransactionView)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUserViews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserViews'
+type DB_GetUserViews_Call struct {
+	*mock.Call
+}
+
+// GetUserViews is a helper method to define mock.On call
+//   - userID string
+func (_e *DB_Expecter) GetUserViews(userID interface{}) *DB_GetUserViews_Call {
+	return &DB_GetUserViews_Call{Call: _e.mock.On("GetUserViews", userID)}
+}
+
+func (_c *DB_GetUserViews_Call) Run(run func(userID string)) *DB_GetUserViews_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_GetUserViews_Call) Return(_a0 []*models.TransactionView, _a1 error) *DB_GetUserViews_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUserViews_Call) RunAndReturn(run func(string) ([]*models.TransactionView, error)) *DB_GetUserViews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUsers provides a mock function with given fields:
+func (_m *DB) GetUsers() ([]*models.User, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUsers")
+	}
+
+	var r0 []*models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.User, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.User); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_GetUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUsers'
+type DB_GetUsers_Call struct {
+	*mock.Call
+}
+
+// GetUsers is a helper method to define mock.On call
+func (_e *DB_Expecter) GetUsers() *DB_GetUsers_Call {
+	return &DB_GetUsers_Call{Call: _e.mock.On("GetUsers")}
+}
+
+func (_c *DB_GetUsers_Call) Run(run func()) *DB_GetUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *DB_GetUsers_Call) Return(_a0 []*models.User, _a1 error) *DB_GetUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_GetUsers_Call) RunAndReturn(run func() ([]*models.User, error)) *DB_GetUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IsTokenRevoked provides a mock function with given fields: id
+func (_m *DB) IsTokenRevoked(id string) (bool, error) {
+	ret := _m.Called(id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IsTokenRevoked")
+	}
+
+	var r0 bool
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (bool, error)); ok {
+		return rf(id)
+	}
+	if rf, ok := ret.Get(0).(func(string) bool); ok {
+		r0 = rf(id)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_IsTokenRevoked_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IsTokenRevoked'
+type DB_IsTokenRevoked_Call struct {
+	*mock.Call
+}
+
+// IsTokenRevoked is a helper method to define mock.On call
+//   - id string
+func (_e *DB_Expecter) IsTokenRevoked(id interface{}) *DB_IsTokenRevoked_Call {
+	return &DB_IsTokenRevoked_Call{Call: _e.mock.On("IsTokenRevoked", id)}
+}
+
+func (_c *DB_IsTokenRevoked_Call) Run(run func(id string)) *DB_IsTokenRevoked_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *DB_IsTokenRevoked_Call) Return(_a0 bool, _a1 error) *DB_IsTokenRevoked_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_IsTokenRevoked_Call) RunAndReturn(run func(string) (bool, error)) *DB_IsTokenRevoked_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PruneTokens provides a mock function with given fields: now
+func (_m *DB) PruneTokens(now time.Time) (int64, error) {
+	ret := _m.Called(now)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneTokens")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time) (int64, error)); ok {
+		return rf(now)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time) int64); ok {
+		r0 = rf(now)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time) error); ok {
+		r1 = rf(now)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_PruneTokens_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PruneTokens'
+type DB_PruneTokens_Call struct {
+	*mock.Call
+}
+
+// PruneTokens is a helper method to define mock.On call
+//   - now time.Time
+func (_e *DB_Expecter) PruneTokens(now interface{}) *DB_PruneTokens_Call {
+	return &DB_PruneTokens_Call{Call: _e.mock.On("PruneTokens", now)}
+}
+
+func (_c *DB_PruneTokens_Call) Run(run func(now time.Time)) *DB_PruneTokens_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time))
+	})
+	return _c
+}
+
+func (_c *DB_PruneTokens_Call) Return(_a0 int64, _a1 error) *DB_PruneTokens_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_PruneTokens_Call) RunAndReturn(run func(time.Time) (int64, error)) *DB_PruneTokens_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// PruneTransactions provides a mock function with given fields: notViewedSince, dryRun
+func (_m *DB) PruneTransactions(notViewedSince time.Time, dryRun bool) (int64, error) {
+	ret := _m.Called(notViewedSince, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for PruneTransactions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(time.Time, bool) (int64, error)); ok {
+		return rf(notViewedSince, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(time.Time, bool) int64); ok {
+		r0 = rf(notViewedSince, dryRun)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(time.Time, bool) error); ok {
+		r1 = rf(notViewedSince, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_PruneTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'PruneTransactions'
+type DB_PruneTransactions_Call struct {
+	*mock.Call
+}
+
+// PruneTransactions is a helper method to define mock.On call
+//   - notViewedSince time.Time
+//   - dryRun bool
+func (_e *DB_Expecter) PruneTransactions(notViewedSince interface{}, dryRun interface{}) *DB_PruneTransactions_Call {
+	return &DB_PruneTransactions_Call{Call: _e.mock.On("PruneTransactions", notViewedSince, dryRun)}
+}
+
+func (_c *DB_PruneTransactions_Call) Run(run func(notViewedSince time.Time, dryRun bool)) *DB_PruneTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(time.Time), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *DB_PruneTransactions_Call) Return(_a0 int64, _a1 error) *DB_PruneTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_PruneTransactions_Call) RunAndReturn(run func(time.Time, bool) (int64, error)) *DB_PruneTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RestoreUserViews provides a mock function with given fields: userID, views
+func (_m *DB) RestoreUserViews(userID string, views []*models.TransactionView) error {
+	ret := _m.Called(userID, views)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RestoreUserViews")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, []*models.TransactionView) error); ok {
+		r0 = rf(userID, views)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_RestoreUserViews_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RestoreUserViews'
+type DB_RestoreUserViews_Call struct {
+	*mock.Call
+}
+
+// RestoreUserViews is a helper method to define mock.On call
+//   - userID string
+//   - views []*models.TransactionView
+func (_e *DB_Expecter) RestoreUserViews(userID interface{}, views interface{}) *DB_RestoreUserViews_Call {
+	return &DB_RestoreUserViews_Call{Call: _e.mock.On("RestoreUserViews", userID, views)}
+}
+
+func (_c *DB_RestoreUserViews_Call) Run(run func(userID string, views []*models.TransactionView)) *DB_RestoreUserViews_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]*models.TransactionView))
+	})
+	return _c
+}
+
+func (_c *DB_RestoreUserViews_Call) Return(_a0 error) *DB_RestoreUserViews_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_RestoreUserViews_Call) RunAndReturn(run func(string, []*models.TransactionView) error) *DB_RestoreUserViews_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeToken provides a mock function with given fields: token
+func (_m *DB) RevokeToken(token *models.RevokedToken) error {
+	ret := _m.Called(token)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeToken")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.RevokedToken) error); ok {
+		r0 = rf(token)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_RevokeToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeToken'
+type DB_RevokeToken_Call struct {
+	*mock.Call
+}
+
+// RevokeToken is a helper method to define mock.On call
+//   - token *models.RevokedToken
+func (_e *DB_Expecter) RevokeToken(token interface{}) *DB_RevokeToken_Call {
+	return &DB_RevokeToken_Call{Call: _e.mock.On("RevokeToken", token)}
+}
+
+func (_c *DB_RevokeToken_Call) Run(run func(token *models.RevokedToken)) *DB_RevokeToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.RevokedToken))
+	})
+	return _c
+}
+
+func (_c *DB_RevokeToken_Call) Return(_a0 error) *DB_RevokeToken_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_RevokeToken_Call) RunAndReturn(run func(*models.RevokedToken) error) *DB_RevokeToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveAddressLabels provides a mock function with given fields: labels
+func (_m *DB) SaveAddressLabels(labels []*models.AddressLabel) error {
+	ret := _m.Called(labels)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveAddressLabels")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.AddressLabel) error); ok {
+		r0 = rf(labels)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_SaveAddressLabels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveAddressLabels'
+type DB_SaveAddressLabels_Call struct {
+	*mock.Call
+}
+
+// SaveAddressLabels is a helper method to define mock.On call
+//   - labels []*models.AddressLabel
+func (_e *DB_Expecter) SaveAddressLabels(labels interface{}) *DB_SaveAddressLabels_Call {
+	return &DB_SaveAddressLabels_Call{Call: _e.mock.On("SaveAddressLabels", labels)}
+}
+
+func (_c *DB_SaveAddressLabels_Call) Run(run func(labels []*models.AddressLabel)) *DB_SaveAddressLabels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]*models.AddressLabel))
+	})
+	return _c
+}
+
+func (_c *DB_SaveAddressLabels_Call) Return(_a0 error) *DB_SaveAddressLabels_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_SaveAddressLabels_Call) RunAndReturn(run func([]*models.AddressLabel) error) *DB_SaveAddressLabels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveBackfillJob provides a mock function with given fields: job
+func (_m *DB) SaveBackfillJob(job *models.BackfillJob) error {
+	ret := _m.Called(job)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveBackfillJob")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(*models.BackfillJob) error); ok {
+		r0 = rf(job)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_SaveBackfillJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveBackfillJob'
+type DB_SaveBackfillJob_Call struct {
+	*mock.Call
+}
+
+// SaveBackfillJob is a helper method to define mock.On call
+//   - job *models.BackfillJob
+func (_e *DB_Expecter) SaveBackfillJob(job interface{}) *DB_SaveBackfillJob_Call {
+	return &DB_SaveBackfillJob_Call{Call: _e.mock.On("SaveBackfillJob", job)}
+}
+
+func (_c *DB_SaveBackfillJob_Call) Run(run func(job *models.BackfillJob)) *DB_SaveBackfillJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(*models.BackfillJob))
+	})
+	return _c
+}
+
+func (_c *DB_SaveBackfillJob_Call) Return(_a0 error) *DB_SaveBackfillJob_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_SaveBackfillJob_Call) RunAndReturn(run func(*models.BackfillJob) error) *DB_SaveBackfillJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SaveTransactions provides a mock function with given fields: transactions
+func (_m *DB) SaveTransactions(transactions []*models.Transaction) error {
+	ret := _m.Called(transactions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SaveTransactions")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]*models.Transaction) error); ok {
+		r0 = rf(transactions)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_SaveTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveTransactions'
+type DB_SaveTransactions_Call struct {
+	*mock.Call
+}
+
+// SaveTransactions is a helper method to define mock.On call
+//   - transactions []*models.Transaction
+func (_e *DB_Expecter) SaveTransactions(transactions interface{}) *DB_SaveTransactions_Call {
+	return &DB_SaveTransactions_Call{Call: _e.mock.On("SaveTransactions", transactions)}
+}
+
+func (_c *DB_SaveTransactions_Call) Run(run func(transactions []*models.Transaction)) *DB_SaveTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].([]*models.Transaction))
+	})
+	return _c
+}
+
+func (_c *DB_SaveTransactions_Call) Return(_a0 error) *DB_SaveTransactions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_SaveTransactions_Call) RunAndReturn(run func([]*models.Transaction) error) *DB_SaveTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserDisabled provides a mock function with given fields: userID, disabled
+func (_m *DB) SetUserDisabled(userID string, disabled bool) error {
+	ret := _m.Called(userID, disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserDisabled")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, bool) error); ok {
+		r0 = rf(userID, disabled)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_SetUserDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserDisabled'
+type DB_SetUserDisabled_Call struct {
+	*mock.Call
+}
+
+// SetUserDisabled is a helper method to define mock.On call
+//   - userID string
+//   - disabled bool
+func (_e *DB_Expecter) SetUserDisabled(userID interface{}, disabled interface{}) *DB_SetUserDisabled_Call {
+	return &DB_SetUserDisabled_Call{Call: _e.mock.On("SetUserDisabled", userID, disabled)}
+}
+
+func (_c *DB_SetUserDisabled_Call) Run(run func(userID string, disabled bool)) *DB_SetUserDisabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *DB_SetUserDisabled_Call) Return(_a0 error) *DB_SetUserDisabled_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_SetUserDisabled_Call) RunAndReturn(run func(string, bool) error) *DB_SetUserDisabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserRole provides a mock function with given fields: userID, role
+func (_m *DB) SetUserRole(userID string, role models.Role) error {
+	ret := _m.Called(userID, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserRole")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, models.Role) error); ok {
+		r0 = rf(userID, role)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_SetUserRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserRole'
+type DB_SetUserRole_Call struct {
+	*mock.Call
+}
+
+// SetUserRole is a helper method to define mock.On call
+//   - userID string
+//   - role models.Role
+func (_e *DB_Expecter) SetUserRole(userID interface{}, role interface{}) *DB_SetUserRole_Call {
+	return &DB_SetUserRole_Call{Call: _e.mock.On("SetUserRole", userID, role)}
+}
+
+func (_c *DB_SetUserRole_Call) Run(run func(userID string, role models.Role)) *DB_SetUserRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(models.Role))
+	})
+	return _c
+}
+
+func (_c *DB_SetUserRole_Call) Return(_a0 error) *DB_SetUserRole_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_SetUserRole_Call) RunAndReturn(run func(string, models.Role) error) *DB_SetUserRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TouchAPIKey provides a mock function with given fields: id, usedAt
+func (_m *DB) TouchAPIKey(id string, usedAt time.Time) error {
+	ret := _m.Called(id, usedAt)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TouchAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(id, usedAt)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_TouchAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TouchAPIKey'
+type DB_TouchAPIKey_Call struct {
+	*mock.Call
+}
+
+// TouchAPIKey is a helper method to define mock.On call
+//   - id string
+//   - usedAt time.Time
+func (_e *DB_Expecter) TouchAPIKey(id interface{}, usedAt interface{}) *DB_TouchAPIKey_Call {
+	return &DB_TouchAPIKey_Call{Call: _e.mock.On("TouchAPIKey", id, usedAt)}
+}
+
+func (_c *DB_TouchAPIKey_Call) Run(run func(id string, usedAt time.Time)) *DB_TouchAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(time.Time))
+	})
+	return _c
+}
+
+func (_c *DB_TouchAPIKey_Call) Return(_a0 error) *DB_TouchAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_TouchAPIKey_Call) RunAndReturn(run func(string, time.Time) error) *DB_TouchAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// TrimUserTransactions provides a mock function with given fields: maxPerUser, dryRun
+func (_m *DB) TrimUserTransactions(maxPerUser int, dryRun bool) (int64, error) {
+	ret := _m.Called(maxPerUser, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for TrimUserTransactions")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int, bool) (int64, error)); ok {
+		return rf(maxPerUser, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(int, bool) int64); ok {
+		r0 = rf(maxPerUser, dryRun)
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func(int, bool) error); ok {
+		r1 = rf(maxPerUser, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// DB_TrimUserTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'TrimUserTransactions'
+type DB_TrimUserTransactions_Call struct {
+	*mock.Call
+}
+
+// TrimUserTransactions is a helper method to define mock.On call
+//   - maxPerUser int
+//   - dryRun bool
+func (_e *DB_Expecter) TrimUserTransactions(maxPerUser interface{}, dryRun interface{}) *DB_TrimUserTransactions_Call {
+	return &DB_TrimUserTransactions_Call{Call: _e.mock.On("TrimUserTransactions", maxPerUser, dryRun)}
+}
+
+func (_c *DB_TrimUserTransactions_Call) Run(run func(maxPerUser int, dryRun bool)) *DB_TrimUserTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *DB_TrimUserTransactions_Call) Return(_a0 int64, _a1 error) *DB_TrimUserTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *DB_TrimUserTransactions_Call) RunAndReturn(run func(int, bool) (int64, error)) *DB_TrimUserTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// UpdateUserPassword provides a mock function with given fields: userID, password
+func (_m *DB) UpdateUserPassword(userID string, password string) error {
+	ret := _m.Called(userID, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for UpdateUserPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DB_UpdateUserPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UpdateUserPassword'
+type DB_UpdateUserPassword_Call struct {
+	*mock.Call
+}
+
+// UpdateUserPassword is a helper method to define mock.On call
+//   - userID string
+//   - password string
+func (_e *DB_Expecter) UpdateUserPassword(userID interface{}, password interface{}) *DB_UpdateUserPassword_Call {
+	return &DB_UpdateUserPassword_Call{Call: _e.mock.On("UpdateUserPassword", userID, password)}
+}
+
+func (_c *DB_UpdateUserPassword_Call) Run(run func(userID string, password string)) *DB_UpdateUserPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *DB_UpdateUserPassword_Call) Return(_a0 error) *DB_UpdateUserPassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *DB_UpdateUserPassword_Call) RunAndReturn(run func(string, string) error) *DB_UpdateUserPassword_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// SaveTransaction provides a mock function with given fields: transaction
-func (_m *DB) SaveTransaction(transaction *models.Transaction) error {
-	ret := _m.Called(transaction)
+// UseRefreshToken provides a mock function with given fields: id, usedAt
+func (_m *DB) UseRefreshToken(id string, usedAt time.Time) error {
+	ret := _m.Called(id, usedAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for SaveTransaction")
+		panic("no return value specified for UseRefreshToken")
 	}
 
 	var r0 error
-	if rf, ok := ret.Get(0).(func(*models.Transaction) error); ok {
-		r0 = rf(transaction)
+	if rf, ok := ret.Get(0).(func(string, time.Time) error); ok {
+		r0 = rf(id, usedAt)
 	} else {
 		r0 = ret.Error(0)
 	}
@@ -362,30 +2344,31 @@ func (_m *DB) SaveTransaction(transaction *models.Transaction) error {
 	return r0
 }
 
-// DB_SaveTransaction_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SaveTransaction'
-type DB_SaveTransaction_Call struct {
+// DB_UseRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'UseRefreshToken'
+type DB_UseRefreshToken_Call struct {
 	*mock.Call
 }
 
-// SaveTransaction is a helper method to define mock.On call
-//   - transaction *models.Transaction
-func (_e *DB_Expecter) SaveTransaction(transaction interface{}) *DB_SaveTransaction_Call {
-	return &DB_SaveTransaction_Call{Call: _e.mock.On("SaveTransaction", transaction)}
+// UseRefreshToken is a helper method to define mock.On call
+//   - id string
+//   - usedAt time.Time
+func (_e *DB_Expecter) UseRefreshToken(id interface{}, usedAt interface{}) *DB_UseRefreshToken_Call {
+	return &DB_UseRefreshToken_Call{Call: _e.mock.On("UseRefreshToken", id, usedAt)}
 }
 
-func (_c *DB_SaveTransaction_Call) Run(run func(transaction *models.Transaction)) *DB_SaveTransaction_Call {
+func (_c *DB_UseRefreshToken_Call) Run(run func(id string, usedAt time.Time)) *DB_UseRefreshToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(*models.Transaction))
+		run(args[0].(string), args[1].(time.Time))
 	})
 	return _c
 }
 
-func (_c *DB_SaveTransaction_Call) Return(_a0 error) *DB_SaveTransaction_Call {
+func (_c *DB_UseRefreshToken_Call) Return(_a0 error) *DB_UseRefreshToken_Call {
 	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *DB_SaveTransaction_Call) RunAndReturn(run func(*models.Transaction) error) *DB_SaveTransaction_Call {
+func (_c *DB_UseRefreshToken_Call) RunAndReturn(run func(string, time.Time) error) *DB_UseRefreshToken_Call {
 	_c.Call.Return(run)
 	return _c
 }
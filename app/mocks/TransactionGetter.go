@@ -53,6 +53,64 @@ func (_c *TransactionGetter_Close_Call) RunAndReturn(run func()) *TransactionGet
 	return _c
 }
 
+// GetBlockTransactions provides a mock function with given fields: blockNumber
+func (_m *TransactionGetter) GetBlockTransactions(blockNumber int64) ([]*models.Transaction, error) {
+	ret := _m.Called(blockNumber)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBlockTransactions")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(int64) ([]*models.Transaction, error)); ok {
+		return rf(blockNumber)
+	}
+	if rf, ok := ret.Get(0).(func(int64) []*models.Transaction); ok {
+		r0 = rf(blockNumber)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(int64) error); ok {
+		r1 = rf(blockNumber)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// TransactionGetter_GetBlockTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBlockTransactions'
+type TransactionGetter_GetBlockTransactions_Call struct {
+	*mock.Call
+}
+
+// GetBlockTransactions is a helper method to define mock.On call
+//   - blockNumber int64
+func (_e *TransactionGetter_Expecter) GetBlockTransactions(blockNumber interface{}) *TransactionGetter_GetBlockTransactions_Call {
+	return &TransactionGetter_GetBlockTransactions_Call{Call: _e.mock.On("GetBlockTransactions", blockNumber)}
+}
+
+func (_c *TransactionGetter_GetBlockTransactions_Call) Run(run func(blockNumber int64)) *TransactionGetter_GetBlockTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(int64))
+	})
+	return _c
+}
+
+func (_c *TransactionGetter_GetBlockTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *TransactionGetter_GetBlockTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *TransactionGetter_GetBlockTransactions_Call) RunAndReturn(run func(int64) ([]*models.Transaction, error)) *TransactionGetter_GetBlockTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // GetTransaction provides a mock function with given fields: txID
 func (_m *TransactionGetter) GetTransaction(txID string) (*models.Transaction, error) {
 	ret := _m.Called(txID)
@@ -0,0 +1,113 @@
+package app
+
+import (
+	"fmt"
+	"time"
+)
+
+// RetentionPolicy decides which stored data is pruned. Zero values keep everything.
+type RetentionPolicy struct {
+	// TransactionTTL prunes the transactions no user viewed for longer. Transactions
+	// involving a labelled address are kept indefinitely.
+	TransactionTTL time.Duration
+	// MaxUserHistory caps the number of viewed transactions kept per user.
+	MaxUserHistory int
+	// Interval is how often the policy is applied in the background, zero disables it.
+	Interval time.Duration
+	// DryRun only reports what the background job would prune.
+	DryRun bool
+}
+
+// PruneReport is the outcome of applying the retention policy once.
+type PruneReport struct {
+	DryRun bool
+	// UserTransactions is the number of entries removed from user histories.
+	UserTransactions int64
+	// Transactions is the number of deleted transactions.
+	Transactions int64
+}
+
+// WithRetention applies the retention policy every policy.Interval in the background.
+func WithRetention(policy RetentionPolicy) Option {
+	return func(a *App) {
+		a.retention = policy
+	}
+}
+
+// Prune applies the retention policy immediately. With dryRun nothing is deleted and
+// the report counts what would be.
+func (a *App) Prune(userID string, dryRun bool) (*PruneReport, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+
+	return a.prune(dryRun)
+}
+
+// prune trims the user histories first, so the transactions only kept by the trimmed
+// entries can be pruned in the same run.
+func (a *App) prune(dryRun bool) (*PruneReport, error) {
+	report := &PruneReport{DryRun: dryRun}
+
+	if a.retention.MaxUserHistory > 0 {
+		trimmed, err := a.db.TrimUserTransactions(a.retention.MaxUserHistory, dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("trimming user histories: %w", err)
+		}
+		report.UserTransactions = trimmed
+	}
+
+	if a.retention.TransactionTTL > 0 {
+		pruned, err := a.db.PruneTransactions(time.Now().Add(-a.retention.TransactionTTL), dryRun)
+		if err != nil {
+			return nil, fmt.Errorf("pruning transactions: %w", err)
+		}
+		report.Transactions = pruned
+	}
+
+	return report, nil
+}
+
+func (a *App) startRetention() {
+	if a.retention.Interval <= 0 || (a.retention.TransactionTTL <= 0 && a.retention.MaxUserHistory <= 0) {
+		return
+	}
+
+	a.retentionWG.Add(1)
+	go func() {
+		defer a.retentionWG.Done()
+
+		ticker := time.NewTicker(a.retention.Interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.retentionDone:
+				return
+			case <-ticker.C:
+				a.runRetention()
+			}
+		}
+	}()
+}
+
+func (a *App) runRetention() {
+	report, err := a.prune(a.retention.DryRun)
+	if err != nil {
+		a.Log.Errorf("error applying retention policy: %v", err)
+		return
+	}
+
+	if report.DryRun {
+		a.Log.Infof("retention dry run: would prune %d transactions and %d user history entries",
+			report.Transactions, report.UserTransactions)
+		return
+	}
+	a.Log.Infof("retention: pruned %d transactions and %d user history entries",
+		report.Transactions, report.UserTransactions)
+}
+
+func (a *App) stopRetention() {
+	close(a.retentionDone)
+	a.retentionWG.Wait()
+}
@@ -0,0 +1,28 @@
+package app
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+)
+
+// secretBytes is the number of random bytes of API keys and refresh tokens.
+const secretBytes = 24
+
+// newSecret returns a random hex encoded secret starting with prefix.
+func newSecret(prefix string) (string, error) {
+	b := make([]byte, secretBytes)
+	_, err := rand.Read(b)
+	if err != nil {
+		return "", err
+	}
+
+	return prefix + hex.EncodeToString(b), nil
+}
+
+// hashSecret returns the hex encoded SHA-256 hash of the secret. Secrets are random,
+// a fast hash is enough to protect them and lets the store look them up.
+func hashSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,290 @@
+package app
+
+import (
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"errors"
+	"eth-fetcher/database/models"
+	"fmt"
+	"time"
+)
+
+// signingKeysCheckInterval is how often the signing keys are rotated if due and
+// reloaded to pick up the keys of other instances.
+const signingKeysCheckInterval = time.Minute
+
+// signingKeyPublishDelay is how long a new key only verifies tokens before it signs
+// them, so that the other instances have reloaded it by then.
+const signingKeyPublishDelay = 2 * signingKeysCheckInterval
+
+// SigningKeyPolicy configures the asymmetric keys signing the access tokens.
+type SigningKeyPolicy struct {
+	// Algorithm is RS256 or ES256, empty disables the signing keys.
+	Algorithm string
+	// Rotation is how long a key signs new tokens before a new key replaces it.
+	Rotation time.Duration
+	// TokenTTL is how long the access tokens are valid. Replaced keys are kept as long
+	// to verify the tokens they signed.
+	TokenTTL time.Duration
+	// Secret encrypts the private keys in the store.
+	Secret string
+}
+
+// signingKey is a stored signing key with its decrypted private key, which is nil if
+// it cannot be decrypted.
+type signingKey struct {
+	*models.SigningKey
+	private crypto.Signer
+}
+
+// WithSigningKeys creates, rotates and deletes the signing keys of the policy in the
+// background. RotateSigningKeys creates the first key.
+func WithSigningKeys(policy SigningKeyPolicy) Option {
+	return func(a *App) {
+		a.signing = policy
+	}
+}
+
+// RotateSigningKeys reloads the signing keys from the store, creates a new key if the
+// current one is older than the rotation period and deletes the keys that are no
+// longer needed to verify tokens. Instances sharing a store share their keys.
+func (a *App) RotateSigningKeys() error {
+	if a.signing.Algorithm == "" {
+		return nil
+	}
+
+	stored, err := a.db.GetSigningKeys()
+	if err != nil {
+		return fmt.Errorf("loading signing keys: %w", err)
+	}
+
+	now := time.Now()
+	keys := make([]*signingKey, 0, len(stored)+1)
+	var current *signingKey
+	for i, key := range stored {
+		// A replaced key verifies the tokens it signed until they expire.
+		if i+1 < len(stored) && now.Sub(stored[i+1].CreatedAt) > a.signing.TokenTTL+signingKeyPublishDelay {
+			err = a.db.DeleteSigningKey(key.ID)
+			if err != nil {
+				a.Log.Errorf("error deleting signing key %s: %v", key.ID, err)
+			} else {
+				a.Log.Infof("deleted signing key %s", key.ID)
+			}
+			continue
+		}
+
+		private, err := a.openSigningKey(key)
+		if err != nil {
+			a.Log.Errorf("error decrypting signing key %s, it only verifies tokens: %v", key.ID, err)
+		}
+		keys = append(keys, &signingKey{SigningKey: key, private: private})
+		if private != nil && key.Algorithm == a.signing.Algorithm {
+			current = keys[len(keys)-1]
+		}
+	}
+
+	if current == nil || now.Sub(current.CreatedAt) >= a.signing.Rotation {
+		key, err := a.createSigningKey()
+		if err != nil {
+			return fmt.Errorf("creating signing key: %w", err)
+		}
+		a.Log.Infof("created %s signing key %s", key.Algorithm, key.ID)
+		keys = append(keys, key)
+	}
+
+	a.signingKeysMu.Lock()
+	a.signingKeys = keys
+	a.signingKeysMu.Unlock()
+
+	return nil
+}
+
+// CurrentSigningKey returns the newest published signing key with its private key to
+// sign new tokens, or the newest key if none is published yet.
+func (a *App) CurrentSigningKey() (*models.SigningKey, crypto.Signer, error) {
+	a.signingKeysMu.RLock()
+	defer a.signingKeysMu.RUnlock()
+
+	var newest *signingKey
+	for i := len(a.signingKeys) - 1; i >= 0; i-- {
+		key := a.signingKeys[i]
+		if key.private == nil || key.Algorithm != a.signing.Algorithm {
+			continue
+		}
+		if time.Since(key.CreatedAt) >= signingKeyPublishDelay {
+			return key.SigningKey, key.private, nil
+		}
+		if newest == nil {
+			newest = key
+		}
+	}
+	if newest != nil {
+		return newest.SigningKey, newest.private, nil
+	}
+
+	return nil, nil, errors.New("no signing key")
+}
+
+// SigningKey returns the signing key with the ID to verify a token. The ID is not
+// verified yet, so only the loaded keys are searched and the store is not queried.
+// Keys of other instances are loaded with the next rotation check, before they sign.
+func (a *App) SigningKey(id string) (*models.SigningKey, error) {
+	a.signingKeysMu.RLock()
+	defer a.signingKeysMu.RUnlock()
+
+	for _, key := range a.signingKeys {
+		if key.ID == id {
+			return key.SigningKey, nil
+		}
+	}
+
+	return nil, fmt.Errorf("signing key %s %w", id, ErrNotFound)
+}
+
+// SigningKeys returns the signing keys that verify tokens, oldest first.
+func (a *App) SigningKeys() []*models.SigningKey {
+	a.signingKeysMu.RLock()
+	defer a.signingKeysMu.RUnlock()
+
+	keys := make([]*models.SigningKey, len(a.signingKeys))
+	for i, key := range a.signingKeys {
+		keys[i] = key.SigningKey
+	}
+
+	return keys
+}
+
+func (a *App) createSigningKey() (*signingKey, error) {
+	private, err := generateSigningKey(a.signing.Algorithm)
+	if err != nil {
+		return nil, err
+	}
+	public, err := x509.MarshalPKIXPublicKey(private.Public())
+	if err != nil {
+		return nil, err
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(private)
+	if err != nil {
+		return nil, err
+	}
+	sealed, err := a.sealSigningKey(der)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &models.SigningKey{
+		Algorithm:  a.signing.Algorithm,
+		PublicKey:  public,
+		PrivateKey: sealed,
+		CreatedAt:  time.Now().UTC(),
+	}
+	err = a.db.CreateSigningKey(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return &signingKey{SigningKey: key, private: private}, nil
+}
+
+func generateSigningKey(algorithm string) (crypto.Signer, error) {
+	switch algorithm {
+	case "RS256":
+		return rsa.GenerateKey(rand.Reader, 2048)
+	case "ES256":
+		return ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unsupported signing algorithm %q", algorithm)
+	}
+}
+
+// signingKeyCipher encrypts the private keys with AES-GCM under the SHA-256 hash of
+// the secret.
+func (a *App) signingKeyCipher() (cipher.AEAD, error) {
+	secret := sha256.Sum256([]byte(a.signing.Secret))
+	block, err := aes.NewCipher(secret[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealSigningKey encrypts the private key, the nonce is prepended to the result.
+func (a *App) sealSigningKey(der []byte) ([]byte, error) {
+	aead, err := a.signingKeyCipher()
+	if err != nil {
+		return nil, err
+	}
+	nonce := make([]byte, aead.NonceSize())
+	_, err = rand.Read(nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, der, nil), nil
+}
+
+// openSigningKey decrypts the private key of the stored key.
+func (a *App) openSigningKey(key *models.SigningKey) (crypto.Signer, error) {
+	aead, err := a.signingKeyCipher()
+	if err != nil {
+		return nil, err
+	}
+	if len(key.PrivateKey) < aead.NonceSize() {
+		return nil, errors.New("malformed private key")
+	}
+	nonce, sealed := key.PrivateKey[:aead.NonceSize()], key.PrivateKey[aead.NonceSize():]
+	der, err := aead.Open(nil, nonce, sealed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("unsupported private key %T", private)
+	}
+
+	return signer, nil
+}
+
+func (a *App) startSigningKeyRotation() {
+	if a.signing.Algorithm == "" {
+		return
+	}
+
+	a.signingKeysWG.Add(1)
+	go func() {
+		defer a.signingKeysWG.Done()
+
+		ticker := time.NewTicker(signingKeysCheckInterval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-a.signingKeysDone:
+				return
+			case <-ticker.C:
+				err := a.RotateSigningKeys()
+				if err != nil {
+					a.Log.Errorf("error rotating signing keys: %v", err)
+				}
+			}
+		}
+	}()
+}
+
+func (a *App) stopSigningKeyRotation() {
+	close(a.signingKeysDone)
+	a.signingKeysWG.Wait()
+}
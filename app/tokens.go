@@ -0,0 +1,134 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+const (
+	// RefreshTokenPrefix starts every refresh token.
+	RefreshTokenPrefix = "ethr_"
+
+	defaultRefreshTokenTTL = 7 * 24 * time.Hour
+)
+
+// WithRefreshTokenTTL sets how long refresh tokens are valid. Every refresh issues a
+// new token that is valid for as long.
+func WithRefreshTokenTTL(ttl time.Duration) Option {
+	return func(a *App) {
+		if ttl > 0 {
+			a.refreshTokenTTL = ttl
+		}
+	}
+}
+
+// IssueRefreshToken starts a new family of refresh tokens for a user that logged in.
+// The expired refresh tokens and revoked access tokens of all users are pruned on the
+// way.
+func (a *App) IssueRefreshToken(userID string) (string, error) {
+	_, err := a.db.PruneTokens(time.Now())
+	if err != nil {
+		a.Log.Errorf("error pruning expired tokens: %v", err)
+	}
+
+	return a.issueRefreshToken(userID, ksuid.New().String())
+}
+
+func (a *App) issueRefreshToken(userID, familyID string) (string, error) {
+	secret, err := newSecret(RefreshTokenPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	err = a.db.CreateRefreshToken(&models.RefreshToken{
+		UserID:    userID,
+		FamilyID:  familyID,
+		Hash:      hashSecret(secret),
+		ExpiresAt: time.Now().UTC().Add(a.refreshTokenTTL),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// RefreshSession exchanges a refresh token for a new one of the same family and
+// returns the user to issue a new access token for. Every refresh token can be
+// exchanged once, exchanging it again means it was stolen and revokes its family.
+func (a *App) RefreshSession(refreshToken string) (*models.User, string, error) {
+	if !strings.HasPrefix(refreshToken, RefreshTokenPrefix) {
+		return nil, "", fmt.Errorf("%w: malformed refresh token", ErrUnauthorized)
+	}
+	token, err := a.db.GetRefreshTokenByHash(hashSecret(refreshToken))
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unknown refresh token", ErrUnauthorized)
+	}
+	now := time.Now()
+	if token.UsedAt != nil {
+		a.Log.Warnf("refresh token of user %s was reused, revoking its family", token.UserID)
+		err = a.db.DeleteRefreshTokenFamily(token.FamilyID)
+		if err != nil {
+			a.Log.Errorf("error revoking refresh tokens: %v", err)
+		}
+		return nil, "", fmt.Errorf("%w: refresh token was used already", ErrUnauthorized)
+	}
+	if !now.Before(token.ExpiresAt) {
+		return nil, "", fmt.Errorf("%w: refresh token expired", ErrUnauthorized)
+	}
+	user, err := a.db.GetUserByID(token.UserID)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: unknown refresh token", ErrUnauthorized)
+	}
+	if user.Disabled {
+		return nil, "", fmt.Errorf("%w: user %s is disabled", ErrUnauthorized, user.Username)
+	}
+
+	err = a.db.UseRefreshToken(token.ID, now)
+	if err != nil {
+		return nil, "", fmt.Errorf("%w: refresh token was used already", ErrUnauthorized)
+	}
+	secret, err := a.issueRefreshToken(user.ID, token.FamilyID)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return user, secret, nil
+}
+
+// Logout revokes the access token of the user until it expires, tokens without an ID
+// cannot be revoked. The family of the refresh token is revoked too if it is set.
+func (a *App) Logout(userID, tokenID string, tokenExpiresAt time.Time, refreshToken string) error {
+	if userID == "" {
+		return ErrUnauthorized
+	}
+
+	if refreshToken != "" {
+		token, err := a.db.GetRefreshTokenByHash(hashSecret(refreshToken))
+		if err != nil || token.UserID != userID {
+			return fmt.Errorf("%w: unknown refresh token", ErrBadRequest)
+		}
+		err = a.db.DeleteRefreshTokenFamily(token.FamilyID)
+		if err != nil {
+			return err
+		}
+	}
+
+	if tokenID != "" {
+		err := a.db.RevokeToken(&models.RevokedToken{ID: tokenID, ExpiresAt: tokenExpiresAt.UTC()})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the access token with the ID was revoked.
+func (a *App) IsTokenRevoked(tokenID string) (bool, error) {
+	return a.db.IsTokenRevoked(tokenID)
+}
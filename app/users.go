@@ -0,0 +1,315 @@
+package app
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"golang.org/x/crypto/bcrypt"
+)
+
+const (
+	// MinPasswordLength is the shortest password accepted for new passwords.
+	MinPasswordLength = 8
+	// MaxPasswordLength is the longest password bcrypt can hash.
+	MaxPasswordLength = 72
+)
+
+var usernamePattern = regexp.MustCompile(`^[a-zA-Z0-9_.-]{3,32}$`)
+
+// commonPasswords are rejected for new passwords.
+var commonPasswords = []string{
+	"password", "password1", "password123", "passw0rd", "12345678", "123456789",
+	"1234567890", "11111111", "00000000", "abc12345", "abcd1234", "qwertyui",
+	"qwerty123", "qwertyuiop", "iloveyou", "letmein1", "welcome1", "admin123",
+	"changeme", "sunshine", "football", "baseball", "trustno1",
+}
+
+// WithRegistration allows anyone to create a user with Register.
+func WithRegistration(enabled bool) Option {
+	return func(a *App) {
+		a.registration = enabled
+	}
+}
+
+// GetUsers returns all users ordered by username.
+func (a *App) GetUsers() ([]*models.User, error) {
+	return a.db.GetUsers()
+}
+
+// HasAdmin reports whether an enabled admin exists. Stores start without users, the
+// first admin is created with the users command.
+func (a *App) HasAdmin() (bool, error) {
+	users, err := a.db.GetUsers()
+	if err != nil {
+		return false, err
+	}
+	for _, user := range users {
+		if user.Role == models.RoleAdmin && !user.Disabled {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// seedUsernames are the users older versions created in every store with their name
+// as password.
+var seedUsernames = []string{"alice", "bob", "carol", "dave"}
+
+// DisableSeedUsers disables the users older versions created whose password is still
+// their name, and returns their names. Their logins are known, alice was an admin.
+// They are enabled again after resetting their password.
+func (a *App) DisableSeedUsers() ([]string, error) {
+	var disabled []string
+	for _, username := range seedUsernames {
+		user, err := a.db.GetUserByUsername(username)
+		if err != nil || user.Disabled {
+			continue
+		}
+		if bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(username)) != nil {
+			continue
+		}
+
+		err = a.db.SetUserDisabled(user.ID, true)
+		if err != nil {
+			return disabled, fmt.Errorf("disabling user %s: %w", username, err)
+		}
+		disabled = append(disabled, username)
+	}
+
+	return disabled, nil
+}
+
+// CreateUser adds a user with a hash of the password. Usernames are unique.
+func (a *App) CreateUser(username, password string, role models.Role) (*models.User, error) {
+	username = strings.TrimSpace(username)
+	if !usernamePattern.MatchString(username) {
+		return nil, fmt.Errorf("%w: usernames have 3 to 32 letters, digits, '.', '_' or '-'", ErrBadRequest)
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: role must be one of %v", ErrBadRequest, models.Roles)
+	}
+	_, err := a.db.GetUserByUsername(username)
+	if err == nil {
+		return nil, fmt.Errorf("%w: user %s already exists", ErrBadRequest, username)
+	}
+
+	hash, err := hashPassword(username, password)
+	if err != nil {
+		return nil, err
+	}
+
+	user := &models.User{Username: username, Password: hash, Role: role}
+	err = a.db.CreateUser(user)
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// ResetPassword replaces the password of the user.
+func (a *App) ResetPassword(username, password string) error {
+	user, err := a.db.GetUserByUsername(username)
+	if err != nil {
+		return fmt.Errorf("user %s %w", username, ErrNotFound)
+	}
+
+	hash, err := hashPassword(user.Username, password)
+	if err != nil {
+		return err
+	}
+
+	return a.updatePassword(user.ID, hash)
+}
+
+// EnableUser lets a disabled user log in again.
+func (a *App) EnableUser(username string) error {
+	user, err := a.db.GetUserByUsername(username)
+	if err != nil {
+		return fmt.Errorf("user %s %w", username, ErrNotFound)
+	}
+
+	return a.db.SetUserDisabled(user.ID, false)
+}
+
+// Register creates a reader, if registration is enabled.
+func (a *App) Register(username, password string) (*models.User, error) {
+	if !a.registration {
+		return nil, fmt.Errorf("%w: registration is disabled", ErrForbidden)
+	}
+
+	return a.CreateUser(username, password, models.RoleReader)
+}
+
+// GetProfile returns the authenticated user.
+func (a *App) GetProfile(userID string) (*models.User, error) {
+	if userID == "" {
+		return nil, ErrUnauthorized
+	}
+	user, err := a.db.GetUserByID(userID)
+	if err != nil {
+		return nil, ErrUnauthorized
+	}
+
+	return user, nil
+}
+
+// ChangePassword replaces the password of the authenticated user, the current
+// password must be confirmed.
+func (a *App) ChangePassword(userID, currentPassword, newPassword string) error {
+	user, err := a.GetProfile(userID)
+	if err != nil {
+		return err
+	}
+	err = bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(currentPassword))
+	if err != nil {
+		return fmt.Errorf("%w: the current password is wrong", ErrForbidden)
+	}
+
+	hash, err := hashPassword(user.Username, newPassword)
+	if err != nil {
+		return err
+	}
+
+	return a.updatePassword(user.ID, hash)
+}
+
+// ListUsers returns all users ordered by username, only for admins.
+func (a *App) ListUsers(userID string) ([]*models.User, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+
+	return a.db.GetUsers()
+}
+
+// AddUser creates a user, only for admins.
+func (a *App) AddUser(userID, username, password string, role models.Role) (*models.User, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+
+	return a.CreateUser(username, password, role)
+}
+
+// SetUserRole replaces the role of the user with the given ID, only for admins.
+// Admins cannot change their own role.
+func (a *App) SetUserRole(userID, id string, role models.Role) (*models.User, error) {
+	user, err := a.managedUser(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if !role.Valid() {
+		return nil, fmt.Errorf("%w: role must be one of %v", ErrBadRequest, models.Roles)
+	}
+	if id == userID {
+		return nil, fmt.Errorf("%w: admins cannot change their own role", ErrBadRequest)
+	}
+
+	err = a.db.SetUserRole(id, role)
+	if err != nil {
+		return nil, err
+	}
+	user.Role = role
+
+	return user, nil
+}
+
+// SetUserDisabled disables or enables the user with the given ID, only for admins.
+// Admins cannot disable themselves.
+func (a *App) SetUserDisabled(userID, id string, disabled bool) (*models.User, error) {
+	user, err := a.managedUser(userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if disabled && id == userID {
+		return nil, fmt.Errorf("%w: admins cannot disable themselves", ErrBadRequest)
+	}
+
+	err = a.db.SetUserDisabled(id, disabled)
+	if err != nil {
+		return nil, err
+	}
+	user.Disabled = disabled
+
+	return user, nil
+}
+
+// DeleteUser deletes the user with the given ID with its history and private labels,
+// only for admins. Admins cannot delete themselves.
+func (a *App) DeleteUser(userID, id string) error {
+	_, err := a.managedUser(userID, id)
+	if err != nil {
+		return err
+	}
+	if id == userID {
+		return fmt.Errorf("%w: admins cannot delete themselves", ErrBadRequest)
+	}
+
+	return a.db.DeleteUser(id)
+}
+
+// ResetUserPassword replaces the password of the user with the given ID, only for
+// admins.
+func (a *App) ResetUserPassword(userID, id, password string) error {
+	user, err := a.managedUser(userID, id)
+	if err != nil {
+		return err
+	}
+
+	hash, err := hashPassword(user.Username, password)
+	if err != nil {
+		return err
+	}
+
+	return a.updatePassword(id, hash)
+}
+
+// managedUser returns the user with the given ID if userID is an admin.
+func (a *App) managedUser(userID, id string) (*models.User, error) {
+	if !a.isAdmin(userID) {
+		return nil, ErrForbidden
+	}
+	user, err := a.db.GetUserByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("user %s %w", id, ErrNotFound)
+	}
+
+	return user, nil
+}
+
+// updatePassword replaces the password hash of the user and revokes its refresh
+// tokens, sessions started with the old password end when their access tokens expire.
+func (a *App) updatePassword(userID, hash string) error {
+	err := a.db.UpdateUserPassword(userID, hash)
+	if err != nil {
+		return err
+	}
+
+	return a.db.DeleteUserRefreshTokens(userID)
+}
+
+// hashPassword checks the password against the password policy and hashes it.
+func hashPassword(username, password string) (string, error) {
+	switch {
+	case len(password) < MinPasswordLength:
+		return "", fmt.Errorf("%w: password shorter than %d characters", ErrBadRequest, MinPasswordLength)
+	case len(password) > MaxPasswordLength:
+		return "", fmt.Errorf("%w: password longer than %d bytes", ErrBadRequest, MaxPasswordLength)
+	case strings.Contains(strings.ToLower(password), strings.ToLower(username)):
+		return "", fmt.Errorf("%w: password contains the username", ErrBadRequest)
+	case slices.Contains(commonPasswords, strings.ToLower(password)):
+		return "", fmt.Errorf("%w: password is too common", ErrBadRequest)
+	}
+
+	hash, err := bcrypt.GenerateFromPassword([]byte(password), bcrypt.DefaultCost)
+	if err != nil {
+		return "", err
+	}
+
+	return string(hash), nil
+}
@@ -1,52 +1,254 @@
 package auth
 
 import (
+	"crypto"
+	"crypto/x509"
+	"errors"
+	"eth-fetcher/database/models"
 	"fmt"
 	"net/http"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/segmentio/ksuid"
 )
 
+// APIKeyHeader is the header API keys are sent in, they are accepted alongside tokens.
+const APIKeyHeader = "X-API-Key"
+
+// TokenHeader is the header tokens were sent in before the Authorization header was
+// supported, it is still accepted.
+const TokenHeader = "AUTH_TOKEN"
+
+var (
+	// ErrNoToken is returned for requests without a token or API key, they are
+	// anonymous.
+	ErrNoToken = errors.New("no token provided")
+	// ErrInvalidToken is returned for tokens that are malformed, expired, revoked or
+	// not signed by a trusted key.
+	ErrInvalidToken = errors.New("invalid token")
+	// ErrInvalidAPIKey is returned for unknown, expired and revoked API keys.
+	ErrInvalidAPIKey = errors.New("invalid API key")
+)
+
+// Identity is the authenticated user of a request.
+type Identity struct {
+	UserID string
+	Role   string
+	// Scopes limit requests authenticated with an API key, nil allows all scopes.
+	Scopes []models.Scope
+	// TokenID and TokenExpiresAt identify the access token of the request so that it
+	// can be revoked, both are empty for API keys.
+	TokenID        string
+	TokenExpiresAt time.Time
+}
+
+// APIKeyVerifier looks up the API keys and their users.
+type APIKeyVerifier interface {
+	VerifyAPIKey(key string) (*models.APIKey, *models.User, error)
+}
+
+// RevocationList knows the access tokens revoked before they expire.
+type RevocationList interface {
+	IsTokenRevoked(tokenID string) (bool, error)
+}
+
+// KeyStore provides the asymmetric keys signing and verifying the tokens.
+type KeyStore interface {
+	// CurrentSigningKey returns the key signing new tokens with its private key.
+	CurrentSigningKey() (*models.SigningKey, crypto.Signer, error)
+	// SigningKey returns the key with the ID, also after it was replaced until the
+	// tokens it signed expired.
+	SigningKey(id string) (*models.SigningKey, error)
+	// SigningKeys returns all keys that verify tokens.
+	SigningKeys() []*models.SigningKey
+}
+
+// Claims are the claims of the tokens, the role is embedded so that requests are
+// authorized without a lookup.
+type Claims struct {
+	jwt.RegisteredClaims
+	Role string `json:"role,omitempty"`
+}
+
 type JWTAuth struct {
-	secret   string
-	duration time.Duration
+	secret      string
+	duration    time.Duration
+	method      jwt.SigningMethod
+	signingKeys KeyStore
+	keys        APIKeyVerifier
+	revoked     RevocationList
 }
 
-func NewJWTAuth(secret string, duration time.Duration) *JWTAuth {
-	return &JWTAuth{
+// Option configures optional settings of the JWTAuth.
+type Option func(*JWTAuth)
+
+// WithSigningKeys signs the tokens with the current key of the store instead of the
+// secret. The algorithm, RS256 or ES256, is the only one accepted and the kid header
+// of the tokens names the key that verifies them.
+func WithSigningKeys(algorithm string, store KeyStore) Option {
+	return func(a *JWTAuth) {
+		a.method = jwt.GetSigningMethod(algorithm)
+		a.signingKeys = store
+	}
+}
+
+// WithRevocationList rejects the tokens on the list.
+func WithRevocationList(revoked RevocationList) Option {
+	return func(a *JWTAuth) {
+		a.revoked = revoked
+	}
+}
+
+// WithAPIKeys accepts the API keys of keys in the APIKeyHeader.
+func WithAPIKeys(keys APIKeyVerifier) Option {
+	return func(a *JWTAuth) {
+		a.keys = keys
+	}
+}
+
+func NewJWTAuth(secret string, duration time.Duration, opts ...Option) *JWTAuth {
+	a := &JWTAuth{
 		secret:   secret,
 		duration: duration,
+		method:   jwt.SigningMethodHS256,
 	}
+	for _, opt := range opts {
+		opt(a)
+	}
+
+	return a
 }
 
-func (a *JWTAuth) GenerateToken(subject string) (string, error) {
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
-		Subject:   subject,
-		ExpiresAt: &jwt.NumericDate{Time: time.Now().Add(a.duration)},
+// GenerateToken issues an access token with a unique ID, the jti claim, by which it
+// can be revoked.
+func (a *JWTAuth) GenerateToken(subject, role string) (string, error) {
+	now := time.Now()
+	if a.method == nil {
+		return "", errors.New("unsupported signing algorithm")
+	}
+	token := jwt.NewWithClaims(a.method, Claims{
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        ksuid.New().String(),
+			Subject:   subject,
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(a.duration)),
+		},
+		Role: role,
 	})
+	if a.signingKeys == nil {
+		return token.SignedString([]byte(a.secret))
+	}
+
+	key, private, err := a.signingKeys.CurrentSigningKey()
+	if err != nil {
+		return "", err
+	}
+	token.Header["kid"] = key.ID
 
-	return token.SignedString([]byte(a.secret))
+	return token.SignedString(private)
 }
 
-func (a *JWTAuth) AuthenticateRequest(r *http.Request) (string, error) {
-	tokenStr := r.Header.Get("AUTH_TOKEN")
-	if tokenStr == "" {
-		return "", fmt.Errorf("no token provided")
+// AuthenticateRequest authenticates the request with the API key in the APIKeyHeader
+// if there is one, otherwise with the token. Requests with neither fail with
+// ErrNoToken, invalid credentials with ErrInvalidToken or ErrInvalidAPIKey.
+func (a *JWTAuth) AuthenticateRequest(r *http.Request) (Identity, error) {
+	if key := r.Header.Get(APIKeyHeader); key != "" && a.keys != nil {
+		return a.authenticateAPIKey(key)
 	}
 
-	token, err := jwt.Parse(tokenStr, func(token *jwt.Token) (interface{}, error) {
+	tokenStr, err := Token(r)
+	if err != nil {
+		return Identity{}, err
+	}
+
+	if a.method == nil {
+		return Identity{}, errors.New("unsupported signing algorithm")
+	}
+	claims := &Claims{}
+	parser := jwt.NewParser(jwt.WithValidMethods([]string{a.method.Alg()}))
+	token, err := parser.ParseWithClaims(tokenStr, claims, a.verificationKey)
+
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if !token.Valid || claims.Subject == "" {
+		return Identity{}, ErrInvalidToken
+	}
+
+	identity := Identity{UserID: claims.Subject, Role: claims.Role, TokenID: claims.ID}
+	if claims.ExpiresAt != nil {
+		identity.TokenExpiresAt = claims.ExpiresAt.Time
+	}
+	if claims.ID != "" && a.revoked != nil {
+		revoked, err := a.revoked.IsTokenRevoked(claims.ID)
+		if err != nil {
+			return Identity{}, fmt.Errorf("checking the revocation list: %w", err)
+		}
+		if revoked {
+			return Identity{}, fmt.Errorf("%w: token revoked", ErrInvalidToken)
+		}
+	}
+
+	return identity, nil
+}
+
+// Token returns the bearer token of the Authorization header, or the token of the
+// TokenHeader. Authorization headers of other schemes are ignored.
+func Token(r *http.Request) (string, error) {
+	scheme, token, _ := strings.Cut(r.Header.Get("Authorization"), " ")
+	if strings.EqualFold(scheme, "Bearer") {
+		token = strings.TrimSpace(token)
+		if token == "" {
+			return "", fmt.Errorf("%w: empty bearer token", ErrInvalidToken)
+		}
+		return token, nil
+	}
+
+	token = r.Header.Get(TokenHeader)
+	if token == "" {
+		return "", ErrNoToken
+	}
+
+	return token, nil
+}
+
+// verificationKey returns the key verifying the token, the secret or the public key
+// named by its kid header. Parsing already rejected other algorithms than the one
+// tokens are signed with.
+func (a *JWTAuth) verificationKey(token *jwt.Token) (interface{}, error) {
+	if a.signingKeys == nil {
 		return []byte(a.secret), nil
-	})
+	}
 
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return nil, errors.New("no kid header")
+	}
+	key, err := a.signingKeys.SigningKey(kid)
 	if err != nil {
-		return "", fmt.Errorf("invalid token: %w", err)
+		return nil, err
 	}
-	if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-		return claims["sub"].(string), nil
+	if key.Algorithm != token.Method.Alg() {
+		return nil, fmt.Errorf("key %s is not a %s key", kid, token.Method.Alg())
+	}
+
+	return x509.ParsePKIXPublicKey(key.PublicKey)
+}
+
+func (a *JWTAuth) authenticateAPIKey(secret string) (Identity, error) {
+	key, user, err := a.keys.VerifyAPIKey(secret)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrInvalidAPIKey, err)
+	}
+
+	identity := Identity{UserID: user.ID, Role: string(user.Role)}
+	if len(key.Scopes) > 0 {
+		identity.Scopes = key.Scopes
 	}
 
-	return "", fmt.Errorf("invalid token")
+	return identity, nil
 }
 
 func (a JWTAuth) Type() string {
@@ -1,14 +1,27 @@
 package auth_test
 
 import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
 	"net/http"
 	"testing"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"eth-fetcher/auth"
+	"eth-fetcher/auth/oidctest"
+	"eth-fetcher/database/models"
 )
 
 func TestJWTAuth_AuthenticateRequest_ValidToken(t *testing.T) {
@@ -31,7 +44,35 @@ func TestJWTAuth_AuthenticateRequest_ValidToken(t *testing.T) {
 
 	// Assert that the authentication was successful
 	assert.NoError(t, err)
-	assert.Equal(t, "user123", sub)
+	assert.Equal(t, auth.Identity{UserID: "user123"}, sub)
+}
+
+func TestJWTAuth_GenerateToken(t *testing.T) {
+	authenticator := auth.NewJWTAuth("my-secret", time.Hour)
+
+	tokenStr, err := authenticator.GenerateToken("user123", "analyst")
+	assert.NoError(t, err)
+
+	// The role is carried in the token
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("AUTH_TOKEN", tokenStr)
+	identity, err := authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", identity.UserID)
+	assert.Equal(t, "analyst", identity.Role)
+	// Every token has an ID to revoke it by
+	assert.NotEmpty(t, identity.TokenID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), identity.TokenExpiresAt, time.Minute)
+
+	other, _ := authenticator.GenerateToken("user123", "analyst")
+	req.Header.Set("AUTH_TOKEN", other)
+	identity2, err := authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.NotEqual(t, identity.TokenID, identity2.TokenID)
+
+	// Tokens signed with another secret are rejected
+	_, err = auth.NewJWTAuth("other-secret", time.Hour).AuthenticateRequest(req)
+	assert.Error(t, err)
 }
 
 func TestJWTAuth_AuthenticateRequest_NoTokenProvided(t *testing.T) {
@@ -48,9 +89,44 @@ func TestJWTAuth_AuthenticateRequest_NoTokenProvided(t *testing.T) {
 	// Assert that an error is returned
 	assert.Error(t, err)
 	assert.EqualError(t, err, "no token provided")
+	assert.ErrorIs(t, err, auth.ErrNoToken)
 	assert.Empty(t, sub)
 }
 
+func TestJWTAuth_AuthenticateRequest_BearerToken(t *testing.T) {
+	authenticator := auth.NewJWTAuth("my-secret", time.Hour)
+	tokenStr, err := authenticator.GenerateToken("user123", "reader")
+	require.NoError(t, err)
+
+	for _, header := range []string{"Bearer " + tokenStr, "bearer " + tokenStr, "Bearer  " + tokenStr + " "} {
+		req, _ := http.NewRequest("GET", "/", nil)
+		req.Header.Set("Authorization", header)
+		identity, err := authenticator.AuthenticateRequest(req)
+		assert.NoError(t, err)
+		assert.Equal(t, "user123", identity.UserID)
+	}
+
+	// The Authorization header takes precedence over the AUTH_TOKEN header
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("Authorization", "Bearer invalid-token")
+	req.Header.Set("AUTH_TOKEN", tokenStr)
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+
+	// Other schemes are ignored
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	identity, err := authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "user123", identity.UserID)
+	req.Header.Del("AUTH_TOKEN")
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrNoToken)
+
+	req.Header.Set("Authorization", "Bearer ")
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
 func TestJWTAuth_AuthenticateRequest_InvalidToken(t *testing.T) {
 	// Create a JWTAuth instance with a secret
 	secret := "my-secret"
@@ -67,7 +143,7 @@ func TestJWTAuth_AuthenticateRequest_InvalidToken(t *testing.T) {
 	sub, err := authenticator.AuthenticateRequest(req)
 
 	// Assert that an error is returned
-	assert.Error(t, err)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
 	assert.Empty(t, sub)
 }
 
@@ -131,3 +207,457 @@ func TestJWTAuth_AuthenticateRequest_ErrorHandling(t *testing.T) {
 	assert.Error(t, err)
 	assert.Empty(t, sub)
 }
+
+// keys is an auth.APIKeyVerifier with a fixed set of keys.
+type keys map[string]*models.APIKey
+
+func (k keys) VerifyAPIKey(secret string) (*models.APIKey, *models.User, error) {
+	key, ok := k[secret]
+	if !ok {
+		return nil, nil, errors.New("unknown API key")
+	}
+	return key, &models.User{ID: key.UserID, Role: models.RoleAnalyst}, nil
+}
+
+func TestJWTAuth_AuthenticateRequest_APIKey(t *testing.T) {
+	authenticator := auth.NewJWTAuth("my-secret", time.Hour, auth.WithAPIKeys(keys{
+		"ethf_scoped": {UserID: "user123", Scopes: []models.Scope{models.ScopeTransactions}},
+		"ethf_all":    {UserID: "user456", Scopes: []models.Scope{}},
+	}))
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set(auth.APIKeyHeader, "ethf_scoped")
+	identity, err := authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Identity{UserID: "user123", Role: "analyst", Scopes: []models.Scope{models.ScopeTransactions}}, identity)
+
+	// Keys without scopes are not limited
+	req.Header.Set(auth.APIKeyHeader, "ethf_all")
+	identity, err = authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Identity{UserID: "user456", Role: "analyst"}, identity)
+
+	// An invalid key is not ignored in favour of a token
+	token, _ := authenticator.GenerateToken("user789", "reader")
+	req.Header.Set("AUTH_TOKEN", token)
+	req.Header.Set(auth.APIKeyHeader, "ethf_unknown")
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrInvalidAPIKey)
+
+	// Without a verifier the header is ignored
+	identity, err = auth.NewJWTAuth("my-secret", time.Hour).AuthenticateRequest(req)
+	assert.NoError(t, err)
+	assert.Equal(t, "user789", identity.UserID)
+}
+
+// revocationList is a RevocationList of the revoked token IDs.
+type revocationList map[string]bool
+
+func (l revocationList) IsTokenRevoked(tokenID string) (bool, error) {
+	if tokenID == "broken" {
+		return false, errors.New("store unavailable")
+	}
+	return l[tokenID], nil
+}
+
+func TestJWTAuth_AuthenticateRequest_Revoked(t *testing.T) {
+	revoked := revocationList{}
+	authenticator := auth.NewJWTAuth("my-secret", time.Hour, auth.WithRevocationList(revoked))
+
+	token, _ := authenticator.GenerateToken("user123", "reader")
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("AUTH_TOKEN", token)
+	identity, err := authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+
+	revoked[identity.TokenID] = true
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+	assert.ErrorContains(t, err, "token revoked")
+
+	// Tokens are rejected when the list cannot be checked
+	token, _ = jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.RegisteredClaims{
+		ID:      "broken",
+		Subject: "user123",
+	}).SignedString([]byte("my-secret"))
+	req.Header.Set("AUTH_TOKEN", token)
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorContains(t, err, "revocation list")
+}
+
+// keyStore is a KeyStore of generated keys, the last one signs new tokens.
+type keyStore []*signingKey
+
+type signingKey struct {
+	model   *models.SigningKey
+	private crypto.Signer
+}
+
+func newKeyStore(t *testing.T, algorithms ...string) keyStore {
+	var store keyStore
+	for i, algorithm := range algorithms {
+		var private crypto.Signer
+		var err error
+		switch algorithm {
+		case "RS256":
+			private, err = rsa.GenerateKey(rand.Reader, 2048)
+		case "ES256":
+			private, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		}
+		require.NoError(t, err)
+		public, err := x509.MarshalPKIXPublicKey(private.Public())
+		require.NoError(t, err)
+		store = append(store, &signingKey{
+			model:   &models.SigningKey{ID: fmt.Sprintf("key%d", i+1), Algorithm: algorithm, PublicKey: public},
+			private: private,
+		})
+	}
+	return store
+}
+
+func (s keyStore) CurrentSigningKey() (*models.SigningKey, crypto.Signer, error) {
+	key := s[len(s)-1]
+	return key.model, key.private, nil
+}
+
+func (s keyStore) SigningKey(id string) (*models.SigningKey, error) {
+	for _, key := range s {
+		if key.model.ID == id {
+			return key.model, nil
+		}
+	}
+	return nil, errors.New("unknown signing key")
+}
+
+func (s keyStore) SigningKeys() []*models.SigningKey {
+	keys := make([]*models.SigningKey, len(s))
+	for i, key := range s {
+		keys[i] = key.model
+	}
+	return keys
+}
+
+func TestJWTAuth_SigningKeys(t *testing.T) {
+	for _, algorithm := range []string{"RS256", "ES256"} {
+		t.Run(algorithm, func(t *testing.T) {
+			store := newKeyStore(t, algorithm, algorithm)
+			authenticator := auth.NewJWTAuth("my-secret", time.Hour, auth.WithSigningKeys(algorithm, store))
+
+			tokenStr, err := authenticator.GenerateToken("user123", "analyst")
+			require.NoError(t, err)
+			token, _, err := jwt.NewParser().ParseUnverified(tokenStr, &auth.Claims{})
+			require.NoError(t, err)
+			assert.Equal(t, algorithm, token.Header["alg"])
+			assert.Equal(t, "key2", token.Header["kid"])
+
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("AUTH_TOKEN", tokenStr)
+			identity, err := authenticator.AuthenticateRequest(req)
+			assert.NoError(t, err)
+			assert.Equal(t, "user123", identity.UserID)
+
+			// Tokens of replaced keys stay valid
+			previous := auth.NewJWTAuth("my-secret", time.Hour, auth.WithSigningKeys(algorithm, store[:1]))
+			tokenStr, err = previous.GenerateToken("user456", "reader")
+			require.NoError(t, err)
+			req.Header.Set("AUTH_TOKEN", tokenStr)
+			identity, err = authenticator.AuthenticateRequest(req)
+			assert.NoError(t, err)
+			assert.Equal(t, "user456", identity.UserID)
+
+			// Tokens of unknown keys are rejected
+			other := auth.NewJWTAuth("my-secret", time.Hour, auth.WithSigningKeys(algorithm, newKeyStore(t, algorithm, algorithm, algorithm)))
+			tokenStr, err = other.GenerateToken("user123", "admin")
+			require.NoError(t, err)
+			req.Header.Set("AUTH_TOKEN", tokenStr)
+			_, err = authenticator.AuthenticateRequest(req)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestJWTAuth_SigningKeys_StrictAlgorithm(t *testing.T) {
+	store := newKeyStore(t, "RS256")
+	authenticator := auth.NewJWTAuth("my-secret", time.Hour, auth.WithSigningKeys("RS256", store))
+	claims := jwt.RegisteredClaims{Subject: "user123", ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour))}
+	req, _ := http.NewRequest("GET", "/", nil)
+
+	tests := map[string]func() (string, error){
+		// HS256 signed with the public key, which verifiers must not accept as a secret
+		"public key as secret": func() (string, error) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			token.Header["kid"] = "key1"
+			return token.SignedString(store[0].model.PublicKey)
+		},
+		"secret": func() (string, error) {
+			token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
+			token.Header["kid"] = "key1"
+			return token.SignedString([]byte("my-secret"))
+		},
+		"none": func() (string, error) {
+			token := jwt.NewWithClaims(jwt.SigningMethodNone, claims)
+			token.Header["kid"] = "key1"
+			return token.SignedString(jwt.UnsafeAllowNoneSignatureType)
+		},
+		"other algorithm": func() (string, error) {
+			token := jwt.NewWithClaims(jwt.SigningMethodRS512, claims)
+			token.Header["kid"] = "key1"
+			return token.SignedString(store[0].private)
+		},
+		"no kid": func() (string, error) {
+			return jwt.NewWithClaims(jwt.SigningMethodRS256, claims).SignedString(store[0].private)
+		},
+	}
+	for name, sign := range tests {
+		t.Run(name, func(t *testing.T) {
+			tokenStr, err := sign()
+			require.NoError(t, err)
+			req.Header.Set("AUTH_TOKEN", tokenStr)
+			_, err = authenticator.AuthenticateRequest(req)
+			assert.Error(t, err)
+		})
+	}
+
+	// Secret based authenticators reject asymmetric tokens too
+	tokenStr, err := authenticator.GenerateToken("user123", "reader")
+	require.NoError(t, err)
+	req.Header.Set("AUTH_TOKEN", tokenStr)
+	_, err = auth.NewJWTAuth("my-secret", time.Hour).AuthenticateRequest(req)
+	assert.Error(t, err)
+}
+
+func TestJWTAuth_JWKS(t *testing.T) {
+	store := newKeyStore(t, "RS256", "ES256")
+	jwks, err := auth.NewJWTAuth("my-secret", time.Hour, auth.WithSigningKeys("ES256", store)).JWKS()
+	require.NoError(t, err)
+	require.Len(t, jwks.Keys, 2)
+
+	rsaKey := store[0].private.Public().(*rsa.PublicKey)
+	assert.Equal(t, auth.JWK{
+		Kty: "RSA", Use: "sig", Alg: "RS256", Kid: "key1",
+		N: base64.RawURLEncoding.EncodeToString(rsaKey.N.Bytes()),
+		E: "AQAB",
+	}, jwks.Keys[0])
+
+	ecKey := store[1].private.Public().(*ecdsa.PublicKey)
+	x, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[1].X)
+	y, _ := base64.RawURLEncoding.DecodeString(jwks.Keys[1].Y)
+	assert.Equal(t, "EC", jwks.Keys[1].Kty)
+	assert.Equal(t, "P-256", jwks.Keys[1].Crv)
+	assert.Equal(t, "ES256", jwks.Keys[1].Alg)
+	assert.Equal(t, "key2", jwks.Keys[1].Kid)
+	assert.Len(t, x, 32)
+	assert.Len(t, y, 32)
+	assert.Zero(t, ecKey.X.Cmp(new(big.Int).SetBytes(x)))
+	assert.Zero(t, ecKey.Y.Cmp(new(big.Int).SetBytes(y)))
+
+	// The secret is not published
+	jwks, err = auth.NewJWTAuth("my-secret", time.Hour).JWKS()
+	assert.NoError(t, err)
+	assert.Empty(t, jwks.Keys)
+}
+
+// externalUsers provisions users named after the subject in memory.
+type externalUsers map[string]*models.User
+
+func (u externalUsers) ProvisionExternalUser(issuer, subject, name string, role models.Role) (*models.User, error) {
+	user, ok := u[subject]
+	if !ok {
+		user = &models.User{ID: "id-" + subject, Username: name}
+		u[subject] = user
+	}
+	if user.Disabled {
+		return nil, errors.New("user disabled")
+	}
+	user.Role = role
+
+	return user, nil
+}
+
+func newOIDCAuth(t *testing.T, issuer *oidctest.Issuer, config auth.OIDCConfig) (*auth.OIDCAuth, externalUsers) {
+	t.Helper()
+
+	config.Issuer = issuer.URL
+	config.Audience = oidctest.Audience
+	users := externalUsers{}
+	authenticator, err := auth.NewOIDCAuth(config, auth.NewJWTAuth("my-secret", time.Hour), users)
+	require.NoError(t, err)
+
+	return authenticator, users
+}
+
+func TestOIDCAuth_AuthenticateRequest(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+	authenticator, users := newOIDCAuth(t, issuer, auth.OIDCConfig{})
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{
+		"sub":                "alice-sub",
+		"preferred_username": "alice",
+		"jti":                "token1",
+	}))
+	identity, err := authenticator.AuthenticateRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "id-alice-sub", identity.UserID)
+	assert.Equal(t, "reader", identity.Role)
+	assert.Equal(t, "token1", identity.TokenID)
+	assert.WithinDuration(t, time.Now().Add(time.Hour), identity.TokenExpiresAt, time.Minute)
+	assert.Equal(t, "alice", users["alice-sub"].Username)
+
+	// The name falls back to the email and the subject
+	req.Header.Del("AUTH_TOKEN")
+	req.Header.Set("Authorization", "Bearer "+issuer.Token(t, jwt.MapClaims{"sub": "bob-sub", "email": "bob@example.com"}))
+	_, err = authenticator.AuthenticateRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "bob@example.com", users["bob-sub"].Username)
+
+	// Local tokens still work
+	local, err := authenticator.GenerateToken("user123", "analyst")
+	require.NoError(t, err)
+	req.Header.Set("Authorization", "Bearer "+local)
+	identity, err = authenticator.AuthenticateRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, "user123", identity.UserID)
+	assert.Equal(t, "analyst", identity.Role)
+
+	// Disabled users are rejected
+	users["alice-sub"].Disabled = true
+	req.Header.Set("Authorization", "Bearer "+issuer.Token(t, jwt.MapClaims{"sub": "alice-sub"}))
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorIs(t, err, auth.ErrInvalidToken)
+}
+
+func TestOIDCAuth_AuthenticateRequest_InvalidTokens(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+	authenticator, users := newOIDCAuth(t, issuer, auth.OIDCConfig{})
+
+	unsigned := jwt.NewWithClaims(jwt.SigningMethodNone, jwt.MapClaims{
+		"iss": issuer.URL, "aud": oidctest.Audience, "sub": "mallory", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	none, _ := unsigned.SignedString(jwt.UnsafeAllowNoneSignatureType)
+	// HS256 signed with the local secret but claiming the provider as issuer
+	hmac := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"iss": issuer.URL, "aud": oidctest.Audience, "sub": "mallory", "exp": time.Now().Add(time.Hour).Unix(),
+	})
+	hs256, _ := hmac.SignedString([]byte("my-secret"))
+	other := oidctest.NewIssuer(t)
+	forged := other.Token(t, jwt.MapClaims{"iss": issuer.URL, "sub": "mallory"})
+
+	tests := map[string]string{
+		"wrong audience":  issuer.Token(t, jwt.MapClaims{"aud": "other-app"}),
+		"no audience":     issuer.Token(t, jwt.MapClaims{"aud": nil}),
+		"expired":         issuer.Token(t, jwt.MapClaims{"exp": time.Now().Add(-time.Minute).Unix()}),
+		"no expiry":       issuer.Token(t, jwt.MapClaims{"exp": nil}),
+		"not yet valid":   issuer.Token(t, jwt.MapClaims{"nbf": time.Now().Add(time.Hour).Unix()}),
+		"no subject":      issuer.Token(t, jwt.MapClaims{"sub": nil}),
+		"alg none":        none,
+		"alg HS256":       hs256,
+		"other issuer":    other.Token(t, jwt.MapClaims{}),
+		"forged issuer":   forged,
+		"malformed token": "not-a-token",
+	}
+	for name, token := range tests {
+		t.Run(name, func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("AUTH_TOKEN", token)
+			_, err := authenticator.AuthenticateRequest(req)
+			assert.Error(t, err)
+		})
+	}
+	assert.NotContains(t, users, "mallory")
+}
+
+func TestOIDCAuth_AuthenticateRequest_RotatedKey(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+	authenticator, _ := newOIDCAuth(t, issuer, auth.OIDCConfig{KeysRefreshInterval: time.Nanosecond})
+	assert.Equal(t, 1, issuer.JWKSRequests())
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{}))
+	_, err := authenticator.AuthenticateRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, 1, issuer.JWKSRequests())
+
+	// Tokens signed with a new key fetch the JWKS again
+	issuer.RotateKey(t)
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{}))
+	_, err = authenticator.AuthenticateRequest(req)
+	require.NoError(t, err)
+	assert.Equal(t, 2, issuer.JWKSRequests())
+
+	// Unknown keys are fetched at most once per refresh interval
+	throttled, _ := newOIDCAuth(t, issuer, auth.OIDCConfig{KeysRefreshInterval: time.Hour})
+	issuer.RotateKey(t)
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{}))
+	_, err = throttled.AuthenticateRequest(req)
+	assert.Error(t, err)
+	assert.Equal(t, 3, issuer.JWKSRequests())
+}
+
+func TestOIDCAuth_AuthenticateRequest_Groups(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+	authenticator, _ := newOIDCAuth(t, issuer, auth.OIDCConfig{
+		GroupsClaim: "roles",
+		RoleGroups: map[models.Role][]string{
+			models.RoleReader:  {"staff"},
+			models.RoleAnalyst: {"analysts", "research"},
+			models.RoleAdmin:   {"ops"},
+		},
+	})
+
+	tests := []struct {
+		groups any
+		role   string
+	}{
+		{groups: []string{"staff"}, role: "reader"},
+		{groups: []string{"staff", "research"}, role: "analyst"},
+		{groups: []string{"analysts", "ops"}, role: "admin"},
+		{groups: "ops", role: "admin"},
+		// Users in none of the groups are rejected when reader groups are set
+		{groups: []string{"contractors"}},
+		{groups: nil},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprint(tt.groups), func(t *testing.T) {
+			req, _ := http.NewRequest("GET", "/", nil)
+			req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{"roles": tt.groups}))
+			identity, err := authenticator.AuthenticateRequest(req)
+			if tt.role == "" {
+				assert.Error(t, err)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, tt.role, identity.Role)
+		})
+	}
+}
+
+func TestOIDCAuth_AuthenticateRequest_Revoked(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+	config := auth.OIDCConfig{Issuer: issuer.URL, Audience: oidctest.Audience}
+	local := auth.NewJWTAuth("my-secret", time.Hour, auth.WithRevocationList(revocationList{"token1": true}))
+	authenticator, err := auth.NewOIDCAuth(config, local, externalUsers{})
+	require.NoError(t, err)
+
+	req, _ := http.NewRequest("GET", "/", nil)
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{"jti": "token1"}))
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.ErrorContains(t, err, "revoked")
+
+	req.Header.Set("AUTH_TOKEN", issuer.Token(t, jwt.MapClaims{"jti": "token2"}))
+	_, err = authenticator.AuthenticateRequest(req)
+	assert.NoError(t, err)
+}
+
+func TestNewOIDCAuth_Discovery(t *testing.T) {
+	issuer := oidctest.NewIssuer(t)
+
+	// The discovery document must be for the configured issuer
+	_, err := auth.NewOIDCAuth(auth.OIDCConfig{Issuer: issuer.URL + "/"}, auth.NewJWTAuth("my-secret", time.Hour), externalUsers{})
+	assert.Error(t, err)
+
+	unreachable := oidctest.NewIssuer(t)
+	unreachable.Close()
+	_, err = auth.NewOIDCAuth(auth.OIDCConfig{Issuer: unreachable.URL}, auth.NewJWTAuth("my-secret", time.Hour), externalUsers{})
+	assert.Error(t, err)
+}
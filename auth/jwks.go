@@ -0,0 +1,140 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"fmt"
+	"math/big"
+)
+
+// JWK is a public key in the JSON Web Key format of RFC 7517.
+type JWK struct {
+	Kty string `json:"kty"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+	// N and E are the modulus and exponent of RSA keys.
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// Crv, X and Y are the curve and coordinates of EC keys.
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JWKS is the set of keys that verify the tokens.
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS returns the public keys verifying the tokens, so that other services can verify
+// them. It is empty when the tokens are signed with the secret.
+func (a *JWTAuth) JWKS() (JWKS, error) {
+	jwks := JWKS{Keys: []JWK{}}
+	if a.signingKeys == nil {
+		return jwks, nil
+	}
+
+	for _, key := range a.signingKeys.SigningKeys() {
+		public, err := x509.ParsePKIXPublicKey(key.PublicKey)
+		if err != nil {
+			return JWKS{}, fmt.Errorf("parsing signing key %s: %w", key.ID, err)
+		}
+		jwk, err := NewJWK(key.ID, key.Algorithm, public)
+		if err != nil {
+			return JWKS{}, err
+		}
+		jwks.Keys = append(jwks.Keys, jwk)
+	}
+
+	return jwks, nil
+}
+
+// NewJWK encodes the RSA or EC public key as a JWK verifying signatures.
+func NewJWK(kid, algorithm string, public crypto.PublicKey) (JWK, error) {
+	jwk := JWK{Use: "sig", Alg: algorithm, Kid: kid}
+	switch public := public.(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBase64URL(public.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(public.E)).Bytes())
+	case *ecdsa.PublicKey:
+		// The uncompressed point is 0x04 followed by the fixed size coordinates.
+		point, err := public.ECDH()
+		if err != nil {
+			return JWK{}, fmt.Errorf("encoding key %s: %w", kid, err)
+		}
+		coordinates := point.Bytes()[1:]
+		size := len(coordinates) / 2
+		jwk.Kty = "EC"
+		jwk.Crv = public.Curve.Params().Name
+		jwk.X = encodeBase64URL(coordinates[:size])
+		jwk.Y = encodeBase64URL(coordinates[size:])
+	default:
+		return JWK{}, fmt.Errorf("unsupported key %s of type %T", kid, public)
+	}
+
+	return jwk, nil
+}
+
+// PublicKey decodes the RSA or EC public key of the JWK.
+func (jwk JWK) PublicKey() (crypto.PublicKey, error) {
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(jwk.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := decodeBase64URL(jwk.E)
+		if err != nil {
+			return nil, err
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("malformed RSA key %s", jwk.Kid)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}, nil
+	case "EC":
+		var curve ecdh.Curve
+		var params elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve, params = ecdh.P256(), elliptic.P256()
+		case "P-384":
+			curve, params = ecdh.P384(), elliptic.P384()
+		case "P-521":
+			curve, params = ecdh.P521(), elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q of key %s", jwk.Crv, jwk.Kid)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := decodeBase64URL(jwk.Y)
+		if err != nil {
+			return nil, err
+		}
+		// Decoding the uncompressed point checks that it is on the curve.
+		_, err = curve.NewPublicKey(append(append([]byte{4}, x...), y...))
+		if err != nil {
+			return nil, fmt.Errorf("malformed EC key %s: %w", jwk.Kid, err)
+		}
+		return &ecdsa.PublicKey{Curve: params, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	default:
+		return nil, fmt.Errorf("unsupported key type %q of key %s", jwk.Kty, jwk.Kid)
+	}
+}
+
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
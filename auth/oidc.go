@@ -0,0 +1,322 @@
+package auth
+
+import (
+	"encoding/json"
+	"eth-fetcher/database/models"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// defaultGroupsClaim is the claim listing the groups of a user.
+	defaultGroupsClaim = "groups"
+	// defaultKeysRefreshInterval is how often the JWKS of the provider is fetched at
+	// most when a token names an unknown key.
+	defaultKeysRefreshInterval = time.Minute
+	// oidcTimeout bounds the requests to the provider.
+	oidcTimeout = 10 * time.Second
+)
+
+// oidcAlgorithms are the algorithms accepted in the tokens of the provider.
+var oidcAlgorithms = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512"}
+
+// OIDCConfig configures the OpenID Connect provider whose tokens are accepted.
+type OIDCConfig struct {
+	// Issuer is the URL of the provider, the discovery document is served under it at
+	// /.well-known/openid-configuration.
+	Issuer string
+	// Audience is the client ID or API the tokens must be issued for.
+	Audience string
+	// GroupsClaim is the claim listing the groups of the user, groups by default.
+	GroupsClaim string
+	// RoleGroups grant a role to the members of any of the groups, the highest role
+	// wins. Users in none of the groups are readers, unless reader groups are set,
+	// then they are rejected.
+	RoleGroups map[models.Role][]string
+	// KeysRefreshInterval is how often the JWKS is fetched at most when a token names
+	// an unknown key, one minute by default.
+	KeysRefreshInterval time.Duration
+	// Client makes the requests to the provider, a client with a timeout by default.
+	Client *http.Client
+}
+
+// ExternalUsers provisions the local users of the identity provider.
+type ExternalUsers interface {
+	ProvisionExternalUser(issuer, subject, name string, role models.Role) (*models.User, error)
+}
+
+// OIDCAuth accepts the ID and access tokens of an OpenID Connect provider besides the
+// tokens and API keys of the embedded JWTAuth, which still issues the tokens. The
+// users of the provider are provisioned as local users on their first request.
+type OIDCAuth struct {
+	*JWTAuth
+
+	config  OIDCConfig
+	users   ExternalUsers
+	jwksURI string
+
+	// fetchMu serializes the fetches of the JWKS.
+	fetchMu   sync.Mutex
+	keysMu    sync.RWMutex
+	keys      map[string]JWK
+	fetchedAt time.Time
+}
+
+// discovery is the part of the discovery document of the provider that is used.
+type discovery struct {
+	Issuer  string `json:"issuer"`
+	JWKSURI string `json:"jwks_uri"`
+}
+
+// NewOIDCAuth discovers the provider of the config and fetches its keys.
+func NewOIDCAuth(config OIDCConfig, local *JWTAuth, users ExternalUsers) (*OIDCAuth, error) {
+	if config.GroupsClaim == "" {
+		config.GroupsClaim = defaultGroupsClaim
+	}
+	if config.KeysRefreshInterval <= 0 {
+		config.KeysRefreshInterval = defaultKeysRefreshInterval
+	}
+	if config.Client == nil {
+		config.Client = &http.Client{Timeout: oidcTimeout}
+	}
+	a := &OIDCAuth{JWTAuth: local, config: config, users: users}
+
+	var doc discovery
+	err := a.get(strings.TrimSuffix(config.Issuer, "/")+"/.well-known/openid-configuration", &doc)
+	if err != nil {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: %w", config.Issuer, err)
+	}
+	if doc.Issuer != config.Issuer {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: the document is for issuer %s", config.Issuer, doc.Issuer)
+	}
+	if doc.JWKSURI == "" {
+		return nil, fmt.Errorf("discovering OIDC issuer %s: no jwks_uri", config.Issuer)
+	}
+	a.jwksURI = doc.JWKSURI
+
+	err = a.fetchKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	return a, nil
+}
+
+// AuthenticateRequest authenticates tokens of the provider, recognized by their iss
+// claim, and leaves API keys and other tokens to the JWTAuth.
+func (a *OIDCAuth) AuthenticateRequest(r *http.Request) (Identity, error) {
+	tokenStr, err := Token(r)
+	if err != nil || r.Header.Get(APIKeyHeader) != "" || !a.issuedByProvider(tokenStr) {
+		return a.JWTAuth.AuthenticateRequest(r)
+	}
+
+	claims := jwt.MapClaims{}
+	parser := jwt.NewParser(jwt.WithValidMethods(oidcAlgorithms))
+	token, err := parser.ParseWithClaims(tokenStr, claims, a.verificationKey)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+	if !token.Valid {
+		return Identity{}, ErrInvalidToken
+	}
+	if !claims.VerifyAudience(a.config.Audience, true) {
+		return Identity{}, fmt.Errorf("%w: not issued for %s", ErrInvalidToken, a.config.Audience)
+	}
+	if !claims.VerifyExpiresAt(time.Now().Unix(), true) {
+		return Identity{}, fmt.Errorf("%w: no expiry", ErrInvalidToken)
+	}
+
+	subject, _ := claims["sub"].(string)
+	if subject == "" {
+		return Identity{}, fmt.Errorf("%w: no subject", ErrInvalidToken)
+	}
+	role, ok := a.role(stringsClaim(claims[a.config.GroupsClaim]))
+	if !ok {
+		return Identity{}, fmt.Errorf("%w: not a member of a group with a role", ErrInvalidToken)
+	}
+	user, err := a.users.ProvisionExternalUser(a.config.Issuer, subject, username(claims), role)
+	if err != nil {
+		return Identity{}, fmt.Errorf("%w: %w", ErrInvalidToken, err)
+	}
+
+	identity := Identity{UserID: user.ID, Role: string(user.Role)}
+	identity.TokenID, _ = claims["jti"].(string)
+	if exp, ok := claims["exp"].(float64); ok {
+		identity.TokenExpiresAt = time.Unix(int64(exp), 0)
+	}
+	if identity.TokenID != "" && a.revoked != nil {
+		revoked, err := a.revoked.IsTokenRevoked(identity.TokenID)
+		if err != nil {
+			return Identity{}, fmt.Errorf("checking the revocation list: %w", err)
+		}
+		if revoked {
+			return Identity{}, fmt.Errorf("%w: token revoked", ErrInvalidToken)
+		}
+	}
+
+	return identity, nil
+}
+
+// issuedByProvider reports whether the unverified iss claim of the token is the
+// provider.
+func (a *OIDCAuth) issuedByProvider(tokenStr string) bool {
+	if tokenStr == "" {
+		return false
+	}
+	claims := jwt.MapClaims{}
+	_, _, err := jwt.NewParser().ParseUnverified(tokenStr, claims)
+	if err != nil {
+		return false
+	}
+	iss, _ := claims["iss"].(string)
+
+	return iss == a.config.Issuer
+}
+
+// role returns the highest role granted by the groups, false if the user is not
+// allowed in.
+func (a *OIDCAuth) role(groups []string) (models.Role, bool) {
+	for i := len(models.Roles) - 1; i >= 0; i-- {
+		role := models.Roles[i]
+		for _, group := range a.config.RoleGroups[role] {
+			if slices.Contains(groups, group) {
+				return role, true
+			}
+		}
+	}
+	if len(a.config.RoleGroups[models.RoleReader]) > 0 {
+		return "", false
+	}
+
+	return models.RoleReader, true
+}
+
+// verificationKey returns the key of the provider named by the kid header of the
+// token. Tokens without a kid are accepted if the provider has a single key.
+func (a *OIDCAuth) verificationKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+	jwk, err := a.key(kid)
+	if err != nil {
+		return nil, err
+	}
+	if jwk.Use != "" && jwk.Use != "sig" {
+		return nil, fmt.Errorf("key %s is not a signing key", jwk.Kid)
+	}
+	if jwk.Alg != "" && jwk.Alg != token.Method.Alg() {
+		return nil, fmt.Errorf("key %s is not a %s key", jwk.Kid, token.Method.Alg())
+	}
+
+	return jwk.PublicKey()
+}
+
+// key returns the key with the kid, fetching the JWKS again if the key is unknown and
+// it was not fetched recently.
+func (a *OIDCAuth) key(kid string) (JWK, error) {
+	jwk, ok, fetchedAt := a.cachedKey(kid)
+	if ok {
+		return jwk, nil
+	}
+	if time.Since(fetchedAt) < a.config.KeysRefreshInterval {
+		return JWK{}, fmt.Errorf("unknown key %q", kid)
+	}
+
+	a.fetchMu.Lock()
+	// Another request may have fetched the keys in the meantime.
+	if _, _, latest := a.cachedKey(kid); latest.Equal(fetchedAt) {
+		err := a.fetchKeys()
+		if err != nil {
+			a.fetchMu.Unlock()
+			return JWK{}, err
+		}
+	}
+	a.fetchMu.Unlock()
+
+	jwk, ok, _ = a.cachedKey(kid)
+	if !ok {
+		return JWK{}, fmt.Errorf("unknown key %q", kid)
+	}
+
+	return jwk, nil
+}
+
+func (a *OIDCAuth) cachedKey(kid string) (JWK, bool, time.Time) {
+	a.keysMu.RLock()
+	defer a.keysMu.RUnlock()
+
+	if kid == "" && len(a.keys) == 1 {
+		for _, jwk := range a.keys {
+			return jwk, true, a.fetchedAt
+		}
+	}
+	jwk, ok := a.keys[kid]
+
+	return jwk, ok, a.fetchedAt
+}
+
+// fetchKeys replaces the keys with the JWKS of the provider.
+func (a *OIDCAuth) fetchKeys() error {
+	var jwks JWKS
+	err := a.get(a.jwksURI, &jwks)
+
+	a.keysMu.Lock()
+	defer a.keysMu.Unlock()
+
+	a.fetchedAt = time.Now()
+	if err != nil {
+		return fmt.Errorf("fetching the JWKS of OIDC issuer %s: %w", a.config.Issuer, err)
+	}
+	a.keys = make(map[string]JWK, len(jwks.Keys))
+	for _, jwk := range jwks.Keys {
+		a.keys[jwk.Kid] = jwk
+	}
+
+	return nil
+}
+
+func (a *OIDCAuth) get(url string, v any) error {
+	resp, err := a.config.Client.Get(url)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("GET %s: %s", url, resp.Status)
+	}
+
+	return json.NewDecoder(resp.Body).Decode(v)
+}
+
+// username returns the claim new local users are named after.
+func username(claims jwt.MapClaims) string {
+	for _, claim := range []string{"preferred_username", "email", "sub"} {
+		if name, ok := claims[claim].(string); ok && name != "" {
+			return name
+		}
+	}
+	return ""
+}
+
+// stringsClaim returns the strings of a claim that is a string or a list of strings.
+func stringsClaim(claim any) []string {
+	switch claim := claim.(type) {
+	case string:
+		return []string{claim}
+	case []any:
+		values := make([]string, 0, len(claim))
+		for _, v := range claim {
+			if s, ok := v.(string); ok {
+				values = append(values, s)
+			}
+		}
+		return values
+	default:
+		return nil
+	}
+}
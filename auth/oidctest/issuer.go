@@ -0,0 +1,123 @@
+// Package oidctest provides a stand-in OpenID Connect provider for tests.
+package oidctest
+
+import (
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/segmentio/ksuid"
+
+	"eth-fetcher/auth"
+)
+
+// Audience is the audience of the tokens of the Issuer unless the claims set one.
+const Audience = "eth-fetcher"
+
+// Issuer serves the discovery document and the JWKS of an identity provider and
+// issues RS256 tokens signed with its current key.
+type Issuer struct {
+	*httptest.Server
+
+	mu           sync.Mutex
+	kid          string
+	key          *rsa.PrivateKey
+	jwks         auth.JWKS
+	jwksRequests int
+}
+
+// NewIssuer starts an issuer with a single key, it is closed when the test ends.
+func NewIssuer(t *testing.T) *Issuer {
+	t.Helper()
+
+	i := &Issuer{jwks: auth.JWKS{Keys: []auth.JWK{}}}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]string{
+			"issuer":   i.URL,
+			"jwks_uri": i.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		i.mu.Lock()
+		defer i.mu.Unlock()
+		i.jwksRequests++
+		writeJSON(w, i.jwks)
+	})
+	i.Server = httptest.NewServer(mux)
+	t.Cleanup(i.Close)
+
+	i.RotateKey(t)
+
+	return i
+}
+
+// RotateKey signs the next tokens with a new key, the previous keys stay in the JWKS.
+func (i *Issuer) RotateKey(t *testing.T) {
+	t.Helper()
+
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	kid := ksuid.New().String()
+	jwk, err := auth.NewJWK(kid, "RS256", key.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.kid, i.key = kid, key
+	i.jwks.Keys = append(i.jwks.Keys, jwk)
+}
+
+// Token returns a token signed with the current key. The iss, aud, sub, iat and exp
+// claims are set unless the claims have them, a nil claim is left out.
+func (i *Issuer) Token(t *testing.T, claims jwt.MapClaims) string {
+	t.Helper()
+
+	all := jwt.MapClaims{
+		"iss": i.URL,
+		"aud": Audience,
+		"sub": ksuid.New().String(),
+		"iat": time.Now().Unix(),
+		"exp": time.Now().Add(time.Hour).Unix(),
+	}
+	for name, value := range claims {
+		if value == nil {
+			delete(all, name)
+			continue
+		}
+		all[name] = value
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, all)
+	token.Header["kid"] = i.kid
+	signed, err := token.SignedString(i.key)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return signed
+}
+
+// JWKSRequests returns how often the JWKS was requested.
+func (i *Issuer) JWKSRequests() int {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	return i.jwksRequests
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	_ = json.NewEncoder(w).Encode(v)
+}
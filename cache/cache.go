@@ -0,0 +1,213 @@
+package cache
+
+import (
+	"container/list"
+	"eth-fetcher/app"
+	"eth-fetcher/database/models"
+	"expvar"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// finalityRefreshInterval is how often the finalized block number is refreshed.
+const finalityRefreshInterval = 30 * time.Second
+
+// metrics are the hits, misses and evictions of all caches, served by the admin
+// metrics endpoint.
+var metrics = expvar.NewMap("tx_cache")
+
+// Finality provides the number of the latest finalized block.
+type Finality interface {
+	FinalizedBlockNumber() (int64, error)
+}
+
+// DB is a bounded in-memory LRU cache of finalized transactions in front of another
+// app.DB. Transactions from blocks that are not finalized yet can still be reorged
+// away, so they are always read from the wrapped database.
+type DB struct {
+	app.DB
+
+	finality Finality
+	size     int
+	ttl      time.Duration
+	log      *zap.SugaredLogger
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+
+	finalized atomic.Int64
+
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+type entry struct {
+	tx      models.Transaction
+	expires time.Time
+}
+
+// New wraps db with a cache of at most size transactions, each kept for ttl.
+func New(db app.DB, finality Finality, size int, ttl time.Duration, log *zap.SugaredLogger) *DB {
+	c := &DB{
+		DB:       db,
+		finality: finality,
+		size:     size,
+		ttl:      ttl,
+		log:      log,
+		entries:  make(map[string]*list.Element),
+		lru:      list.New(),
+		done:     make(chan struct{}),
+	}
+
+	c.refreshFinality()
+	c.wg.Add(1)
+	go c.watchFinality()
+
+	return c
+}
+
+// GetTransactionsByHashes returns the cached transactions and reads the rest from the
+// wrapped database.
+func (c *DB) GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error) {
+	transactions := make([]*models.Transaction, 0, len(hashes))
+	var missing []string
+
+	for _, hash := range hashes {
+		if tx, ok := c.get(hash); ok {
+			transactions = append(transactions, tx)
+			continue
+		}
+		missing = append(missing, hash)
+	}
+
+	metrics.Add("hits", int64(len(transactions)))
+	metrics.Add("misses", int64(len(missing)))
+
+	if len(missing) == 0 {
+		return transactions, nil
+	}
+
+	fetched, err := c.DB.GetTransactionsByHashes(missing)
+	if err != nil {
+		return nil, err
+	}
+	for _, tx := range fetched {
+		c.add(tx)
+	}
+
+	return append(transactions, fetched...), nil
+}
+
+// SaveTransactions saves the transactions and caches the finalized ones, replacing
+// their cached versions.
+func (c *DB) SaveTransactions(transactions []*models.Transaction) error {
+	err := c.DB.SaveTransactions(transactions)
+	if err != nil {
+		return err
+	}
+	for _, tx := range transactions {
+		c.add(tx)
+	}
+
+	return nil
+}
+
+// PruneTransactions prunes the wrapped database and empties the cache, so the pruned
+// transactions are not served from it anymore.
+func (c *DB) PruneTransactions(notViewedSince time.Time, dryRun bool) (int64, error) {
+	pruned, err := c.DB.PruneTransactions(notViewedSince, dryRun)
+	if err != nil || dryRun || pruned == 0 {
+		return pruned, err
+	}
+
+	c.mu.Lock()
+	c.entries = make(map[string]*list.Element)
+	c.lru.Init()
+	c.mu.Unlock()
+
+	return pruned, nil
+}
+
+// Close stops refreshing the finalized block and closes the wrapped database.
+func (c *DB) Close() error {
+	close(c.done)
+	c.wg.Wait()
+
+	return c.DB.Close()
+}
+
+func (c *DB) get(hash string) (*models.Transaction, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	el, ok := c.entries[hash]
+	if !ok {
+		return nil, false
+	}
+
+	e := el.Value.(*entry)
+	if time.Now().After(e.expires) {
+		c.lru.Remove(el)
+		delete(c.entries, hash)
+		return nil, false
+	}
+
+	c.lru.MoveToFront(el)
+	tx := e.tx
+
+	return &tx, true
+}
+
+// add caches a copy of the transaction if its block is finalized.
+func (c *DB) add(tx *models.Transaction) {
+	if tx.BlockNumber <= 0 || tx.BlockNumber > c.finalized.Load() {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	e := &entry{tx: *tx, expires: time.Now().Add(c.ttl)}
+	if el, ok := c.entries[tx.TxHash]; ok {
+		el.Value = e
+		c.lru.MoveToFront(el)
+		return
+	}
+
+	c.entries[tx.TxHash] = c.lru.PushFront(e)
+	for c.lru.Len() > c.size {
+		oldest := c.lru.Back()
+		c.lru.Remove(oldest)
+		delete(c.entries, oldest.Value.(*entry).tx.TxHash)
+		metrics.Add("evictions", 1)
+	}
+}
+
+func (c *DB) watchFinality() {
+	defer c.wg.Done()
+
+	ticker := time.NewTicker(finalityRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-c.done:
+			return
+		case <-ticker.C:
+			c.refreshFinality()
+		}
+	}
+}
+
+func (c *DB) refreshFinality() {
+	number, err := c.finality.FinalizedBlockNumber()
+	if err != nil {
+		c.log.Errorf("error refreshing finalized block: %v", err)
+		return
+	}
+	c.finalized.Store(number)
+}
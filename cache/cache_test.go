@@ -0,0 +1,176 @@
+package cache_test
+
+import (
+	"expvar"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+
+	appmocks "eth-fetcher/app/mocks"
+	"eth-fetcher/cache"
+	"eth-fetcher/cache/mocks"
+	"eth-fetcher/database/models"
+)
+
+const (
+	hash1 = "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"
+	hash2 = "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"
+	hash3 = "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057"
+)
+
+var finalizedTx1 = &models.Transaction{TxHash: hash1, BlockNumber: 100}
+
+var finalizedTx2 = &models.Transaction{TxHash: hash2, BlockNumber: 90}
+
+var pendingTx = &models.Transaction{TxHash: hash3, BlockNumber: 101}
+
+func Setup(t *testing.T, size int, ttl time.Duration) (*appmocks.DB, *cache.DB) {
+	db := appmocks.NewDB(t)
+	finality := mocks.NewFinality(t)
+	finality.EXPECT().FinalizedBlockNumber().Return(100, nil)
+
+	logger, _ := zap.NewProduction()
+	defer logger.Sync()
+	sLog := logger.Sugar()
+
+	c := cache.New(db, finality, size, ttl, sLog)
+	t.Cleanup(func() {
+		db.On("Close").Return(nil)
+		c.Close()
+	})
+
+	return db, c
+}
+
+// counter returns the value of a published cache counter. The counters are shared by
+// all caches, so tests compare them before and after.
+func counter(name string) int64 {
+	v, ok := expvar.Get("tx_cache").(*expvar.Map).Get(name).(*expvar.Int)
+	if !ok {
+		return 0
+	}
+	return v.Value()
+}
+
+func TestDB_GetTransactionsByHashes(t *testing.T) {
+	db, c := Setup(t, 10, time.Hour)
+	hits, misses := counter("hits"), counter("misses")
+
+	// The first lookup reads everything from the database
+	db.EXPECT().GetTransactionsByHashes([]string{hash1, hash3}).Return([]*models.Transaction{finalizedTx1, pendingTx}, nil).Once()
+
+	transactions, err := c.GetTransactionsByHashes([]string{hash1, hash3})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 2)
+
+	// The finalized transaction is cached, the pending one is read again
+	db.EXPECT().GetTransactionsByHashes([]string{hash3}).Return([]*models.Transaction{pendingTx}, nil).Once()
+
+	transactions, err = c.GetTransactionsByHashes([]string{hash1, hash3})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{finalizedTx1, pendingTx}, transactions)
+
+	assert.Equal(t, int64(1), counter("hits")-hits)
+	assert.Equal(t, int64(3), counter("misses")-misses)
+
+	db.AssertExpectations(t)
+}
+
+func TestDB_SaveTransactions(t *testing.T) {
+	db, c := Setup(t, 10, time.Hour)
+
+	db.EXPECT().SaveTransactions([]*models.Transaction{finalizedTx1, pendingTx}).Return(nil)
+
+	err := c.SaveTransactions([]*models.Transaction{finalizedTx1, pendingTx})
+	assert.NoError(t, err)
+
+	// The saved finalized transaction is served from the cache
+	transactions, err := c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{finalizedTx1}, transactions)
+
+	// Saving it again replaces the cached version
+	updated := *finalizedTx1
+	updated.TxStatus = 1
+	db.EXPECT().SaveTransactions([]*models.Transaction{&updated}).Return(nil)
+
+	err = c.SaveTransactions([]*models.Transaction{&updated})
+	assert.NoError(t, err)
+
+	transactions, err = c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{&updated}, transactions)
+
+	db.AssertNotCalled(t, "GetTransactionsByHashes", []string{hash1})
+}
+
+func TestDB_Eviction(t *testing.T) {
+	db, c := Setup(t, 1, time.Hour)
+	evictions := counter("evictions")
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return([]*models.Transaction{finalizedTx1}, nil).Twice()
+	db.EXPECT().GetTransactionsByHashes([]string{hash2}).Return([]*models.Transaction{finalizedTx2}, nil).Once()
+
+	// Caching the second transaction evicts the first one
+	for _, hash := range []string{hash1, hash2, hash1} {
+		_, err := c.GetTransactionsByHashes([]string{hash})
+		assert.NoError(t, err)
+	}
+
+	assert.Equal(t, int64(2), counter("evictions")-evictions)
+
+	db.AssertExpectations(t)
+}
+
+func TestDB_Expiration(t *testing.T) {
+	db, c := Setup(t, 10, time.Millisecond)
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return([]*models.Transaction{finalizedTx1}, nil).Twice()
+
+	_, err := c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+
+	time.Sleep(5 * time.Millisecond)
+
+	// The expired transaction is read from the database again
+	_, err = c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+
+	db.AssertExpectations(t)
+}
+
+func TestDB_PruneTransactions(t *testing.T) {
+	db, c := Setup(t, 10, time.Hour)
+	cutoff := time.Now()
+
+	db.EXPECT().SaveTransactions([]*models.Transaction{finalizedTx1}).Return(nil)
+	assert.NoError(t, c.SaveTransactions([]*models.Transaction{finalizedTx1}))
+
+	// A dry run keeps the cache
+	db.EXPECT().PruneTransactions(cutoff, true).Return(1, nil)
+
+	pruned, err := c.PruneTransactions(cutoff, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	transactions, err := c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{finalizedTx1}, transactions)
+
+	// Pruned transactions are not served from the cache anymore
+	db.EXPECT().PruneTransactions(cutoff, false).Return(1, nil)
+
+	pruned, err = c.PruneTransactions(cutoff, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	db.EXPECT().GetTransactionsByHashes([]string{hash1}).Return(nil, nil).Once()
+
+	transactions, err = c.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	db.AssertExpectations(t)
+}
@@ -0,0 +1,87 @@
+// Code generated by mockery v2.40.1. DO NOT EDIT.
+
+package mocks
+
+import mock "github.com/stretchr/testify/mock"
+
+// Finality is an autogenerated mock type for the Finality type
+type Finality struct {
+	mock.Mock
+}
+
+type Finality_Expecter struct {
+	mock *mock.Mock
+}
+
+func (_m *Finality) EXPECT() *Finality_Expecter {
+	return &Finality_Expecter{mock: &_m.Mock}
+}
+
+// FinalizedBlockNumber provides a mock function with given fields:
+func (_m *Finality) FinalizedBlockNumber() (int64, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for FinalizedBlockNumber")
+	}
+
+	var r0 int64
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (int64, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() int64); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(int64)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Finality_FinalizedBlockNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'FinalizedBlockNumber'
+type Finality_FinalizedBlockNumber_Call struct {
+	*mock.Call
+}
+
+// FinalizedBlockNumber is a helper method to define mock.On call
+func (_e *Finality_Expecter) FinalizedBlockNumber() *Finality_FinalizedBlockNumber_Call {
+	return &Finality_FinalizedBlockNumber_Call{Call: _e.mock.On("FinalizedBlockNumber")}
+}
+
+func (_c *Finality_FinalizedBlockNumber_Call) Run(run func()) *Finality_FinalizedBlockNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Finality_FinalizedBlockNumber_Call) Return(_a0 int64, _a1 error) *Finality_FinalizedBlockNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Finality_FinalizedBlockNumber_Call) RunAndReturn(run func() (int64, error)) *Finality_FinalizedBlockNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// NewFinality creates a new instance of Finality. It also registers a testing interface on the mock and a cleanup function to assert the mocks expectations.
+// The first argument is typically a *testing.T value.
+func NewFinality(t interface {
+	mock.TestingT
+	Cleanup(func())
+}) *Finality {
+	mock := &Finality{}
+	mock.Mock.Test(t)
+
+	t.Cleanup(func() { mock.AssertExpectations(t) })
+
+	return mock
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"errors"
+	"eth-fetcher/app"
+	"eth-fetcher/cache"
+	"eth-fetcher/config"
+	"eth-fetcher/database"
+	"eth-fetcher/database/models"
+	node "eth-fetcher/nodeconnect"
+	"eth-fetcher/storage"
+	"fmt"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+// command is a subcommand of the binary. All commands share the configuration
+// loaded from the config file, the environment and the flags.
+type command struct {
+	name    string
+	args    string
+	summary string
+	run     func(cfg *config.Config, args []string, log *zap.SugaredLogger) error
+	// noConfig commands run without loading the configuration, cfg is nil
+	noConfig bool
+	// dbConfig commands only use the database, the other settings are not validated
+	dbConfig bool
+}
+
+var commands = []command{
+	{name: "serve", summary: "start the HTTP API, the default without a command", run: serve},
+	{name: "fetch", args: "<hash...>", summary: "look up transactions and print them as JSON", run: fetch},
+	{name: "decode-rlp", args: "<hex>", summary: "decode an RLP encoded list of transaction hashes", run: decodeRLP, noConfig: true},
+	{name: "users", args: "list | add | reset-password | enable", summary: "manage users", run: users, dbConfig: true},
+	{name: "migrate", args: "up | down [steps] | goto <version> | version", summary: "change the database schema", run: migrate, dbConfig: true},
+	{name: "export", args: "[file]", summary: "export transactions and user histories as NDJSON", run: exportDataset, dbConfig: true},
+	{name: "import", args: "[file]", summary: "import transactions and user histories from NDJSON", run: importDataset, dbConfig: true},
+}
+
+var errNoNode = errors.New("the command does not connect to the eth node")
+
+func findCommand(name string) (command, bool) {
+	for _, cmd := range commands {
+		if cmd.name == name {
+			return cmd, true
+		}
+	}
+	return command{}, false
+}
+
+func usage() string {
+	var b strings.Builder
+	b.WriteString("usage: eth-fetcher [flags] [command] [arguments]\n\ncommands:\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(&b, "  %-11s %s\n", cmd.name, cmd.summary)
+		if cmd.args != "" {
+			fmt.Fprintf(&b, "  %-11s   %s %s\n", "", cmd.name, cmd.args)
+		}
+	}
+	b.WriteString("  help        show this help\n\n")
+	b.WriteString("flags override the config file and the environment variables of the same name:\n")
+	return b.String()
+}
+
+// openStore opens the configured database, retrying until it is reachable.
+func openStore(cfg *config.Config, log *zap.SugaredLogger) (app.DB, error) {
+	db, err := storage.Open(cfg.DatabaseURL(),
+		database.WithPool(database.Pool{
+			MaxOpenConns:    cfg.Database.MaxOpenConns,
+			MaxIdleConns:    cfg.Database.MaxIdleConns,
+			ConnMaxLifetime: cfg.Database.ConnMaxLifetime,
+			ConnMaxIdleTime: cfg.Database.ConnMaxIdleTime,
+		}),
+		database.WithConnectRetry(cfg.Database.ConnectTimeout, log),
+		database.WithReadReplica(cfg.ReadReplicaURL()),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("error creating db client: %w", err)
+	}
+
+	return db, nil
+}
+
+// newApp creates the App on top of the configured database, cache and the eth node.
+func newApp(cfg *config.Config, tg *node.Node, log *zap.SugaredLogger, opts ...app.Option) (*app.App, error) {
+	db, err := openStore(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	var store app.DB = db
+	if cfg.Cache.Size > 0 {
+		store = cache.New(db, tg, cfg.Cache.Size, cfg.Cache.TTL, log)
+	}
+
+	opts = append([]app.Option{
+		app.WithBackfillConcurrency(cfg.BackfillConcurrency),
+		app.WithNodeFetchLimits(cfg.NodeLimits.Concurrency, cfg.NodeLimits.ConcurrencyPerUser, cfg.NodeLimits.MaxPending),
+	}, opts...)
+
+	return app.NewApp(store, tg, log, opts...), nil
+}
+
+// newOfflineApp creates the App on top of the configured database only, for commands
+// that never fetch from the eth node.
+func newOfflineApp(cfg *config.Config, log *zap.SugaredLogger) (*app.App, error) {
+	db, err := openStore(cfg, log)
+	if err != nil {
+		return nil, err
+	}
+
+	return app.NewApp(db, noNode{}, log), nil
+}
+
+// noNode stands in for the eth node in commands that do not connect to it.
+type noNode struct{}
+
+func (noNode) GetTransaction(string) (*models.Transaction, error) {
+	return nil, errNoNode
+}
+
+func (noNode) GetBlockTransactions(int64) ([]*models.Transaction, error) {
+	return nil, errNoNode
+}
+
+func (noNode) Close() {}
@@ -1,46 +1,329 @@
 package config
 
 import (
-	"os"
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
 	"time"
 )
 
+const (
+	EnvDev        = "dev"
+	EnvProduction = "production"
+)
+
+// DefaultJWTSecret is only accepted in the dev environment.
+const DefaultJWTSecret = "secret"
+
+// NodeAPIKeyPlaceholder is replaced with the API key in the eth node URL.
+const NodeAPIKeyPlaceholder = "{apiKey}"
+
+// LogLevels are the accepted log levels.
+var LogLevels = []string{"debug", "info", "warn", "error"}
+
 type Config struct {
-	APIPort         string
-	ETHNodeURL      string
-	DBConnectionURL string
-	JWT             JWT
+	// Env is dev or production, production refuses insecure defaults.
+	Env      string `yaml:"env"`
+	LogLevel string `yaml:"logLevel"`
+	APIPort  string `yaml:"apiPort"`
+	// CORSOrigins are the origins allowed to call the API from a browser, * allows all.
+	CORSOrigins []string `yaml:"corsOrigins"`
+	ETHNodeURL  string   `yaml:"ethNodeUrl"`
+	// ETHNodeAPIKey replaces NodeAPIKeyPlaceholder in ETHNodeURL.
+	ETHNodeAPIKey   string   `yaml:"ethNodeApiKey"`
+	DBConnectionURL string   `yaml:"dbConnectionUrl"`
+	Database        Database `yaml:"database"`
+	JWT             JWT      `yaml:"jwt"`
+	// OIDC accepts the tokens of a single sign-on provider.
+	OIDC       OIDC     `yaml:"oidc"`
+	LabelFiles []string `yaml:"labelFiles"`
+	// Registration allows anyone to create a user with POST /api/register.
+	Registration bool `yaml:"registration"`
+
+	BackfillConcurrency int `yaml:"backfillConcurrency"`
+
+	Cache Cache `yaml:"cache"`
+
+	NodeLimits NodeLimits `yaml:"nodeLimits"`
+
+	Retention Retention `yaml:"retention"`
+}
+
+// Default returns the configuration used for the settings that are not set.
+func Default() *Config {
+	cfg := &Config{
+		Env:      EnvProduction,
+		LogLevel: "info",
+		APIPort:  "8080",
+	}
+	cfg.JWT.Default()
+	cfg.OIDC.Default()
+	cfg.Database.Default()
+	cfg.Cache.Default()
+	cfg.Retention.Default()
+
+	return cfg
 }
 
-func LoadConfig() *Config {
-	jwt := JWT{}
-	jwt.Default()
+// Validate reports every invalid setting.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.Env != EnvDev && c.Env != EnvProduction {
+		invalid("env must be %s or %s, got %q", EnvDev, EnvProduction, c.Env)
+	}
+
+	if !slices.Contains(LogLevels, c.LogLevel) {
+		invalid("log level must be one of %v, got %q", LogLevels, c.LogLevel)
+	}
+
+	port, err := strconv.Atoi(c.APIPort)
+	if err != nil || port < 1 || port > 65535 {
+		invalid("API port must be between 1 and 65535, got %q", c.APIPort)
+	}
 
-	if os.Getenv("JWT_SECRET") != "" {
-		jwt.Secret = os.Getenv("JWT_SECRET")
+	err = validateURL(c.ETHNodeURL, "http", "https", "ws", "wss")
+	if err != nil {
+		invalid("eth node URL: %v", err)
+	}
+	hasPlaceholder := strings.Contains(c.ETHNodeURL, NodeAPIKeyPlaceholder)
+	if c.ETHNodeAPIKey != "" && !hasPlaceholder {
+		invalid("eth node API key is set but the eth node URL has no %s", NodeAPIKeyPlaceholder)
+	} else if c.ETHNodeAPIKey == "" && hasPlaceholder {
+		invalid("eth node URL needs an API key for %s", NodeAPIKeyPlaceholder)
 	}
 
-	if os.Getenv("JWT_DURATION") != "" {
-		duration, err := time.ParseDuration(os.Getenv("JWT_DURATION"))
+	if c.JWT.Secret == "" {
+		invalid("JWT secret is required")
+	} else if c.JWT.Secret == DefaultJWTSecret && c.Env != EnvDev {
+		invalid("JWT secret must be changed from the default outside the %s env", EnvDev)
+	}
+	if c.JWT.Duration <= 0 {
+		invalid("JWT duration must be positive, got %s", c.JWT.Duration)
+	}
+	if !slices.Contains(JWTAlgorithms, c.JWT.Algorithm) {
+		invalid("JWT algorithm must be one of %v, got %q", JWTAlgorithms, c.JWT.Algorithm)
+	}
+	if c.JWT.KeyRotation <= 0 {
+		invalid("JWT key rotation must be positive, got %s", c.JWT.KeyRotation)
+	}
+	if c.JWT.RefreshDuration <= 0 {
+		invalid("JWT refresh duration must be positive, got %s", c.JWT.RefreshDuration)
+	}
+	if c.OIDC.Issuer != "" {
+		schemes := []string{"https"}
+		if c.Env == EnvDev {
+			schemes = append(schemes, "http")
+		}
+		err = validateURL(c.OIDC.Issuer, schemes...)
 		if err != nil {
-			jwt.Duration = duration
+			invalid("OIDC issuer: %v", err)
+		}
+		if c.OIDC.Audience == "" {
+			invalid("OIDC audience is required with an OIDC issuer")
+		}
+	}
+
+	for name, n := range map[string]int{
+		"backfill concurrency":       c.BackfillConcurrency,
+		"cache size":                 c.Cache.Size,
+		"node concurrency":           c.NodeLimits.Concurrency,
+		"node concurrency per user":  c.NodeLimits.ConcurrencyPerUser,
+		"node max pending":           c.NodeLimits.MaxPending,
+		"retention days":             c.Retention.Days,
+		"retention max user history": c.Retention.MaxUserHistory,
+	} {
+		if n < 0 {
+			invalid("%s must not be negative, got %d", name, n)
+		}
+	}
+	if c.Cache.Size > 0 && c.Cache.TTL <= 0 {
+		invalid("cache TTL must be positive, got %s", c.Cache.TTL)
+	}
+	if c.Retention.Interval <= 0 {
+		invalid("retention interval must be positive, got %s", c.Retention.Interval)
+	}
+
+	return errors.Join(append(errs, c.ValidateDatabase())...)
+}
+
+// ValidateDatabase reports every invalid database setting, the only ones the commands
+// that just use the database need.
+func (c *Config) ValidateDatabase() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if c.DBConnectionURL == "" {
+		invalid("DB connection URL is required")
+	}
+	if c.Database.Password != "" {
+		for name, rawURL := range map[string]string{"DB connection URL": c.DBConnectionURL, "read replica URL": c.Database.ReadReplicaURL} {
+			u, err := url.Parse(rawURL)
+			if rawURL != "" && (err != nil || u.User == nil) {
+				invalid("DB password is set but the %s has no user", name)
+			}
+		}
+	}
+
+	for name, n := range map[string]int{
+		"DB max open conns": c.Database.MaxOpenConns,
+		"DB max idle conns": c.Database.MaxIdleConns,
+	} {
+		if n < 0 {
+			invalid("%s must not be negative, got %d", name, n)
+		}
+	}
+	for name, d := range map[string]time.Duration{
+		"DB conn max lifetime":  c.Database.ConnMaxLifetime,
+		"DB conn max idle time": c.Database.ConnMaxIdleTime,
+		"DB connect timeout":    c.Database.ConnectTimeout,
+	} {
+		if d < 0 {
+			invalid("%s must not be negative, got %s", name, d)
 		}
 	}
 
-	return &Config{
-		APIPort:         os.Getenv("API_PORT"),
-		ETHNodeURL:      os.Getenv("ETH_NODE_URL"),
-		DBConnectionURL: os.Getenv("DB_CONNECTION_URL"),
-		JWT:             jwt,
+	return errors.Join(errs...)
+}
+
+// NodeURL returns the eth node URL with the API key.
+func (c *Config) NodeURL() string {
+	return strings.ReplaceAll(c.ETHNodeURL, NodeAPIKeyPlaceholder, url.PathEscape(c.ETHNodeAPIKey))
+}
+
+// DatabaseURL returns the DB connection URL with the DB password.
+func (c *Config) DatabaseURL() string {
+	return withPassword(c.DBConnectionURL, c.Database.Password)
+}
+
+// ReadReplicaURL returns the read replica URL with the DB password.
+func (c *Config) ReadReplicaURL() string {
+	return withPassword(c.Database.ReadReplicaURL, c.Database.Password)
+}
+
+func withPassword(rawURL, password string) string {
+	u, err := url.Parse(rawURL)
+	if password == "" || rawURL == "" || err != nil || u.User == nil {
+		return rawURL
+	}
+	u.User = url.UserPassword(u.User.Username(), password)
+
+	return u.String()
+}
+
+func validateURL(rawURL string, schemes ...string) error {
+	if rawURL == "" {
+		return errors.New("required")
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return err
+	}
+	for _, scheme := range schemes {
+		if u.Scheme == scheme && u.Host != "" {
+			return nil
+		}
 	}
+	return fmt.Errorf("%q is not a %v URL", rawURL, schemes)
 }
 
+// JWTAlgorithms are the algorithms signing the access tokens. HS256 signs them with the
+// secret, the others with keys that are rotated and published.
+var JWTAlgorithms = []string{"RS256", "ES256", "HS256"}
+
+// JWT configures the short-lived access tokens and the refresh tokens that renew them.
 type JWT struct {
-	Secret   string
-	Duration time.Duration
+	// Secret signs the HS256 tokens, or encrypts the stored private keys.
+	Secret          string        `yaml:"secret"`
+	Algorithm       string        `yaml:"algorithm"`
+	KeyRotation     time.Duration `yaml:"keyRotation"`
+	Duration        time.Duration `yaml:"duration"`
+	RefreshDuration time.Duration `yaml:"refreshDuration"`
 }
 
 func (j *JWT) Default() {
-	j.Secret = "secret"
-	j.Duration = time.Hour * 24
+	j.Secret = DefaultJWTSecret
+	j.Algorithm = "RS256"
+	j.KeyRotation = time.Hour * 24 * 30
+	j.Duration = time.Minute * 15
+	j.RefreshDuration = time.Hour * 24 * 7
+}
+
+// OIDC configures the OpenID Connect provider whose tokens are accepted, an empty
+// Issuer disables it. Its users get the role of the groups they are in.
+type OIDC struct {
+	Issuer      string `yaml:"issuer"`
+	Audience    string `yaml:"audience"`
+	GroupsClaim string `yaml:"groupsClaim"`
+	// ReaderGroups restrict the access to their members when set, otherwise every
+	// user of the provider is a reader.
+	ReaderGroups  []string `yaml:"readerGroups"`
+	AnalystGroups []string `yaml:"analystGroups"`
+	AdminGroups   []string `yaml:"adminGroups"`
+}
+
+func (o *OIDC) Default() {
+	o.GroupsClaim = "groups"
+}
+
+// Database configures the connection pool, the startup retries and the optional
+// read replica of the database.
+type Database struct {
+	MaxOpenConns    int           `yaml:"maxOpenConns"`
+	MaxIdleConns    int           `yaml:"maxIdleConns"`
+	ConnMaxLifetime time.Duration `yaml:"connMaxLifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"connMaxIdleTime"`
+	// ConnectTimeout is how long connecting is retried at startup.
+	ConnectTimeout time.Duration `yaml:"connectTimeout"`
+	ReadReplicaURL string        `yaml:"readReplicaUrl"`
+	// Password replaces the password of the connection and read replica URLs.
+	Password string `yaml:"password"`
+}
+
+func (d *Database) Default() {
+	d.MaxOpenConns = 20
+	d.MaxIdleConns = 10
+	d.ConnMaxLifetime = 30 * time.Minute
+	d.ConnMaxIdleTime = 5 * time.Minute
+	d.ConnectTimeout = time.Minute
+}
+
+// Cache configures the in-memory transaction cache, a zero Size disables it.
+type Cache struct {
+	Size int           `yaml:"size"`
+	TTL  time.Duration `yaml:"ttl"`
+}
+
+func (c *Cache) Default() {
+	c.Size = 10000
+	c.TTL = time.Hour
+}
+
+// NodeLimits bounds the concurrent eth node fetches, zero values use the app defaults.
+type NodeLimits struct {
+	Concurrency        int `yaml:"concurrency"`
+	ConcurrencyPerUser int `yaml:"concurrencyPerUser"`
+	MaxPending         int `yaml:"maxPending"`
+}
+
+// Retention configures pruning the stored data, zero Days and MaxUserHistory keep
+// everything.
+type Retention struct {
+	// Days is how long transactions no user viewed are kept.
+	Days           int           `yaml:"days"`
+	MaxUserHistory int           `yaml:"maxUserHistory"`
+	Interval       time.Duration `yaml:"interval"`
+	DryRun         bool          `yaml:"dryRun"`
+}
+
+func (r *Retention) Default() {
+	r.Interval = 24 * time.Hour
 }
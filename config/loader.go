@@ -0,0 +1,283 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// settings maps the environment variables to the fields they set. Every setting can
+// also be passed as a flag named after the variable in lowercase with dashes, e.g.
+// -db-max-open-conns for DB_MAX_OPEN_CONNS.
+var settings = []struct {
+	env   string
+	usage string
+	field func(*Config) any
+}{
+	{"APP_ENV", "dev or production", func(c *Config) any { return &c.Env }},
+	{"LOG_LEVEL", "debug, info, warn or error", func(c *Config) any { return &c.LogLevel }},
+	{"API_PORT", "port of the HTTP API", func(c *Config) any { return &c.APIPort }},
+	{"CORS_ORIGINS", "comma separated origins allowed to call the API from a browser", func(c *Config) any { return &c.CORSOrigins }},
+	{"ETH_NODE_URL", "URL of the eth node", func(c *Config) any { return &c.ETHNodeURL }},
+	{"ETH_NODE_API_KEY", "API key replacing {apiKey} in the eth node URL", func(c *Config) any { return &c.ETHNodeAPIKey }},
+	{"DB_CONNECTION_URL", "database DSN", func(c *Config) any { return &c.DBConnectionURL }},
+	{"DB_READ_REPLICA_URL", "read replica DSN", func(c *Config) any { return &c.Database.ReadReplicaURL }},
+	{"DB_PASSWORD", "password replacing the one of the database DSNs", func(c *Config) any { return &c.Database.Password }},
+	{"DB_MAX_OPEN_CONNS", "maximum open database connections", func(c *Config) any { return &c.Database.MaxOpenConns }},
+	{"DB_MAX_IDLE_CONNS", "maximum idle database connections", func(c *Config) any { return &c.Database.MaxIdleConns }},
+	{"DB_CONN_MAX_LIFETIME", "maximum lifetime of a database connection", func(c *Config) any { return &c.Database.ConnMaxLifetime }},
+	{"DB_CONN_MAX_IDLE_TIME", "maximum idle time of a database connection", func(c *Config) any { return &c.Database.ConnMaxIdleTime }},
+	{"DB_CONNECT_TIMEOUT", "how long connecting to the database is retried", func(c *Config) any { return &c.Database.ConnectTimeout }},
+	{"JWT_SECRET", "secret signing HS256 tokens or encrypting the signing keys", func(c *Config) any { return &c.JWT.Secret }},
+	{"JWT_ALGORITHM", "algorithm signing the JWT tokens, RS256, ES256 or HS256", func(c *Config) any { return &c.JWT.Algorithm }},
+	{"JWT_KEY_ROTATION", "how long a signing key signs new tokens", func(c *Config) any { return &c.JWT.KeyRotation }},
+	{"JWT_DURATION", "validity of the JWT access tokens", func(c *Config) any { return &c.JWT.Duration }},
+	{"JWT_REFRESH_DURATION", "validity of the refresh tokens", func(c *Config) any { return &c.JWT.RefreshDuration }},
+	{"OIDC_ISSUER", "URL of the OpenID Connect provider accepted for single sign-on", func(c *Config) any { return &c.OIDC.Issuer }},
+	{"OIDC_AUDIENCE", "audience the OpenID Connect tokens must be issued for", func(c *Config) any { return &c.OIDC.Audience }},
+	{"OIDC_GROUPS_CLAIM", "claim listing the groups of the OpenID Connect users", func(c *Config) any { return &c.OIDC.GroupsClaim }},
+	{"OIDC_READER_GROUPS", "comma separated groups of readers, empty allows all users", func(c *Config) any { return &c.OIDC.ReaderGroups }},
+	{"OIDC_ANALYST_GROUPS", "comma separated groups of analysts", func(c *Config) any { return &c.OIDC.AnalystGroups }},
+	{"OIDC_ADMIN_GROUPS", "comma separated groups of admins", func(c *Config) any { return &c.OIDC.AdminGroups }},
+	{"REGISTRATION_ENABLED", "allow anyone to create a user", func(c *Config) any { return &c.Registration }},
+	{"LABEL_FILES", "comma separated address label files", func(c *Config) any { return &c.LabelFiles }},
+	{"BACKFILL_CONCURRENCY", "blocks a backfill fetches in parallel", func(c *Config) any { return &c.BackfillConcurrency }},
+	{"CACHE_SIZE", "transactions in the cache, 0 disables it", func(c *Config) any { return &c.Cache.Size }},
+	{"CACHE_TTL", "how long transactions are cached", func(c *Config) any { return &c.Cache.TTL }},
+	{"NODE_CONCURRENCY", "concurrent eth node fetches", func(c *Config) any { return &c.NodeLimits.Concurrency }},
+	{"NODE_CONCURRENCY_PER_USER", "concurrent eth node fetches per user", func(c *Config) any { return &c.NodeLimits.ConcurrencyPerUser }},
+	{"NODE_MAX_PENDING", "waiting and running eth node fetches", func(c *Config) any { return &c.NodeLimits.MaxPending }},
+	{"RETENTION_DAYS", "days unviewed transactions are kept, 0 keeps them", func(c *Config) any { return &c.Retention.Days }},
+	{"RETENTION_MAX_USER_HISTORY", "viewed transactions kept per user, 0 keeps all", func(c *Config) any { return &c.Retention.MaxUserHistory }},
+	{"RETENTION_INTERVAL", "how often the retention job runs", func(c *Config) any { return &c.Retention.Interval }},
+	{"RETENTION_DRY_RUN", "only log what the retention job would prune", func(c *Config) any { return &c.Retention.DryRun }},
+}
+
+// secrets can also be read from the file named by the variable with a _FILE suffix,
+// or by the flag with a -file suffix.
+var secrets = map[string]bool{
+	"ETH_NODE_URL":        true,
+	"ETH_NODE_API_KEY":    true,
+	"DB_CONNECTION_URL":   true,
+	"DB_READ_REPLICA_URL": true,
+	"DB_PASSWORD":         true,
+	"JWT_SECRET":          true,
+}
+
+// reloadable settings can be changed without restarting the server.
+var reloadable = map[string]bool{
+	"LOG_LEVEL":                 true,
+	"CORS_ORIGINS":              true,
+	"ETH_NODE_URL":              true,
+	"ETH_NODE_API_KEY":          true,
+	"NODE_CONCURRENCY":          true,
+	"NODE_CONCURRENCY_PER_USER": true,
+	"NODE_MAX_PENDING":          true,
+}
+
+// Loader reads the configuration from a YAML file, the environment and flags. Each
+// source overrides the ones before, unset settings keep their defaults.
+type Loader struct {
+	file string
+	// flags are the raw values of the setting flags by environment variable, the
+	// secret file flags by the variable with the _FILE suffix
+	flags map[string]string
+}
+
+// NewLoader registers -config and a flag for every setting on fs. The flags are read
+// by Load after fs is parsed.
+func NewLoader(fs *flag.FlagSet) *Loader {
+	l := &Loader{flags: make(map[string]string)}
+
+	fs.StringVar(&l.file, "config", "", "YAML config file, CONFIG_FILE by default")
+	for _, s := range settings {
+		s := s
+		fs.Func(flagName(s.env), s.usage, func(value string) error {
+			err := parse(s.field(Default()), value)
+			if err != nil {
+				return err
+			}
+			l.flags[s.env] = value
+			return nil
+		})
+		if secrets[s.env] {
+			fs.Func(flagName(s.env+"_FILE"), "read "+s.env+" from a file", func(path string) error {
+				l.flags[s.env+"_FILE"] = path
+				return nil
+			})
+		}
+	}
+
+	return l
+}
+
+// File returns the config file, empty without one.
+func (l *Loader) File() string {
+	if l.file != "" {
+		return l.file
+	}
+	return os.Getenv("CONFIG_FILE")
+}
+
+// Load returns the validated configuration. It can be called again to reload the
+// config file, the environment and the secret files.
+func (l *Loader) Load() (*Config, error) {
+	return l.load((*Config).Validate)
+}
+
+// LoadDatabase returns the configuration with only the database settings validated,
+// for the commands that just use the database.
+func (l *Loader) LoadDatabase() (*Config, error) {
+	return l.load((*Config).ValidateDatabase)
+}
+
+func (l *Loader) load(validate func(*Config) error) (*Config, error) {
+	cfg := Default()
+
+	if file := l.File(); file != "" {
+		err := readFile(file, cfg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	err := apply(cfg, func(key string) (string, bool) {
+		value := os.Getenv(key)
+		return value, value != ""
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid environment: %w", err)
+	}
+
+	err = apply(cfg, func(key string) (string, bool) {
+		value, ok := l.flags[key]
+		return value, ok
+	})
+	if err != nil {
+		return nil, fmt.Errorf("invalid flags: %w", err)
+	}
+
+	err = validate(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("invalid config: %w", err)
+	}
+
+	return cfg, nil
+}
+
+// apply overrides cfg with the settings returned by lookup, secrets are read from the
+// files it names with the _FILE suffix.
+func apply(cfg *Config, lookup func(key string) (string, bool)) error {
+	var errs []error
+	for _, s := range settings {
+		value, ok := lookup(s.env)
+		if path, isFile := lookup(s.env + "_FILE"); isFile && secrets[s.env] {
+			if ok {
+				errs = append(errs, fmt.Errorf("%s and %s_FILE are both set", s.env, s.env))
+				continue
+			}
+			data, err := os.ReadFile(path)
+			if err != nil {
+				errs = append(errs, fmt.Errorf("%s_FILE: %w", s.env, err))
+				continue
+			}
+			value, ok = strings.TrimRight(string(data), "\r\n"), true
+		}
+		if !ok {
+			continue
+		}
+
+		err := parse(s.field(cfg), value)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", s.env, err))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// Changes returns the settings that differ between old and new, split into the
+// reloadable ones and the ones that need a restart. Values are not included as they
+// may be secret.
+func Changes(old, new *Config) (reload, restart []string) {
+	for _, s := range settings {
+		if reflect.DeepEqual(s.field(old), s.field(new)) {
+			continue
+		}
+		if reloadable[s.env] {
+			reload = append(reload, s.env)
+		} else {
+			restart = append(restart, s.env)
+		}
+	}
+
+	return reload, restart
+}
+
+// Reloaded returns a copy of running with the reloadable settings of next, the
+// settings that need a restart keep their running values.
+func Reloaded(running, next *Config) *Config {
+	reloaded := *running
+	for _, s := range settings {
+		if reloadable[s.env] {
+			reflect.ValueOf(s.field(&reloaded)).Elem().Set(reflect.ValueOf(s.field(next)).Elem())
+		}
+	}
+
+	return &reloaded
+}
+
+// readFile overrides cfg with the settings of the YAML file, unknown keys are errors.
+func readFile(path string, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	err = dec.Decode(cfg)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	return nil
+}
+
+func parse(field any, value string) error {
+	var err error
+	switch f := field.(type) {
+	case *string:
+		*f = value
+	case *int:
+		*f, err = strconv.Atoi(strings.TrimSpace(value))
+	case *bool:
+		*f, err = strconv.ParseBool(strings.TrimSpace(value))
+	case *time.Duration:
+		*f, err = time.ParseDuration(strings.TrimSpace(value))
+	case *[]string:
+		*f = nil
+		for _, item := range strings.Split(value, ",") {
+			if item = strings.TrimSpace(item); item != "" {
+				*f = append(*f, item)
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported setting type %T", field)
+	}
+
+	return err
+}
+
+func flagName(env string) string {
+	return strings.ReplaceAll(strings.ToLower(env), "_", "-")
+}
@@ -1,33 +1,146 @@
 package database
 
 import (
+	"database/sql"
+	"errors"
 	"eth-fetcher/database/models"
+	"fmt"
+	"math/big"
+	"strings"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
-	"github.com/ethereum/go-ethereum/log"
-	"golang.org/x/crypto/bcrypt"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/plugin/dbresolver"
 )
 
+const sqliteScheme = "sqlite://"
+
+// saveBatchSize is the number of transactions inserted with a single statement.
+const saveBatchSize = 500
+
 type Client struct {
 	db *gorm.DB
+	// resolver routes reads to the read replica, it is nil without one
+	resolver *dbresolver.DBResolver
 }
 
-// NewClient creates a new database client with the provided DSN.
-func NewClient(dsn string) (*Client, error) {
+// NewClient creates a new database client with the provided DSN. DSNs starting with
+// sqlite:// open an SQLite database file, all others are passed to PostgreSQL. The
+// schema must be migrated to the latest version, otherwise ErrSchemaVersion is returned.
+func NewClient(dsn string, opts ...Option) (*Client, error) {
+	c, err := Open(dsn, opts...)
+	if err != nil {
+		return nil, err
+	}
 
-	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	err = c.checkSchemaVersion()
 	if err != nil {
+		c.Close()
 		return nil, err
 	}
-	db.AutoMigrate(&models.Transaction{}, &models.User{})
-	c := &Client{db}
-	c.insertDefaultUsers()
 
 	return c, nil
 }
 
+// Open connects to the database without checking its schema, it is used to migrate it.
+func Open(dsn string, opts ...Option) (*Client, error) {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+
+	deadline := time.Now().Add(o.connectTimeout)
+	delay := connectRetryInitialDelay
+	for attempt := 1; ; attempt++ {
+		c, err := open(dsn, o)
+		if err == nil {
+			return c, nil
+		}
+		if o.log == nil || time.Now().Add(delay).After(deadline) {
+			return nil, err
+		}
+
+		o.log.Warnf("error connecting to the database (attempt %d), retrying in %s: %v", attempt, delay, err)
+		time.Sleep(delay)
+		delay = min(delay*2, connectRetryMaxDelay)
+	}
+}
+
+func open(dsn string, o *options) (*Client, error) {
+	db, err := gorm.Open(dialector(dsn), &gorm.Config{})
+	if err != nil {
+		closeDB(db)
+		return nil, err
+	}
+	c := &Client{db: db}
+
+	if o.replicaDSN != "" {
+		c.resolver = dbresolver.Register(dbresolver.Config{
+			Replicas: []gorm.Dialector{dialector(o.replicaDSN)},
+		})
+		err = db.Use(c.resolver)
+		if err != nil {
+			closeDB(db)
+			return nil, fmt.Errorf("error connecting to the read replica: %w", err)
+		}
+	}
+
+	err = c.eachPool(func(sqlDB *sql.DB) {
+		o.pool.apply(sqlDB)
+		if strings.HasPrefix(dsn, sqliteScheme) {
+			// SQLite allows a single writer, sharing one connection avoids busy errors
+			// and keeps :memory: databases alive across queries
+			sqlDB.SetMaxOpenConns(1)
+		}
+	})
+	if err != nil {
+		c.Close()
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// eachPool calls fn with the connection pool of the database and of its read replica.
+func (c *Client) eachPool(fn func(*sql.DB)) error {
+	primary, err := c.db.DB()
+	if err != nil {
+		return err
+	}
+	fn(primary)
+
+	if c.resolver == nil {
+		return nil
+	}
+
+	return c.resolver.Call(func(pool gorm.ConnPool) error {
+		if sqlDB, ok := pool.(*sql.DB); ok && sqlDB != primary {
+			fn(sqlDB)
+		}
+		return nil
+	})
+}
+
+func closeDB(db *gorm.DB) {
+	if db == nil {
+		return
+	}
+	if sqlDB, err := db.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
+
+func dialector(dsn string) gorm.Dialector {
+	if path, ok := strings.CutPrefix(dsn, sqliteScheme); ok {
+		return sqlite.Open(path)
+	}
+	return postgres.Open(dsn)
+}
+
 func NewTestClient() (*Client, sqlmock.Sqlmock, error) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -40,11 +153,20 @@ func NewTestClient() (*Client, sqlmock.Sqlmock, error) {
 		return nil, nil, err
 	}
 
-	return &Client{gormDB}, mock, nil
+	return &Client{db: gormDB}, mock, nil
 }
 
-func (c *Client) SaveTransaction(transaction *models.Transaction) error {
-	return c.db.Create(transaction).Error
+// SaveTransactions inserts the transactions in batches, the ones that already exist
+// are overwritten with the new values.
+func (c *Client) SaveTransactions(transactions []*models.Transaction) error {
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	return c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "tx_hash"}},
+		UpdateAll: true,
+	}).CreateInBatches(transactions, saveBatchSize).Error
 }
 
 func (c *Client) GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error) {
@@ -65,8 +187,157 @@ func (c *Client) GetAllTransactions() ([]*models.Transaction, error) {
 	return transactions, nil
 }
 
+// GetTransactionsPage returns up to limit transactions with a hash after the given
+// one, ordered by hash. An empty hash starts at the first page.
+func (c *Client) GetTransactionsPage(after string, limit int) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := c.db.Where("tx_hash > ?", after).Order("tx_hash").Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// addressFilter matches the transactions sent by, sent to or creating the address.
+const addressFilter = `"from" = @address OR "to" = @address OR contract_address = @address`
+
+// GetTransactionsByAddress returns up to limit transactions sent by, sent to or
+// creating the address after the cursor, ordered by block number and hash. The
+// address is expected in lowercase.
+func (c *Client) GetTransactionsByAddress(address string, after *models.TransactionCursor, limit int) ([]*models.Transaction, error) {
+	query := c.db.Where(addressFilter, sql.Named("address", address))
+	if after != nil {
+		query = query.Where("block_number > ? OR (block_number = ? AND tx_hash > ?)", after.BlockNumber, after.BlockNumber, after.TxHash)
+	}
+
+	var transactions []*models.Transaction
+	err := query.Order("block_number, tx_hash").Limit(limit).Find(&transactions).Error
+	if err != nil {
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// GetAddressStats summarizes the transactions sent by, sent to or creating the
+// address in a single aggregate query over the address indexes. The address is
+// expected in lowercase.
+func (c *Client) GetAddressStats(address string) (*models.AddressStats, error) {
+	// The values are summed in two parts that cannot overflow 64 bit integers,
+	// which SQLite sums in.
+	var row struct {
+		TransactionCount      int
+		SentCount             int
+		ReceivedCount         int
+		ContractCreationCount int
+		FirstSeenBlock        sql.NullInt64
+		LastSeenBlock         sql.NullInt64
+		ValueInHigh           sql.NullString
+		ValueInLow            sql.NullString
+		ValueOutHigh          sql.NullString
+		ValueOutLow           sql.NullString
+	}
+	err := c.db.Raw(`SELECT COUNT(*) AS transaction_count,
+			COUNT(CASE WHEN "from" = @address THEN 1 END) AS sent_count,
+			COUNT(CASE WHEN "to" = @address THEN 1 END) AS received_count,
+			COUNT(CASE WHEN contract_address = @address THEN 1 END) AS contract_creation_count,
+			MIN(block_number) AS first_seen_block,
+			MAX(block_number) AS last_seen_block,
+			SUM(CASE WHEN "to" = @address THEN value / @split END) AS value_in_high,
+			SUM(CASE WHEN "to" = @address THEN value % @split END) AS value_in_low,
+			SUM(CASE WHEN "from" = @address THEN value / @split END) AS value_out_high,
+			SUM(CASE WHEN "from" = @address THEN value % @split END) AS value_out_low
+		FROM transactions WHERE `+addressFilter,
+		sql.Named("address", address), sql.Named("split", valueSplit)).
+		Scan(&row).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats := models.NewAddressStats()
+	stats.TransactionCount = row.TransactionCount
+	stats.SentCount = row.SentCount
+	stats.ReceivedCount = row.ReceivedCount
+	stats.ContractCreationCount = row.ContractCreationCount
+	stats.FirstSeenBlock = row.FirstSeenBlock.Int64
+	stats.LastSeenBlock = row.LastSeenBlock.Int64
+	stats.TotalValueIn, err = joinValueSum(row.ValueInHigh, row.ValueInLow)
+	if err != nil {
+		return nil, err
+	}
+	stats.TotalValueOut, err = joinValueSum(row.ValueOutHigh, row.ValueOutLow)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+// valueSplit splits the values summed by GetAddressStats into a high and a low part.
+const valueSplit = 1_000_000_000
+
+// joinValueSum returns high * valueSplit + low of the sums of the value parts.
+func joinValueSum(high, low sql.NullString) (*big.Int, error) {
+	total := new(big.Int)
+	for _, part := range []struct {
+		sum    sql.NullString
+		factor int64
+	}{{high, valueSplit}, {low, 1}} {
+		if !part.sum.Valid {
+			continue
+		}
+		// Postgres returns the sums as numeric, e.g. 12 or 12.0000
+		digits, _, _ := strings.Cut(part.sum.String, ".")
+		n, ok := new(big.Int).SetString(digits, 10)
+		if !ok {
+			return nil, fmt.Errorf("malformed value sum %q", part.sum.String)
+		}
+		total.Add(total, n.Mul(n, big.NewInt(part.factor)))
+	}
+
+	return total, nil
+}
+
+// userView is a transaction in the history of a user, viewed last at ViewedAt.
+type userView struct {
+	UserID            string
+	TransactionTxHash string
+	ViewedAt          time.Time
+}
+
+func (userView) TableName() string {
+	return "user_viewed_transactions"
+}
+
+// AddUserTransactions stores the transactions that are not stored yet and adds them
+// to the history of the user. Transactions already in the history are marked as
+// viewed again.
 func (c *Client) AddUserTransactions(userID string, transactions []*models.Transaction) error {
-	return c.db.Model(&models.User{ID: userID}).Association("ViewedTransactions").Append(transactions)
+	if len(transactions) == 0 {
+		return nil
+	}
+
+	now := time.Now().UTC()
+	views := make([]userView, 0, len(transactions))
+	seen := make(map[string]bool, len(transactions))
+	for _, tx := range transactions {
+		if seen[tx.TxHash] {
+			continue
+		}
+		seen[tx.TxHash] = true
+		views = append(views, userView{UserID: userID, TransactionTxHash: tx.TxHash, ViewedAt: now})
+	}
+
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Clauses(clause.OnConflict{DoNothing: true}).CreateInBatches(transactions, saveBatchSize).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "user_id"}, {Name: "transaction_tx_hash"}},
+			DoUpdates: clause.AssignmentColumns([]string{"viewed_at"}),
+		}).CreateInBatches(views, saveBatchSize).Error
+	})
 }
 
 func (c *Client) GetUserTransactions(userID string) ([]*models.Transaction, error) {
@@ -78,12 +349,51 @@ func (c *Client) GetUserTransactions(userID string) ([]*models.Transaction, erro
 	return transactions, nil
 }
 
-func (c *Client) Close() error {
-	sqlDB, err := c.db.DB()
+// GetUserViews returns the history of the user with the time of the last view, oldest
+// first.
+func (c *Client) GetUserViews(userID string) ([]*models.TransactionView, error) {
+	var rows []userView
+	err := c.db.Where("user_id = ?", userID).Order("viewed_at, transaction_tx_hash").Find(&rows).Error
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	views := make([]*models.TransactionView, len(rows))
+	for i, row := range rows {
+		views[i] = &models.TransactionView{TxHash: row.TransactionTxHash, ViewedAt: row.ViewedAt}
+	}
+	return views, nil
+}
+
+// RestoreUserViews adds the stored transactions to the history of the user as viewed
+// at the given times. Views already in the history keep the later time.
+func (c *Client) RestoreUserViews(userID string, views []*models.TransactionView) error {
+	if len(views) == 0 {
+		return nil
+	}
+
+	rows := make([]userView, len(views))
+	for i, v := range views {
+		rows[i] = userView{UserID: userID, TransactionTxHash: v.TxHash, ViewedAt: v.ViewedAt.UTC()}
 	}
-	return sqlDB.Close()
+
+	return c.db.Clauses(clause.OnConflict{
+		Columns: []clause.Column{{Name: "user_id"}, {Name: "transaction_tx_hash"}},
+		DoUpdates: clause.Set{{
+			Column: clause.Column{Name: "viewed_at"},
+			Value:  gorm.Expr("CASE WHEN excluded.viewed_at > user_viewed_transactions.viewed_at THEN excluded.viewed_at ELSE user_viewed_transactions.viewed_at END"),
+		}},
+	}).CreateInBatches(rows, saveBatchSize).Error
+}
+
+// Close closes the connections to the database and to its read replica.
+func (c *Client) Close() error {
+	var errs []error
+	err := c.eachPool(func(sqlDB *sql.DB) {
+		errs = append(errs, sqlDB.Close())
+	})
+
+	return errors.Join(append(errs, err)...)
 }
 
 func (c *Client) GetUserByUsername(username string) (*models.User, error) {
@@ -96,25 +406,331 @@ func (c *Client) GetUserByUsername(username string) (*models.User, error) {
 	return user, nil
 }
 
-var defaultUserNames = []string{"alice", "bob", "carol", "dave"}
+// GetUserByExternalID returns the user of the subject of an external identity provider.
+// It reads from the primary so that users provisioned by other instances are found.
+func (c *Client) GetUserByExternalID(issuer, subject string) (*models.User, error) {
+	user := &models.User{}
+	err := c.db.Clauses(dbresolver.Write).Where("issuer = ? AND subject = ?", issuer, subject).First(user).Error
+	if err != nil {
+		return nil, err
+	}
 
-func (c *Client) insertDefaultUsers() {
-	for _, name := range defaultUserNames {
-		u := &models.User{}
-		result := c.db.Find(u, "username = ?", name)
-		if result.RowsAffected > 0 {
-			continue
-		}
+	return user, nil
+}
 
-		pass, err := bcrypt.GenerateFromPassword([]byte(name), bcrypt.DefaultCost)
-		if err != nil {
-			log.Error("generating password for %s:%w", name, err)
+// GetUserByID returns the user with the given ID. It reads from the primary, so that
+// the role and disabled flag checked on every request are never stale.
+func (c *Client) GetUserByID(userID string) (*models.User, error) {
+	user := &models.User{}
+	err := c.db.Clauses(dbresolver.Write).Where("id = ?", userID).First(user).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// GetUsers returns all users ordered by username.
+func (c *Client) GetUsers() ([]*models.User, error) {
+	var users []*models.User
+	err := c.db.Order("username").Find(&users).Error
+	if err != nil {
+		return nil, err
+	}
+	return users, nil
+}
 
+// CreateUser inserts the user with a new ID.
+func (c *Client) CreateUser(user *models.User) error {
+	return c.db.Create(user).Error
+}
+
+// UpdateUserPassword replaces the password hash of the user.
+func (c *Client) UpdateUserPassword(userID, password string) error {
+	result := c.db.Model(&models.User{}).Where("id = ?", userID).Update("password", password)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetUserRole replaces the role of the user.
+func (c *Client) SetUserRole(userID string, role models.Role) error {
+	result := c.db.Model(&models.User{}).Where("id = ?", userID).Update("role", role)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetUserDisabled disables or enables the user.
+func (c *Client) SetUserDisabled(userID string, disabled bool) error {
+	result := c.db.Model(&models.User{}).Where("id = ?", userID).Update("disabled", disabled)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteUser deletes the user together with its history, private labels, API keys and
+// refresh tokens.
+func (c *Client) DeleteUser(userID string) error {
+	return c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Where("user_id = ?", userID).Delete(&userView{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", userID).Delete(&models.AddressLabel{}).Error
+		if err != nil {
+			return err
+		}
+		err = tx.Where("user_id = ?", userID).Delete(&models.APIKey{}).Error
+		if err != nil {
+			return err
 		}
-		user := &models.User{Username: name, Password: string(pass)}
-		err = c.db.Create(user).Error
+		err = tx.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
 		if err != nil {
-			log.Error("crating default user %s:%w", name, err)
+			return err
 		}
+
+		result := tx.Where("id = ?", userID).Delete(&models.User{})
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return gorm.ErrRecordNotFound
+		}
+		return nil
+	})
+}
+
+// CreateAPIKey inserts the API key with a new ID.
+func (c *Client) CreateAPIKey(key *models.APIKey) error {
+	return c.db.Create(key).Error
+}
+
+// GetAPIKeys returns the API keys of the user, oldest first.
+func (c *Client) GetAPIKeys(userID string) ([]*models.APIKey, error) {
+	var keys []*models.APIKey
+	err := c.db.Where("user_id = ?", userID).Order("created_at, id").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// GetAPIKeyByHash returns the API key with the given hash. It reads from the primary
+// so that created and revoked keys take effect right away.
+func (c *Client) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	key := &models.APIKey{}
+	err := c.db.Clauses(dbresolver.Write).Where("hash = ?", hash).First(key).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// TouchAPIKey sets the time the API key was last used.
+func (c *Client) TouchAPIKey(id string, usedAt time.Time) error {
+	return c.db.Model(&models.APIKey{}).Where("id = ?", id).Update("last_used_at", usedAt).Error
+}
+
+// DeleteAPIKey deletes the API key of the user.
+func (c *Client) DeleteAPIKey(userID, id string) error {
+	result := c.db.Where("user_id = ? AND id = ?", userID, id).Delete(&models.APIKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// CreateRefreshToken inserts the refresh token with a new ID.
+func (c *Client) CreateRefreshToken(token *models.RefreshToken) error {
+	return c.db.Create(token).Error
+}
+
+// GetRefreshTokenByHash returns the refresh token with the given hash, read from the
+// primary.
+func (c *Client) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	token := &models.RefreshToken{}
+	err := c.db.Clauses(dbresolver.Write).Where("hash = ?", hash).First(token).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// UseRefreshToken marks the refresh token as exchanged. It fails if the token was
+// exchanged already, also by a concurrent call.
+func (c *Client) UseRefreshToken(id string, usedAt time.Time) error {
+	result := c.db.Model(&models.RefreshToken{}).Where("id = ? AND used_at IS NULL", id).Update("used_at", usedAt.UTC())
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// DeleteRefreshTokenFamily deletes all refresh tokens of the family.
+func (c *Client) DeleteRefreshTokenFamily(familyID string) error {
+	return c.db.Where("family_id = ?", familyID).Delete(&models.RefreshToken{}).Error
+}
+
+// DeleteUserRefreshTokens deletes all refresh tokens of the user.
+func (c *Client) DeleteUserRefreshTokens(userID string) error {
+	return c.db.Where("user_id = ?", userID).Delete(&models.RefreshToken{}).Error
+}
+
+// RevokeToken adds the access token to the revocation list, revoking it twice is a
+// no-op.
+func (c *Client) RevokeToken(token *models.RevokedToken) error {
+	return c.db.Clauses(clause.OnConflict{DoNothing: true}).Create(token).Error
+}
+
+// IsTokenRevoked reports whether the access token is on the revocation list, read
+// from the primary.
+func (c *Client) IsTokenRevoked(id string) (bool, error) {
+	var count int64
+	err := c.db.Clauses(dbresolver.Write).Model(&models.RevokedToken{}).Where("id = ?", id).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+	return count > 0, nil
+}
+
+// PruneTokens deletes the refresh tokens and revoked access tokens that expired before
+// now and returns how many.
+func (c *Client) PruneTokens(now time.Time) (int64, error) {
+	var pruned int64
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		result := tx.Where("expires_at < ?", now.UTC()).Delete(&models.RefreshToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		pruned += result.RowsAffected
+
+		result = tx.Where("expires_at < ?", now.UTC()).Delete(&models.RevokedToken{})
+		if result.Error != nil {
+			return result.Error
+		}
+		pruned += result.RowsAffected
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+// CreateSigningKey inserts the signing key with a new ID.
+func (c *Client) CreateSigningKey(key *models.SigningKey) error {
+	return c.db.Create(key).Error
+}
+
+// GetSigningKeys returns all signing keys, oldest first. It reads from the primary so
+// that keys created by other instances are picked up right away.
+func (c *Client) GetSigningKeys() ([]*models.SigningKey, error) {
+	var keys []*models.SigningKey
+	err := c.db.Clauses(dbresolver.Write).Order("created_at, id").Find(&keys).Error
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+func (c *Client) DeleteSigningKey(id string) error {
+	result := c.db.Where("id = ?", id).Delete(&models.SigningKey{})
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SaveAddressLabels inserts the labels, renaming the ones that already exist for
+// the same address and owner.
+func (c *Client) SaveAddressLabels(labels []*models.AddressLabel) error {
+	if len(labels) == 0 {
+		return nil
+	}
+
+	return c.db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "address"}, {Name: "user_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"name"}),
+	}).Create(labels).Error
+}
+
+// GetAddressLabels returns the global labels together with the private labels of the user.
+func (c *Client) GetAddressLabels(userID string) ([]*models.AddressLabel, error) {
+	var labels []*models.AddressLabel
+	err := c.db.Where("user_id IN ?", []string{"", userID}).Order("address").Find(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+// FindAddressLabels returns the global and the user's private labels for the given addresses.
+func (c *Client) FindAddressLabels(userID string, addresses []string) ([]*models.AddressLabel, error) {
+	var labels []*models.AddressLabel
+	err := c.db.Where("address IN ? AND user_id IN ?", addresses, []string{"", userID}).Find(&labels).Error
+	if err != nil {
+		return nil, err
+	}
+	return labels, nil
+}
+
+func (c *Client) DeleteAddressLabel(userID, address string) error {
+	return c.db.Where("address = ? AND user_id = ?", address, userID).Delete(&models.AddressLabel{}).Error
+}
+
+// SaveBackfillJob inserts a new backfill job or updates an existing one.
+func (c *Client) SaveBackfillJob(job *models.BackfillJob) error {
+	return c.db.Save(job).Error
+}
+
+// GetBackfillJob returns the backfill job with the given ID. It reads from the primary,
+// so that a job is resumed from its last saved progress.
+func (c *Client) GetBackfillJob(id string) (*models.BackfillJob, error) {
+	job := &models.BackfillJob{}
+	err := c.db.Clauses(dbresolver.Write).Where("id = ?", id).First(job).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// GetBackfillJobs returns the backfill jobs with the given status, or all jobs
+// when status is empty, newest first.
+func (c *Client) GetBackfillJobs(status string) ([]*models.BackfillJob, error) {
+	var jobs []*models.BackfillJob
+	query := c.db.Order("created_at DESC")
+	if status != "" {
+		query = query.Where("status = ?", status)
+	}
+	err := query.Find(&jobs).Error
+	if err != nil {
+		return nil, err
 	}
+	return jobs, nil
 }
@@ -11,7 +11,28 @@ import (
 	"gorm.io/gorm"
 )
 
-func TestClient_SaveTransaction(t *testing.T) {
+func TestClient_SaveTransactions(t *testing.T) {
+	client, mock, err := database.NewTestClient()
+	assert.NoError(t, err)
+	defer client.Close()
+
+	transactions := []*models.Transaction{
+		{TxHash: "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", BlockNumber: 7976373},
+		{TxHash: "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", BlockNumber: 7976374},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`INSERT INTO "transactions" (.+) VALUES (.+),(.+) ON CONFLICT \("tx_hash"\) DO UPDATE SET (.+)`).
+		WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectCommit()
+
+	err = client.SaveTransactions(transactions)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClient_SaveTransactions_LowercaseAddresses(t *testing.T) {
 	client, mock, err := database.NewTestClient()
 	assert.NoError(t, err)
 	defer client.Close()
@@ -30,14 +51,15 @@ func TestClient_SaveTransaction(t *testing.T) {
 		Value:           50000000000000000,
 	}
 
+	// Addresses are stored in lowercase
 	mock.ExpectBegin()
 	mock.ExpectExec("INSERT INTO \"transactions\" (.+)").
 		WithArgs(transaction.TxHash,
 			transaction.TxStatus,
 			transaction.BlockHash,
 			transaction.BlockNumber,
-			transaction.From,
-			transaction.To,
+			"0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767",
+			null.NewString("0xb0428bf0d49eb5c2239a815b43e59e124b84e303", true),
 			transaction.ContractAddress,
 			transaction.LogsCount,
 			transaction.Input,
@@ -45,7 +67,7 @@ func TestClient_SaveTransaction(t *testing.T) {
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
-	err = client.SaveTransaction(transaction)
+	err = client.SaveTransactions([]*models.Transaction{transaction})
 	assert.NoError(t, err)
 
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -211,16 +233,16 @@ func TestClient_AddUserTransactions(t *testing.T) {
 			transactions[0].TxStatus,
 			transactions[0].BlockHash,
 			transactions[0].BlockNumber,
-			transactions[0].From,
-			transactions[0].To,
+			"0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767",
+			null.NewString("0xb0428bf0d49eb5c2239a815b43e59e124b84e303", true),
 			transactions[0].ContractAddress,
 			transactions[0].LogsCount,
 			transactions[0].Input,
 			transactions[0].Value).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 
-	mock.ExpectExec("INSERT INTO \"user_viewed_transactions\" (.+)").
-		WithArgs("1", transactions[0].TxHash).
+	mock.ExpectExec("INSERT INTO \"user_viewed_transactions\" (.+) ON CONFLICT \\(\"user_id\",\"transaction_tx_hash\"\\) DO UPDATE SET \"viewed_at\"").
+		WithArgs("1", transactions[0].TxHash, sqlmock.AnyArg()).
 		WillReturnResult(sqlmock.NewResult(1, 1))
 	mock.ExpectCommit()
 
@@ -229,3 +251,89 @@ func TestClient_AddUserTransactions(t *testing.T) {
 
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestClient_SaveAddressLabels(t *testing.T) {
+	client, mock, err := database.NewTestClient()
+	assert.NoError(t, err)
+	defer client.Close()
+
+	labels := []*models.AddressLabel{
+		{Address: "0x28c6c06298d514db089934071355e5743bf21d60", Name: "Binance 14"},
+		{Address: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", Name: "router", UserID: "1"},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectExec("INSERT INTO \"address_labels\" (.+) ON CONFLICT \\(\"address\",\"user_id\"\\) DO UPDATE SET \"name\"=\"excluded\".\"name\"").
+		WithArgs(labels[0].Address, labels[0].UserID, labels[0].Name,
+			labels[1].Address, labels[1].UserID, labels[1].Name).
+		WillReturnResult(sqlmock.NewResult(2, 2))
+	mock.ExpectCommit()
+
+	err = client.SaveAddressLabels(labels)
+	assert.NoError(t, err)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClient_FindAddressLabels(t *testing.T) {
+	client, mock, err := database.NewTestClient()
+	assert.NoError(t, err)
+	defer client.Close()
+
+	rows := sqlmock.NewRows([]string{"address", "user_id", "name"}).
+		AddRow("0x28c6c06298d514db089934071355e5743bf21d60", "", "Binance 14").
+		AddRow("0x7a250d5630b4cf539739df2c5dacb4c659f2488d", "1", "router")
+
+	mock.ExpectQuery("SELECT (.+) FROM \"address_labels\" WHERE address IN (.+) AND user_id IN (.+)").
+		WithArgs("0x28c6c06298d514db089934071355e5743bf21d60", "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", "", "1").
+		WillReturnRows(rows)
+
+	labels, err := client.FindAddressLabels("1", []string{"0x28c6c06298d514db089934071355e5743bf21d60", "0x7a250d5630b4cf539739df2c5dacb4c659f2488d"})
+	assert.NoError(t, err)
+	assert.Len(t, labels, 2)
+	assert.Equal(t, "Binance 14", labels[0].Name)
+	assert.Equal(t, "1", labels[1].UserID)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClient_GetTransactionsByAddress(t *testing.T) {
+	client, mock, err := database.NewTestClient()
+	assert.NoError(t, err)
+	defer client.Close()
+
+	address := "0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767"
+	rows := sqlmock.NewRows([]string{"tx_hash", "block_number", "from"}).
+		AddRow("0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", 7976373, "0xF29A6c0f8eE500dC87d0d4EB8B26a6faC7A76767")
+
+	mock.ExpectQuery("SELECT (.+) FROM \"transactions\" WHERE \\(\"from\" = (.+) OR \"to\" = (.+) OR contract_address = (.+)\\) AND \\(block_number > (.+) OR \\(block_number = (.+) AND tx_hash > (.+)\\)\\) ORDER BY block_number, tx_hash LIMIT 10").
+		WithArgs(address, address, address, 7976000, 7976000, "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524").
+		WillReturnRows(rows)
+
+	after := &models.TransactionCursor{BlockNumber: 7976000, TxHash: "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}
+	transactions, err := client.GetTransactionsByAddress(address, after, 10)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestClient_GetBackfillJobs(t *testing.T) {
+	client, mock, err := database.NewTestClient()
+	assert.NoError(t, err)
+	defer client.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "from_block", "to_block", "checkpoint", "status"}).
+		AddRow("job1", 100, 199, 150, models.BackfillStatusRunning)
+
+	mock.ExpectQuery("SELECT (.+) FROM \"backfill_jobs\" WHERE status = (.+) ORDER BY created_at DESC").
+		WithArgs(models.BackfillStatusRunning).
+		WillReturnRows(rows)
+
+	jobs, err := client.GetBackfillJobs(models.BackfillStatusRunning)
+	assert.NoError(t, err)
+	assert.Len(t, jobs, 1)
+	assert.Equal(t, int64(150), jobs[0].Checkpoint)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
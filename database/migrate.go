@@ -0,0 +1,249 @@
+package database
+
+import (
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// ErrSchemaVersion is returned when the database schema does not match the migrations
+// of this build.
+var ErrSchemaVersion = errors.New("unexpected schema version")
+
+//go:embed migrations
+var migrationFiles embed.FS
+
+var migrationFileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// Migration is a versioned schema change with the scripts to apply and to revert it.
+type Migration struct {
+	Version int
+	Name    string
+	Up      string
+	Down    string
+}
+
+// schemaMigration records an applied migration in the schema_migrations table.
+type schemaMigration struct {
+	Version   int `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (schemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// Migrations returns the migrations for the SQL dialect of the database, ordered by version.
+func (c *Client) Migrations() ([]Migration, error) {
+	return loadMigrations(c.db.Dialector.Name())
+}
+
+// SchemaVersion returns the version of the last applied migration, 0 for a database
+// without migrations.
+func (c *Client) SchemaVersion() (int, error) {
+	if !c.db.Migrator().HasTable(&schemaMigration{}) {
+		return 0, nil
+	}
+
+	var version int
+	err := c.db.Model(&schemaMigration{}).Select("COALESCE(MAX(version), 0)").Scan(&version).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return version, nil
+}
+
+// LatestSchemaVersion returns the version the database is at after all migrations.
+func (c *Client) LatestSchemaVersion() (int, error) {
+	migrations, err := c.Migrations()
+	if err != nil {
+		return 0, err
+	}
+
+	return len(migrations), nil
+}
+
+// MigrateUp applies all pending migrations.
+func (c *Client) MigrateUp() error {
+	latest, err := c.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	return c.Migrate(latest)
+}
+
+// MigrateDown reverts the last steps migrations.
+func (c *Client) MigrateDown(steps int) error {
+	version, err := c.SchemaVersion()
+	if err != nil {
+		return err
+	}
+
+	return c.Migrate(max(version-steps, 0))
+}
+
+// Migrate applies or reverts migrations until the schema is at the target version.
+// Every migration runs in its own transaction together with the update of its
+// schema_migrations row.
+func (c *Client) Migrate(target int) error {
+	migrations, err := c.Migrations()
+	if err != nil {
+		return err
+	}
+	if target < 0 || target > len(migrations) {
+		return fmt.Errorf("unknown schema version %d, the latest is %d", target, len(migrations))
+	}
+
+	if !c.db.Migrator().HasTable(&schemaMigration{}) {
+		err = c.db.Migrator().CreateTable(&schemaMigration{})
+		if err != nil {
+			return fmt.Errorf("error creating schema_migrations: %w", err)
+		}
+	}
+
+	version, err := c.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version > len(migrations) {
+		return fmt.Errorf("%w: database is at version %d, this build only knows %d", ErrSchemaVersion, version, len(migrations))
+	}
+
+	for ; version < target; version++ {
+		m := migrations[version]
+		err = c.db.Transaction(func(tx *gorm.DB) error {
+			err := execScript(tx, m.Up)
+			if err != nil {
+				return err
+			}
+			return tx.Create(&schemaMigration{Version: m.Version, Name: m.Name, AppliedAt: time.Now()}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("error applying migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	for ; version > target; version-- {
+		m := migrations[version-1]
+		err = c.db.Transaction(func(tx *gorm.DB) error {
+			err := execScript(tx, m.Down)
+			if err != nil {
+				return err
+			}
+			return tx.Delete(&schemaMigration{Version: m.Version}).Error
+		})
+		if err != nil {
+			return fmt.Errorf("error reverting migration %d_%s: %w", m.Version, m.Name, err)
+		}
+	}
+
+	return nil
+}
+
+// checkSchemaVersion fails unless all migrations of this build have been applied.
+func (c *Client) checkSchemaVersion() error {
+	version, err := c.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	latest, err := c.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	if version != latest {
+		return fmt.Errorf("%w: database is at version %d, expected %d, run the migrate command", ErrSchemaVersion, version, latest)
+	}
+
+	return nil
+}
+
+// execScript runs the statements of a script one by one, statements end with a
+// semicolon at the end of a line.
+func execScript(tx *gorm.DB, script string) error {
+	var lines []string
+	for _, line := range strings.Split(script, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "--") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+
+	for _, statement := range strings.Split(strings.Join(lines, "\n"), ";\n") {
+		statement = strings.TrimSuffix(strings.TrimSpace(statement), ";")
+		if statement == "" {
+			continue
+		}
+		err := tx.Exec(statement).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadMigrations reads the embedded migrations of the dialect. Versions start at 1
+// without gaps and every migration has an up and a down script.
+func loadMigrations(dialect string) ([]Migration, error) {
+	dir := path.Join("migrations", dialect)
+	entries, err := fs.ReadDir(migrationFiles, dir)
+	if err != nil {
+		return nil, fmt.Errorf("no migrations for %s: %w", dialect, err)
+	}
+
+	byVersion := make(map[int]*Migration)
+	for _, entry := range entries {
+		match := migrationFileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			return nil, fmt.Errorf("invalid migration file name %s", entry.Name())
+		}
+		version, _ := strconv.Atoi(match[1])
+
+		script, err := fs.ReadFile(migrationFiles, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: match[2]}
+			byVersion[version] = m
+		}
+		if match[3] == "up" {
+			m.Up = string(script)
+		} else {
+			m.Down = string(script)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, m := range byVersion {
+		migrations = append(migrations, *m)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	for i, m := range migrations {
+		if m.Version != i+1 {
+			return nil, fmt.Errorf("missing migration %d", i+1)
+		}
+		if m.Up == "" || m.Down == "" {
+			return nil, fmt.Errorf("migration %d_%s needs an up and a down script", m.Version, m.Name)
+		}
+	}
+
+	return migrations, nil
+}
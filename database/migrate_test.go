@@ -0,0 +1,153 @@
+package database_test
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/glebarez/sqlite"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"eth-fetcher/database"
+	"eth-fetcher/database/models"
+)
+
+func TestClient_Migrate(t *testing.T) {
+	dsn := "sqlite://" + filepath.Join(t.TempDir(), "eth-fetcher.db")
+
+	client, err := database.Open(dsn)
+	require.NoError(t, err)
+	defer client.Close()
+
+	version, err := client.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	// The application refuses to start on an unmigrated database
+	_, err = database.NewClient(dsn)
+	assert.ErrorIs(t, err, database.ErrSchemaVersion)
+
+	err = client.MigrateUp()
+	require.NoError(t, err)
+
+	latest, err := client.LatestSchemaVersion()
+	assert.NoError(t, err)
+	version, err = client.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, latest, version)
+
+	migrated, err := database.NewClient(dsn)
+	require.NoError(t, err)
+	migrated.Close()
+
+	// Applying the migrations again is a no-op
+	assert.NoError(t, client.MigrateUp())
+
+	err = client.MigrateDown(latest)
+	require.NoError(t, err)
+
+	version, err = client.SchemaVersion()
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	_, err = database.NewClient(dsn)
+	assert.ErrorIs(t, err, database.ErrSchemaVersion)
+
+	assert.Error(t, client.Migrate(latest+1))
+}
+
+// legacyUser is models.User of the versions before the migrations.
+type legacyUser struct {
+	ID                 string `gorm:"primaryKey"`
+	Username           string `gorm:"unique"`
+	Password           string
+	Admin              bool
+	ViewedTransactions []models.Transaction `gorm:"many2many:user_viewed_transactions;joinForeignKey:UserID"`
+}
+
+func (legacyUser) TableName() string {
+	return "users"
+}
+
+// legacyBackfillJob is models.BackfillJob of the versions before the migrations.
+type legacyBackfillJob struct {
+	ID           string `gorm:"primaryKey"`
+	FromBlock    int64
+	ToBlock      int64
+	Checkpoint   int64
+	Transactions int64
+	Status       string `gorm:"index"`
+	Error        string
+	CreatedAt    time.Time
+	UpdatedAt    time.Time
+}
+
+func (legacyBackfillJob) TableName() string {
+	return "backfill_jobs"
+}
+
+func TestClient_Migrate_AutoMigratedDatabase(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eth-fetcher.db")
+
+	// Create the schema the way older versions did
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.AutoMigrate(&models.Transaction{}, &legacyUser{}, &models.AddressLabel{}, &legacyBackfillJob{})
+	require.NoError(t, err)
+	err = db.Create(&models.Transaction{TxHash: "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", BlockNumber: 7976373}).Error
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.Close()
+
+	// The migrations adopt the existing tables and keep their data
+	client, err := database.Open("sqlite://" + path)
+	require.NoError(t, err)
+	err = client.MigrateUp()
+	assert.NoError(t, err)
+	client.Close()
+
+	client, err = database.NewClient("sqlite://" + path)
+	require.NoError(t, err)
+	defer client.Close()
+
+	transactions, err := client.GetTransactionsByHashes([]string{"0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+}
+
+func TestClient_Migrate_LowercaseAddresses(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "eth-fetcher.db")
+
+	client, err := database.Open("sqlite://" + path)
+	require.NoError(t, err)
+	require.NoError(t, client.Migrate(8))
+	client.Close()
+
+	// Older versions stored the addresses as returned by the node
+	db, err := gorm.Open(sqlite.Open(path), &gorm.Config{})
+	require.NoError(t, err)
+	err = db.Exec(`INSERT INTO "transactions" ("tx_hash", "block_number", "from", "to") VALUES (?, ?, ?, ?)`,
+		"0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", 7976373,
+		"0xF29A6c0f8eE500dC87d0d4EB8B26a6faC7A76767", "0xb0428bF0D49eB5c2239A815B43E59E124b84E303").Error
+	require.NoError(t, err)
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.Close()
+
+	client, err = database.Open("sqlite://" + path)
+	require.NoError(t, err)
+	require.NoError(t, client.MigrateUp())
+	client.Close()
+
+	client, err = database.NewClient("sqlite://" + path)
+	require.NoError(t, err)
+	defer client.Close()
+
+	transactions, err := client.GetTransactionsByAddress("0xb0428bf0d49eb5c2239a815b43e59e124b84e303", nil, 10)
+	assert.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, "0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767", transactions[0].From)
+}
@@ -0,0 +1,4 @@
+DROP INDEX IF EXISTS "idx_user_viewed_transactions_transaction";
+DROP INDEX IF EXISTS "idx_transactions_fetched_at";
+ALTER TABLE "user_viewed_transactions" DROP COLUMN IF EXISTS "viewed_at";
+ALTER TABLE "transactions" DROP COLUMN IF EXISTS "fetched_at";
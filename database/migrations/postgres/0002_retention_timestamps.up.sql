@@ -0,0 +1,10 @@
+-- fetched_at and viewed_at decide which transactions the retention job prunes. They are
+-- filled by the database and not part of the models. Existing rows count as fetched
+-- and viewed now.
+ALTER TABLE "transactions" ADD COLUMN IF NOT EXISTS "fetched_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+ALTER TABLE "user_viewed_transactions" ADD COLUMN IF NOT EXISTS "viewed_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP;
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_fetched_at" ON "transactions" ("fetched_at");
+
+CREATE INDEX IF NOT EXISTS "idx_user_viewed_transactions_transaction" ON "user_viewed_transactions" ("transaction_tx_hash", "viewed_at");
@@ -0,0 +1,6 @@
+-- role replaces the admin flag, the other users become readers.
+ALTER TABLE "users" ADD COLUMN IF NOT EXISTS "role" text NOT NULL DEFAULT 'reader';
+
+UPDATE "users" SET "role" = 'admin' WHERE "admin";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "admin";
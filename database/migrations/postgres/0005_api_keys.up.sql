@@ -0,0 +1,18 @@
+-- API keys of machine clients, only the SHA-256 hash of a key is stored.
+CREATE TABLE IF NOT EXISTS "api_keys" (
+    "id" text,
+    "user_id" text NOT NULL,
+    "name" text NOT NULL,
+    "prefix" text NOT NULL,
+    "hash" text NOT NULL,
+    "scopes" text,
+    "expires_at" timestamptz,
+    "last_used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_api_keys_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_api_keys_hash" ON "api_keys" ("hash");
+
+CREATE INDEX IF NOT EXISTS "idx_api_keys_user_id" ON "api_keys" ("user_id");
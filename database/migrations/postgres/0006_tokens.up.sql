@@ -0,0 +1,28 @@
+-- Refresh tokens, stored hashed, and the access tokens revoked before they expire.
+CREATE TABLE IF NOT EXISTS "refresh_tokens" (
+    "id" text,
+    "user_id" text NOT NULL,
+    "family_id" text NOT NULL,
+    "hash" text NOT NULL,
+    "expires_at" timestamptz NOT NULL,
+    "used_at" timestamptz,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id"),
+    CONSTRAINT "fk_refresh_tokens_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id")
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_refresh_tokens_hash" ON "refresh_tokens" ("hash");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_user_id" ON "refresh_tokens" ("user_id");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_family_id" ON "refresh_tokens" ("family_id");
+
+CREATE INDEX IF NOT EXISTS "idx_refresh_tokens_expires_at" ON "refresh_tokens" ("expires_at");
+
+CREATE TABLE IF NOT EXISTS "revoked_tokens" (
+    "id" text,
+    "expires_at" timestamptz NOT NULL,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_revoked_tokens_expires_at" ON "revoked_tokens" ("expires_at");
@@ -0,0 +1,11 @@
+-- Key pairs signing the access tokens, the private keys are encrypted.
+CREATE TABLE IF NOT EXISTS "signing_keys" (
+    "id" text,
+    "algorithm" text NOT NULL,
+    "public_key" bytea NOT NULL,
+    "private_key" bytea NOT NULL,
+    "created_at" timestamptz NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_signing_keys_created_at" ON "signing_keys" ("created_at");
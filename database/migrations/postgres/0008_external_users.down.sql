@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "idx_users_external";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "subject";
+
+ALTER TABLE "users" DROP COLUMN IF EXISTS "issuer";
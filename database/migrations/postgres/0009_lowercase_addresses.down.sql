@@ -0,0 +1,6 @@
+-- the addresses stay in lowercase, the lookups match them either way.
+DROP INDEX IF EXISTS "idx_transactions_contract_address";
+
+DROP INDEX IF EXISTS "idx_transactions_to";
+
+DROP INDEX IF EXISTS "idx_transactions_from";
@@ -0,0 +1,9 @@
+-- addresses are stored in lowercase so they are looked up with the indexes instead
+-- of comparing LOWER() of every row.
+UPDATE "transactions" SET "from" = LOWER("from"), "to" = LOWER("to"), "contract_address" = LOWER("contract_address");
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_from" ON "transactions" ("from");
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_to" ON "transactions" ("to");
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_contract_address" ON "transactions" ("contract_address");
@@ -0,0 +1,3 @@
+ALTER TABLE "backfill_jobs" DROP COLUMN IF EXISTS "address";
+
+ALTER TABLE "backfill_jobs" DROP COLUMN IF EXISTS "user_id";
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS "backfill_jobs";
+DROP TABLE IF EXISTS "address_labels";
+DROP TABLE IF EXISTS "user_viewed_transactions";
+DROP TABLE IF EXISTS "users";
+DROP TABLE IF EXISTS "transactions";
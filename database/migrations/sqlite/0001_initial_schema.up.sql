@@ -0,0 +1,53 @@
+-- The schema previously created by AutoMigrate. IF NOT EXISTS lets databases created
+-- by older versions adopt the migrations.
+CREATE TABLE IF NOT EXISTS "transactions" (
+    "tx_hash" text,
+    "tx_status" integer,
+    "block_hash" text,
+    "block_number" integer,
+    "from" text,
+    "to" text,
+    "contract_address" text,
+    "logs_count" integer,
+    "input" text,
+    "value" integer,
+    PRIMARY KEY ("tx_hash")
+);
+
+CREATE TABLE IF NOT EXISTS "users" (
+    "id" text,
+    "username" text UNIQUE,
+    "password" text,
+    "admin" numeric,
+    PRIMARY KEY ("id")
+);
+
+CREATE TABLE IF NOT EXISTS "user_viewed_transactions" (
+    "user_id" text,
+    "transaction_tx_hash" text,
+    PRIMARY KEY ("user_id", "transaction_tx_hash"),
+    CONSTRAINT "fk_user_viewed_transactions_user" FOREIGN KEY ("user_id") REFERENCES "users" ("id"),
+    CONSTRAINT "fk_user_viewed_transactions_transaction" FOREIGN KEY ("transaction_tx_hash") REFERENCES "transactions" ("tx_hash")
+);
+
+CREATE TABLE IF NOT EXISTS "address_labels" (
+    "address" text,
+    "user_id" text,
+    "name" text,
+    PRIMARY KEY ("address", "user_id")
+);
+
+CREATE TABLE IF NOT EXISTS "backfill_jobs" (
+    "id" text,
+    "from_block" integer,
+    "to_block" integer,
+    "checkpoint" integer,
+    "transactions" integer,
+    "status" text,
+    "error" text,
+    "created_at" datetime,
+    "updated_at" datetime,
+    PRIMARY KEY ("id")
+);
+
+CREATE INDEX IF NOT EXISTS "idx_backfill_jobs_status" ON "backfill_jobs" ("status");
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "idx_user_viewed_transactions_transaction";
+DROP INDEX IF EXISTS "idx_transactions_fetched_at";
+DROP TRIGGER IF EXISTS "trg_transactions_fetched_at";
+ALTER TABLE "user_viewed_transactions" DROP COLUMN "viewed_at";
+ALTER TABLE "transactions" DROP COLUMN "fetched_at";
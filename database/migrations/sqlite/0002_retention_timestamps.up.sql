@@ -0,0 +1,18 @@
+-- fetched_at and viewed_at decide which transactions the retention job prunes. They are
+-- filled by the database and not part of the models. Existing rows count as fetched
+-- and viewed now. SQLite cannot add a column defaulting to the current time, a
+-- trigger fills fetched_at instead. Times are stored in the format of the Go driver
+-- so they compare correctly as text.
+ALTER TABLE "transactions" ADD COLUMN "fetched_at" datetime;
+
+UPDATE "transactions" SET "fetched_at" = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+
+CREATE TRIGGER IF NOT EXISTS "trg_transactions_fetched_at" AFTER INSERT ON "transactions" FOR EACH ROW WHEN NEW."fetched_at" IS NULL BEGIN UPDATE "transactions" SET "fetched_at" = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now') WHERE "tx_hash" = NEW."tx_hash"; END;
+
+ALTER TABLE "user_viewed_transactions" ADD COLUMN "viewed_at" datetime;
+
+UPDATE "user_viewed_transactions" SET "viewed_at" = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
+
+CREATE INDEX IF NOT EXISTS "idx_transactions_fetched_at" ON "transactions" ("fetched_at");
+
+CREATE INDEX IF NOT EXISTS "idx_user_viewed_transactions_transaction" ON "user_viewed_transactions" ("transaction_tx_hash", "viewed_at");
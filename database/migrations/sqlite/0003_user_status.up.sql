@@ -0,0 +1,7 @@
+-- disabled users cannot log in. Existing users count as created now, in the time
+-- format of the Go driver.
+ALTER TABLE "users" ADD COLUMN "disabled" numeric NOT NULL DEFAULT false;
+
+ALTER TABLE "users" ADD COLUMN "created_at" datetime;
+
+UPDATE "users" SET "created_at" = strftime('%Y-%m-%d %H:%M:%f+00:00', 'now');
@@ -0,0 +1,3 @@
+ALTER TABLE "users" ADD COLUMN "admin" numeric NOT NULL DEFAULT false;
+UPDATE "users" SET "admin" = ("role" = 'admin');
+ALTER TABLE "users" DROP COLUMN "role";
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS "idx_users_external";
+
+ALTER TABLE "users" DROP COLUMN "subject";
+
+ALTER TABLE "users" DROP COLUMN "issuer";
@@ -0,0 +1,7 @@
+-- users of an external identity provider are identified by the issuer and the
+-- subject of their tokens.
+ALTER TABLE "users" ADD COLUMN "issuer" text;
+
+ALTER TABLE "users" ADD COLUMN "subject" text;
+
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_users_external" ON "users" ("issuer", "subject");
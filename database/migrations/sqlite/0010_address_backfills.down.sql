@@ -0,0 +1,3 @@
+ALTER TABLE "backfill_jobs" DROP COLUMN "address";
+
+ALTER TABLE "backfill_jobs" DROP COLUMN "user_id";
@@ -0,0 +1,5 @@
+-- address backfills are backfill jobs storing only the transactions of the address,
+-- they are visible to the user that started them.
+ALTER TABLE "backfill_jobs" ADD COLUMN "user_id" text;
+
+ALTER TABLE "backfill_jobs" ADD COLUMN "address" text;
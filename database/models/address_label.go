@@ -0,0 +1,9 @@
+package models
+
+// AddressLabel is a human readable name attached to an address. Labels with an
+// empty UserID are global, the rest are private to the user that owns them.
+type AddressLabel struct {
+	Address string `gorm:"primaryKey" json:"address"`
+	UserID  string `gorm:"primaryKey" json:"userId,omitempty"`
+	Name    string `json:"name"`
+}
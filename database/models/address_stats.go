@@ -0,0 +1,52 @@
+package models
+
+import "math/big"
+
+// TransactionCursor is the position of a transaction in the block order, a page of
+// transactions starts after it.
+type TransactionCursor struct {
+	BlockNumber int64
+	TxHash      string
+}
+
+// AddressStats summarizes the stored transactions of an address.
+type AddressStats struct {
+	TransactionCount      int
+	SentCount             int
+	ReceivedCount         int
+	ContractCreationCount int
+	FirstSeenBlock        int64
+	LastSeenBlock         int64
+	TotalValueIn          *big.Int
+	TotalValueOut         *big.Int
+}
+
+// NewAddressStats returns the stats of an address without transactions.
+func NewAddressStats() *AddressStats {
+	return &AddressStats{TotalValueIn: new(big.Int), TotalValueOut: new(big.Int)}
+}
+
+// Add counts a transaction of the address, the address and the transaction are
+// expected in lowercase.
+func (s *AddressStats) Add(tx *Transaction, address string) {
+	if s.TransactionCount == 0 || tx.BlockNumber < s.FirstSeenBlock {
+		s.FirstSeenBlock = tx.BlockNumber
+	}
+	if tx.BlockNumber > s.LastSeenBlock {
+		s.LastSeenBlock = tx.BlockNumber
+	}
+	s.TransactionCount++
+
+	value := big.NewInt(tx.Value)
+	if tx.From == address {
+		s.SentCount++
+		s.TotalValueOut.Add(s.TotalValueOut, value)
+	}
+	if tx.To.Valid && tx.To.String == address {
+		s.ReceivedCount++
+		s.TotalValueIn.Add(s.TotalValueIn, value)
+	}
+	if tx.ContractAddress.Valid && tx.ContractAddress.String == address {
+		s.ContractCreationCount++
+	}
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"slices"
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
+)
+
+// Scope limits an API key to a group of routes.
+type Scope string
+
+const (
+	// ScopeTransactions covers transaction lookups, histories, address activity and
+	// lookup jobs.
+	ScopeTransactions Scope = "transactions"
+	// ScopeLabels covers the address labels.
+	ScopeLabels Scope = "labels"
+	// ScopeAccount covers the profile, the password and the API keys of the user.
+	ScopeAccount Scope = "account"
+	// ScopeAdmin covers the admin routes, which also need the admin role.
+	ScopeAdmin Scope = "admin"
+)
+
+// Scopes are the valid scopes.
+var Scopes = []Scope{ScopeTransactions, ScopeLabels, ScopeAccount, ScopeAdmin}
+
+// Valid reports whether the scope is known.
+func (s Scope) Valid() bool {
+	return slices.Contains(Scopes, s)
+}
+
+// APIKey authenticates a machine client as the user that owns it. Only the SHA-256
+// hash of the key is stored, Prefix identifies the key in listings. Keys without
+// scopes allow everything the user can do.
+type APIKey struct {
+	ID         string `gorm:"primaryKey"`
+	UserID     string `gorm:"index"`
+	Name       string
+	Prefix     string
+	Hash       string  `gorm:"uniqueIndex"`
+	Scopes     []Scope `gorm:"serializer:json"`
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// Expired reports whether the key expired before now.
+func (key *APIKey) Expired(now time.Time) bool {
+	return key.ExpiresAt != nil && !now.Before(*key.ExpiresAt)
+}
+
+func (key *APIKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if key.ID == "" {
+		key.ID = ksuid.New().String()
+	}
+	return
+}
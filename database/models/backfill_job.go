@@ -0,0 +1,48 @@
+package models
+
+import (
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
+)
+
+const (
+	BackfillStatusRunning   = "running"
+	BackfillStatusCompleted = "completed"
+	BackfillStatusCancelled = "cancelled"
+	BackfillStatusFailed    = "failed"
+)
+
+// BackfillJob fetches every block in an inclusive range from the eth node and stores
+// its transactions, only the ones of Address if it is set. All blocks before
+// Checkpoint have been processed.
+type BackfillJob struct {
+	ID           string    `gorm:"primaryKey" json:"id"`
+	UserID       string    `json:"-"`
+	Address      string    `json:"address,omitempty"`
+	FromBlock    int64     `json:"fromBlock"`
+	ToBlock      int64     `json:"toBlock"`
+	Checkpoint   int64     `json:"checkpoint"`
+	Transactions int64     `json:"transactions"`
+	Status       string    `gorm:"index" json:"status"`
+	Error        string    `json:"error,omitempty"`
+	CreatedAt    time.Time `json:"createdAt"`
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// Progress returns the processed part of the block range between 0 and 1.
+func (job *BackfillJob) Progress() float64 {
+	total := job.ToBlock - job.FromBlock + 1
+	if total <= 0 {
+		return 0
+	}
+	return float64(job.Checkpoint-job.FromBlock) / float64(total)
+}
+
+func (job *BackfillJob) BeforeCreate(tx *gorm.DB) (err error) {
+	if job.ID == "" {
+		job.ID = ksuid.New().String()
+	}
+	return
+}
@@ -0,0 +1,29 @@
+package models
+
+import "slices"
+
+// Role grants a user permissions, every role has the permissions of the roles
+// before it in Roles.
+type Role string
+
+const (
+	// RoleReader looks up transactions and manages its own history and labels.
+	RoleReader Role = "reader"
+	// RoleAnalyst also reads all stored transactions and backfills addresses.
+	RoleAnalyst Role = "analyst"
+	// RoleAdmin also manages users, global labels, backfills and retention.
+	RoleAdmin Role = "admin"
+)
+
+// Roles are ordered by their permissions.
+var Roles = []Role{RoleReader, RoleAnalyst, RoleAdmin}
+
+// Valid reports whether r is one of Roles.
+func (r Role) Valid() bool {
+	return slices.Contains(Roles, r)
+}
+
+// Includes reports whether r has the permissions of other.
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && slices.Index(Roles, r) >= slices.Index(Roles, other)
+}
@@ -0,0 +1,26 @@
+package models
+
+import (
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
+)
+
+// SigningKey is an asymmetric key pair signing the access tokens, its ID is the kid
+// header of the tokens. The public key is stored as PKIX DER to be published, the
+// private key as PKCS #8 DER encrypted with the JWT secret.
+type SigningKey struct {
+	ID         string `gorm:"primaryKey"`
+	Algorithm  string
+	PublicKey  []byte
+	PrivateKey []byte
+	CreatedAt  time.Time
+}
+
+func (key *SigningKey) BeforeCreate(tx *gorm.DB) (err error) {
+	if key.ID == "" {
+		key.ID = ksuid.New().String()
+	}
+	return
+}
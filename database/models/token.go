@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+
+	"github.com/segmentio/ksuid"
+	"gorm.io/gorm"
+)
+
+// RefreshToken exchanges itself for a new access token and a new refresh token of
+// the same family. Only the SHA-256 hash of the token is stored, UsedAt marks the
+// tokens that were exchanged already.
+type RefreshToken struct {
+	ID        string `gorm:"primaryKey"`
+	UserID    string `gorm:"index"`
+	FamilyID  string `gorm:"index"`
+	Hash      string `gorm:"uniqueIndex"`
+	ExpiresAt time.Time
+	UsedAt    *time.Time
+	CreatedAt time.Time
+}
+
+func (token *RefreshToken) BeforeCreate(tx *gorm.DB) (err error) {
+	if token.ID == "" {
+		token.ID = ksuid.New().String()
+	}
+	return
+}
+
+// RevokedToken is an access token revoked before it expires, identified by its jti.
+// It can be forgotten once the token expired.
+type RevokedToken struct {
+	ID        string `gorm:"primaryKey"`
+	ExpiresAt time.Time
+}
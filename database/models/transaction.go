@@ -1,6 +1,9 @@
 package models
 
 import (
+	"strings"
+	"time"
+
 	"github.com/segmentio/ksuid"
 	"gopkg.in/guregu/null.v4"
 	"gorm.io/gorm"
@@ -11,18 +14,49 @@ type Transaction struct {
 	TxStatus        int         `json:"transactionStatus"`
 	BlockHash       string      `json:"blockHash"`
 	BlockNumber     int64       `json:"blockNumber"`
-	From            string      `json:"from"`
-	To              null.String `json:"to"`
-	ContractAddress null.String `json:"contractAddress"`
+	From            string      `gorm:"index" json:"from"`
+	To              null.String `gorm:"index" json:"to"`
+	ContractAddress null.String `gorm:"index" json:"contractAddress"`
 	LogsCount       int         `json:"logsCount"`
 	Input           string      `json:"input"`
 	Value           int64       `json:"value"`
+
+	FromLabel            string `gorm:"-" json:"fromLabel,omitempty"`
+	ToLabel              string `gorm:"-" json:"toLabel,omitempty"`
+	ContractAddressLabel string `gorm:"-" json:"contractAddressLabel,omitempty"`
+}
+
+// LowercaseAddresses lowercases the addresses of the transaction. Addresses are
+// stored in lowercase so they can be looked up with an index.
+func (tx *Transaction) LowercaseAddresses() {
+	tx.From = strings.ToLower(tx.From)
+	tx.To.String = strings.ToLower(tx.To.String)
+	tx.ContractAddress.String = strings.ToLower(tx.ContractAddress.String)
+}
+
+func (tx *Transaction) BeforeSave(db *gorm.DB) (err error) {
+	tx.LowercaseAddresses()
+	return
+}
+
+// TransactionView is a transaction in the history of a user, viewed last at ViewedAt.
+type TransactionView struct {
+	TxHash   string
+	ViewedAt time.Time
 }
 
 type User struct {
-	ID                 string `gorm:"primaryKey"`
-	Username           string `gorm:"unique"`
-	Password           string
+	ID       string `gorm:"primaryKey"`
+	Username string `gorm:"unique"`
+	Password string
+	Role     Role
+	// Disabled users cannot log in.
+	Disabled bool
+	// Issuer and Subject identify the users of an external identity provider, they
+	// are null for local users.
+	Issuer             null.String `gorm:"uniqueIndex:idx_users_external"`
+	Subject            null.String `gorm:"uniqueIndex:idx_users_external"`
+	CreatedAt          time.Time
 	ViewedTransactions []Transaction `gorm:"many2many:user_viewed_transactions;"`
 }
 
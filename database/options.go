@@ -0,0 +1,74 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+const (
+	connectRetryInitialDelay = 500 * time.Millisecond
+	connectRetryMaxDelay     = 10 * time.Second
+)
+
+// Pool limits the connections to a database, zero values keep the database/sql defaults.
+type Pool struct {
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+	ConnMaxIdleTime time.Duration
+}
+
+// Option configures optional settings of the connection.
+type Option func(*options)
+
+type options struct {
+	pool           Pool
+	connectTimeout time.Duration
+	replicaDSN     string
+	log            *zap.SugaredLogger
+}
+
+// WithPool sets the connection pool limits of the database and its read replica.
+func WithPool(pool Pool) Option {
+	return func(o *options) {
+		o.pool = pool
+	}
+}
+
+// WithConnectRetry retries connecting with exponential backoff for up to timeout,
+// logging every failed attempt. Without it connecting is only tried once.
+func WithConnectRetry(timeout time.Duration, log *zap.SugaredLogger) Option {
+	return func(o *options) {
+		o.connectTimeout = timeout
+		o.log = log
+	}
+}
+
+// WithReadReplica routes the read queries outside of transactions to a replica of the
+// database. Reads can lag behind the writes by the replication delay, so
+// GetTransactionsByHashes, GetAllTransactions, GetTransactionsPage,
+// GetTransactionsByAddress, GetAddressStats, GetUserTransactions, GetUserViews,
+// GetUserByUsername, GetUsers, GetAPIKeys, GetAddressLabels, FindAddressLabels and
+// GetBackfillJobs can miss recent writes. The other reads always use the primary.
+func WithReadReplica(dsn string) Option {
+	return func(o *options) {
+		o.replicaDSN = dsn
+	}
+}
+
+func (p Pool) apply(db *sql.DB) {
+	if p.MaxOpenConns > 0 {
+		db.SetMaxOpenConns(p.MaxOpenConns)
+	}
+	if p.MaxIdleConns > 0 {
+		db.SetMaxIdleConns(p.MaxIdleConns)
+	}
+	if p.ConnMaxLifetime > 0 {
+		db.SetConnMaxLifetime(p.ConnMaxLifetime)
+	}
+	if p.ConnMaxIdleTime > 0 {
+		db.SetConnMaxIdleTime(p.ConnMaxIdleTime)
+	}
+}
@@ -0,0 +1,74 @@
+package database
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// prunableTransactions matches the transactions fetched and last viewed before @cutoff
+// that involve no labelled address.
+const prunableTransactions = `fetched_at < @cutoff
+	AND NOT EXISTS (
+		SELECT 1 FROM user_viewed_transactions v
+		WHERE v.transaction_tx_hash = transactions.tx_hash AND v.viewed_at >= @cutoff)
+	AND NOT EXISTS (
+		SELECT 1 FROM address_labels l
+		WHERE l.address IN (transactions."from", transactions."to", transactions.contract_address))`
+
+// excessUserViews selects the history entries of every user beyond the newest ?.
+const excessUserViews = `SELECT user_id, transaction_tx_hash FROM (
+	SELECT user_id, transaction_tx_hash,
+		ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY viewed_at DESC, transaction_tx_hash) AS position
+	FROM user_viewed_transactions) ranked
+	WHERE position > ?`
+
+// PruneTransactions deletes the transactions that were fetched and last viewed by any
+// user before notViewedSince, together with their history entries. Transactions from,
+// to or creating a labelled address are kept. With dryRun nothing is deleted, the
+// number of transactions that would be is returned.
+func (c *Client) PruneTransactions(notViewedSince time.Time, dryRun bool) (int64, error) {
+	args := map[string]any{"cutoff": notViewedSince.UTC()}
+
+	if dryRun {
+		var count int64
+		err := c.db.Raw("SELECT COUNT(*) FROM transactions WHERE "+prunableTransactions, args).Scan(&count).Error
+		return count, err
+	}
+
+	var pruned int64
+	err := c.db.Transaction(func(tx *gorm.DB) error {
+		err := tx.Exec("DELETE FROM user_viewed_transactions WHERE transaction_tx_hash IN "+
+			"(SELECT tx_hash FROM transactions WHERE "+prunableTransactions+")", args).Error
+		if err != nil {
+			return err
+		}
+
+		result := tx.Exec("DELETE FROM transactions WHERE "+prunableTransactions, args)
+		pruned = result.RowsAffected
+		return result.Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return pruned, nil
+}
+
+// TrimUserTransactions removes the oldest viewed transactions from the history of every
+// user with more than maxPerUser of them. The transactions themselves are kept. With
+// dryRun nothing is removed, the number of entries that would be is returned.
+func (c *Client) TrimUserTransactions(maxPerUser int, dryRun bool) (int64, error) {
+	if dryRun {
+		var count int64
+		err := c.db.Raw("SELECT COUNT(*) FROM ("+excessUserViews+") excess", maxPerUser).Scan(&count).Error
+		return count, err
+	}
+
+	result := c.db.Exec("DELETE FROM user_viewed_transactions WHERE (user_id, transaction_tx_hash) IN ("+excessUserViews+")", maxPerUser)
+	if result.Error != nil {
+		return 0, result.Error
+	}
+
+	return result.RowsAffected, nil
+}
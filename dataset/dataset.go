@@ -0,0 +1,295 @@
+// Package dataset exports the stored transactions and user histories to NDJSON and
+// imports them into another store.
+//
+// A dataset starts with a header record followed by one record per line, the
+// transactions first and the user histories after them:
+//
+//	{"type":"header","version":1}
+//	{"type":"transaction","transaction":{"transactionHash":"0x...",...}}
+//	{"type":"view","view":{"username":"alice","transactionHash":"0x...","viewedAt":"2024-01-02T15:04:05Z"}}
+//
+// Histories reference users by name, as user IDs differ between stores.
+package dataset
+
+import (
+	"encoding/json"
+	"errors"
+	"eth-fetcher/app"
+	"eth-fetcher/database/models"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+)
+
+// Version is the format version written to the header of new datasets.
+const Version = 1
+
+const (
+	RecordHeader      = "header"
+	RecordTransaction = "transaction"
+	RecordView        = "view"
+)
+
+const (
+	// exportPageSize is the number of transactions read at once.
+	exportPageSize = 1000
+	// importBatchSize is the number of records saved at once.
+	importBatchSize = 500
+)
+
+// Record is a single line of a dataset.
+type Record struct {
+	Type        string              `json:"type"`
+	Version     int                 `json:"version,omitempty"`
+	Transaction *models.Transaction `json:"transaction,omitempty"`
+	View        *View               `json:"view,omitempty"`
+}
+
+// View is a transaction in the history of a user, viewed last at ViewedAt.
+type View struct {
+	Username        string    `json:"username"`
+	TransactionHash string    `json:"transactionHash"`
+	ViewedAt        time.Time `json:"viewedAt"`
+}
+
+// Stats counts the records of an export or import.
+type Stats struct {
+	Transactions int
+	Views        int
+	// Skipped are the views of unknown users or transactions, only set by Import.
+	Skipped int
+}
+
+// Export writes all transactions, ordered by hash, and the histories of all users to w.
+func Export(db app.DB, w io.Writer) (Stats, error) {
+	var stats Stats
+	enc := json.NewEncoder(w)
+
+	err := enc.Encode(Record{Type: RecordHeader, Version: Version})
+	if err != nil {
+		return stats, err
+	}
+
+	// Transactions are read in pages, so the store is never loaded into memory at once
+	after := ""
+	for {
+		transactions, err := db.GetTransactionsPage(after, exportPageSize)
+		if err != nil {
+			return stats, fmt.Errorf("reading transactions: %w", err)
+		}
+		for _, tx := range transactions {
+			err = enc.Encode(Record{Type: RecordTransaction, Transaction: tx})
+			if err != nil {
+				return stats, err
+			}
+			stats.Transactions++
+		}
+		if len(transactions) < exportPageSize {
+			break
+		}
+		after = transactions[len(transactions)-1].TxHash
+	}
+
+	users, err := db.GetUsers()
+	if err != nil {
+		return stats, fmt.Errorf("reading users: %w", err)
+	}
+	for _, user := range users {
+		views, err := db.GetUserViews(user.ID)
+		if err != nil {
+			return stats, fmt.Errorf("reading history of %s: %w", user.Username, err)
+		}
+		for _, v := range views {
+			view := &View{Username: user.Username, TransactionHash: v.TxHash, ViewedAt: v.ViewedAt.UTC()}
+			err = enc.Encode(Record{Type: RecordView, View: view})
+			if err != nil {
+				return stats, err
+			}
+			stats.Views++
+		}
+	}
+
+	return stats, nil
+}
+
+// Import reads a dataset from r and upserts its records into db, so importing the
+// same dataset again changes nothing. Transaction hashes are normalized, records with
+// invalid hashes are rejected. Views of users or transactions missing from db are
+// skipped, views keep their time and count as viewed now if they have none.
+func Import(db app.DB, r io.Reader) (Stats, error) {
+	im := &importer{
+		db:      db,
+		now:     time.Now(),
+		userIDs: make(map[string]string),
+		views:   make(map[string][]*models.TransactionView),
+	}
+
+	dec := json.NewDecoder(r)
+	for n := 1; ; n++ {
+		var record Record
+		err := dec.Decode(&record)
+		if errors.Is(err, io.EOF) {
+			if n == 1 {
+				return im.stats, errors.New("empty dataset")
+			}
+			break
+		}
+		if err != nil {
+			return im.stats, fmt.Errorf("record %d: %w", n, err)
+		}
+
+		err = im.add(n, record)
+		if err != nil {
+			return im.stats, fmt.Errorf("record %d: %w", n, err)
+		}
+	}
+
+	err := im.flush()
+	if err != nil {
+		return im.stats, err
+	}
+
+	return im.stats, nil
+}
+
+type importer struct {
+	db    app.DB
+	stats Stats
+
+	// now is the time of the views without one
+	now time.Time
+
+	transactions []*models.Transaction
+	// views maps usernames to their pending views
+	views        map[string][]*models.TransactionView
+	pendingViews int
+	// userIDs caches the IDs of the users by name, unknown users map to ""
+	userIDs map[string]string
+}
+
+func (im *importer) add(n int, record Record) error {
+	if n == 1 {
+		if record.Type != RecordHeader {
+			return errors.New("missing header")
+		}
+		if record.Version != Version {
+			return fmt.Errorf("unsupported version %d", record.Version)
+		}
+		return nil
+	}
+
+	switch record.Type {
+	case RecordTransaction:
+		if record.Transaction == nil || record.Transaction.TxHash == "" {
+			return errors.New("transaction without hash")
+		}
+		hash, ok := app.NormalizeHash(record.Transaction.TxHash)
+		if !ok {
+			return fmt.Errorf("invalid transaction hash %q", record.Transaction.TxHash)
+		}
+		record.Transaction.TxHash = hash
+		im.transactions = append(im.transactions, record.Transaction)
+		if len(im.transactions) >= importBatchSize {
+			return im.flushTransactions()
+		}
+	case RecordView:
+		if record.View == nil || record.View.Username == "" || record.View.TransactionHash == "" {
+			return errors.New("view without username or transaction hash")
+		}
+		hash, ok := app.NormalizeHash(record.View.TransactionHash)
+		if !ok {
+			return fmt.Errorf("invalid transaction hash %q", record.View.TransactionHash)
+		}
+		viewedAt := record.View.ViewedAt
+		if viewedAt.IsZero() {
+			viewedAt = im.now
+		}
+		view := &models.TransactionView{TxHash: hash, ViewedAt: viewedAt}
+		im.views[record.View.Username] = append(im.views[record.View.Username], view)
+		im.pendingViews++
+		if im.pendingViews >= importBatchSize {
+			return im.flush()
+		}
+	default:
+		return fmt.Errorf("unknown record type %q", record.Type)
+	}
+
+	return nil
+}
+
+// flush saves the pending transactions before the pending views, which may
+// reference them.
+func (im *importer) flush() error {
+	err := im.flushTransactions()
+	if err != nil {
+		return err
+	}
+
+	for username, views := range im.views {
+		err = im.flushViews(username, views)
+		if err != nil {
+			return err
+		}
+	}
+	im.views = make(map[string][]*models.TransactionView)
+	im.pendingViews = 0
+
+	return nil
+}
+
+func (im *importer) flushTransactions() error {
+	if len(im.transactions) == 0 {
+		return nil
+	}
+
+	err := im.db.SaveTransactions(im.transactions)
+	if err != nil {
+		return fmt.Errorf("saving transactions: %w", err)
+	}
+	im.stats.Transactions += len(im.transactions)
+	im.transactions = nil
+
+	return nil
+}
+
+func (im *importer) flushViews(username string, views []*models.TransactionView) error {
+	userID, ok := im.userIDs[username]
+	if !ok {
+		user, err := im.db.GetUserByUsername(username)
+		if err == nil {
+			userID = user.ID
+		}
+		im.userIDs[username] = userID
+	}
+	if userID == "" {
+		im.stats.Skipped += len(views)
+		return nil
+	}
+
+	// Only add stored transactions, views must not create empty ones
+	hashes := make([]string, len(views))
+	for i, v := range views {
+		hashes[i] = v.TxHash
+	}
+	transactions, err := im.db.GetTransactionsByHashes(hashes)
+	if err != nil {
+		return fmt.Errorf("reading transactions: %w", err)
+	}
+	stored := make(map[string]bool, len(transactions))
+	for _, tx := range transactions {
+		stored[tx.TxHash] = true
+	}
+	views = slices.DeleteFunc(views, func(v *models.TransactionView) bool {
+		return !stored[v.TxHash]
+	})
+
+	err = im.db.RestoreUserViews(userID, views)
+	if err != nil {
+		return fmt.Errorf("saving history of %s: %w", username, err)
+	}
+	im.stats.Views += len(views)
+	im.stats.Skipped += len(hashes) - len(views)
+
+	return nil
+}
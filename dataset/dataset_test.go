@@ -0,0 +1,220 @@
+package dataset_test
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gopkg.in/guregu/null.v4"
+
+	"eth-fetcher/database/models"
+	"eth-fetcher/dataset"
+	"eth-fetcher/memory"
+)
+
+const (
+	hash1 = "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"
+	hash2 = "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"
+)
+
+var tx1 = &models.Transaction{
+	TxHash:      hash1,
+	TxStatus:    1,
+	BlockHash:   "0x92557f7e29c39cae6be013ffc817620fcd5233b68405cdfc6e0b5528261e81e5",
+	BlockNumber: 20,
+	From:        "0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767",
+	To:          null.NewString("0xb0428bf0d49eb5c2239a815b43e59e124b84e303", true),
+	LogsCount:   1,
+	Input:       "0x",
+	Value:       50000000000000000,
+}
+
+var tx2 = &models.Transaction{
+	TxHash:          hash2,
+	TxStatus:        1,
+	BlockNumber:     10,
+	From:            "0xb0428bf0d49eb5c2239a815b43e59e124b84e303",
+	ContractAddress: null.NewString("0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767", true),
+	Input:           "0x60806040",
+}
+
+// newStore returns an in-memory store with the user alice.
+func newStore(t *testing.T) *memory.DB {
+	db, err := memory.New()
+	require.NoError(t, err)
+	require.NoError(t, db.CreateUser(&models.User{Username: "alice", Role: models.RoleReader}))
+
+	return db
+}
+
+func TestExportImport(t *testing.T) {
+	source := newStore(t)
+	alice, err := source.GetUserByUsername("alice")
+	require.NoError(t, err)
+
+	require.NoError(t, source.SaveTransactions([]*models.Transaction{tx1, tx2}))
+	require.NoError(t, source.AddUserTransactions(alice.ID, []*models.Transaction{tx2}))
+
+	var buf bytes.Buffer
+	stats, err := dataset.Export(source, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, dataset.Stats{Transactions: 2, Views: 1}, stats)
+	assert.Len(t, strings.Split(strings.TrimSpace(buf.String()), "\n"), 4)
+
+	target := newStore(t)
+
+	// Importing twice is idempotent
+	for i := 0; i < 2; i++ {
+		stats, err = dataset.Import(target, bytes.NewReader(buf.Bytes()))
+		require.NoError(t, err)
+		assert.Equal(t, dataset.Stats{Transactions: 2, Views: 1}, stats)
+	}
+
+	// Transactions are exported in the order of their hashes
+	transactions, err := target.GetAllTransactions()
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2, tx1}, transactions)
+
+	// The history is matched by username, the IDs differ between the stores
+	targetAlice, err := target.GetUserByUsername("alice")
+	require.NoError(t, err)
+	assert.NotEqual(t, alice.ID, targetAlice.ID)
+
+	transactions, err = target.GetUserTransactions(targetAlice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2}, transactions)
+}
+
+func TestExport_Pages(t *testing.T) {
+	source := newStore(t)
+
+	// More transactions than fit in one page of the export
+	transactions := make([]*models.Transaction, 2500)
+	for i := range transactions {
+		transactions[i] = &models.Transaction{TxHash: fmt.Sprintf("0x%064x", i), BlockNumber: int64(i)}
+	}
+	require.NoError(t, source.SaveTransactions(transactions))
+
+	var buf bytes.Buffer
+	stats, err := dataset.Export(source, &buf)
+	require.NoError(t, err)
+	assert.Equal(t, dataset.Stats{Transactions: len(transactions)}, stats)
+
+	target := newStore(t)
+	_, err = dataset.Import(target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	imported, err := target.GetAllTransactions()
+	assert.NoError(t, err)
+	assert.Equal(t, transactions, imported)
+}
+
+func TestExportImport_ViewedAt(t *testing.T) {
+	source := newStore(t)
+	alice, err := source.GetUserByUsername("alice")
+	require.NoError(t, err)
+
+	viewedAt := time.Date(2024, 1, 2, 15, 4, 5, 0, time.UTC)
+	require.NoError(t, source.SaveTransactions([]*models.Transaction{tx1}))
+	require.NoError(t, source.RestoreUserViews(alice.ID, []*models.TransactionView{{TxHash: hash1, ViewedAt: viewedAt}}))
+
+	var buf bytes.Buffer
+	_, err = dataset.Export(source, &buf)
+	require.NoError(t, err)
+	assert.Contains(t, buf.String(), `"viewedAt":"2024-01-02T15:04:05Z"`)
+
+	target := newStore(t)
+	_, err = dataset.Import(target, bytes.NewReader(buf.Bytes()))
+	require.NoError(t, err)
+
+	// The view keeps its time instead of counting as viewed at the import
+	targetAlice, err := target.GetUserByUsername("alice")
+	require.NoError(t, err)
+	views, err := target.GetUserViews(targetAlice.ID)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.Equal(t, hash1, views[0].TxHash)
+	assert.True(t, viewedAt.Equal(views[0].ViewedAt))
+}
+
+func TestImport_WithoutViewedAt(t *testing.T) {
+	db := newStore(t)
+	start := time.Now()
+
+	data := `{"type":"header","version":1}
+{"type":"transaction","transaction":{"transactionHash":"` + hash1 + `","blockNumber":20}}
+{"type":"view","view":{"username":"alice","transactionHash":"` + hash1 + `"}}
+`
+	_, err := dataset.Import(db, strings.NewReader(data))
+	require.NoError(t, err)
+
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	views, err := db.GetUserViews(alice.ID)
+	require.NoError(t, err)
+	require.Len(t, views, 1)
+	assert.False(t, views[0].ViewedAt.Before(start))
+}
+
+func TestImport_NormalizesHashes(t *testing.T) {
+	db := newStore(t)
+
+	data := `{"type":"header","version":1}
+{"type":"transaction","transaction":{"transactionHash":"` + strings.ToUpper(hash1[2:]) + `","blockNumber":20}}
+{"type":"view","view":{"username":"alice","transactionHash":"0X` + strings.ToUpper(hash1[2:]) + `"}}
+`
+	stats, err := dataset.Import(db, strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, dataset.Stats{Transactions: 1, Views: 1}, stats)
+
+	transactions, err := db.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+}
+
+func TestImport_SkipsUnknownViews(t *testing.T) {
+	db := newStore(t)
+
+	data := `{"type":"header","version":1}
+{"type":"transaction","transaction":{"transactionHash":"` + hash1 + `","blockNumber":20}}
+{"type":"view","view":{"username":"alice","transactionHash":"` + hash1 + `"}}
+{"type":"view","view":{"username":"alice","transactionHash":"` + hash2 + `"}}
+{"type":"view","view":{"username":"mallory","transactionHash":"` + hash1 + `"}}
+`
+	stats, err := dataset.Import(db, strings.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, dataset.Stats{Transactions: 1, Views: 1, Skipped: 2}, stats)
+
+	// Views do not create transactions
+	transactions, err := db.GetTransactionsByHashes([]string{hash2})
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func TestImport_Invalid(t *testing.T) {
+	tests := map[string]string{
+		"empty":          "",
+		"missing header": `{"type":"transaction","transaction":{"transactionHash":"` + hash1 + `"}}`,
+		"version":        `{"type":"header","version":2}`,
+		"unknown type":   "{\"type\":\"header\",\"version\":1}\n{\"type\":\"block\"}",
+		"no hash":        "{\"type\":\"header\",\"version\":1}\n{\"type\":\"transaction\",\"transaction\":{}}",
+		"malformed":      "{\"type\":\"header\",\"version\":1}\n{\"type\":",
+		"invalid hash":   "{\"type\":\"header\",\"version\":1}\n{\"type\":\"transaction\",\"transaction\":{\"transactionHash\":\"0x1234\"}}",
+		"invalid view hash": "{\"type\":\"header\",\"version\":1}\n" +
+			"{\"type\":\"view\",\"view\":{\"username\":\"alice\",\"transactionHash\":\"not a hash\"}}",
+	}
+
+	for name, data := range tests {
+		t.Run(name, func(t *testing.T) {
+			db, err := memory.New()
+			require.NoError(t, err)
+
+			_, err = dataset.Import(db, strings.NewReader(data))
+			assert.Error(t, err)
+		})
+	}
+}
@@ -0,0 +1,101 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"eth-fetcher/app"
+	"eth-fetcher/config"
+	"eth-fetcher/dataset"
+	"io"
+	"os"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const (
+	exportUsage = "usage: export [file]"
+	importUsage = "usage: import [file]"
+)
+
+// exportDataset writes the transactions and user histories to the file given by the
+// arguments, or to stdout without one.
+func exportDataset(cfg *config.Config, args []string, log *zap.SugaredLogger) (err error) {
+	if len(args) > 1 {
+		return errors.New(exportUsage)
+	}
+
+	db, err := openDatasetStore(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	out := os.Stdout
+	if len(args) == 1 && args[0] != "-" {
+		out, err = os.Create(args[0])
+		if err != nil {
+			return err
+		}
+		// A failed close can lose the written data
+		defer func() {
+			if closeErr := out.Close(); err == nil {
+				err = closeErr
+			}
+		}()
+	}
+
+	w := bufio.NewWriter(out)
+	stats, err := dataset.Export(db, w)
+	if err != nil {
+		return err
+	}
+	err = w.Flush()
+	if err != nil {
+		return err
+	}
+	log.Infof("exported %d transactions and %d viewed transactions", stats.Transactions, stats.Views)
+
+	return nil
+}
+
+// importDataset upserts the transactions and user histories from the file given by
+// the arguments, or from stdin without one.
+func importDataset(cfg *config.Config, args []string, log *zap.SugaredLogger) error {
+	if len(args) > 1 {
+		return errors.New(importUsage)
+	}
+
+	db, err := openDatasetStore(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer db.Close()
+
+	var r io.Reader = os.Stdin
+	if len(args) == 1 && args[0] != "-" {
+		f, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	stats, err := dataset.Import(db, r)
+	if err != nil {
+		return err
+	}
+	log.Infof("imported %d transactions and %d viewed transactions, skipped %d views of unknown users or transactions",
+		stats.Transactions, stats.Views, stats.Skipped)
+
+	return nil
+}
+
+func openDatasetStore(cfg *config.Config, log *zap.SugaredLogger) (app.DB, error) {
+	if strings.HasPrefix(cfg.DBConnectionURL, "memory://") {
+		return nil, errors.New("the memory store is not persisted")
+	}
+
+	return openStore(cfg, log)
+}
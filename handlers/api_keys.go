@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// CreateAPIKeyHandler creates an API key for the user, requests authenticated with a
+// limited API key can only create keys with its scopes. The key is only part of this
+// response.
+func (a *HTTP) CreateAPIKeyHandler(s Session, r *http.Request) (any, error) {
+	var req CreateAPIKeyRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	key, secret, err := a.app.CreateAPIKey(s.UserID, s.Scopes, req.Name, req.Scopes, req.ExpiresAt)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	response := newAPIKeyResponse(key)
+	response.Key = secret
+	return response, nil
+}
+
+func (a *HTTP) GetAPIKeysHandler(s Session, r *http.Request) (any, error) {
+	keys, err := a.app.ListAPIKeys(s.UserID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	response := GetAPIKeysResponse{Keys: make([]APIKeyResponse, len(keys))}
+	for i, key := range keys {
+		response.Keys[i] = newAPIKeyResponse(key)
+	}
+
+	return response, nil
+}
+
+func (a *HTTP) RevokeAPIKeyHandler(s Session, r *http.Request) (any, error) {
+	err := a.app.RevokeAPIKey(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import (
+	"eth-fetcher/auth"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+var (
+	corsMethods = []string{http.MethodGet, http.MethodPost, http.MethodDelete}
+	corsHeaders = []string{"Authorization", auth.TokenHeader, auth.APIKeyHeader, "Content-Type"}
+	// corsExposedHeaders are the response headers that are not safelisted.
+	corsExposedHeaders = []string{"WWW-Authenticate", "Retry-After"}
+)
+
+// SetCORSOrigins sets the origins allowed to call the API from a browser, * allows
+// all. It can be changed while the server runs.
+func (h *HTTP) SetCORSOrigins(origins []string) {
+	h.corsMu.Lock()
+	defer h.corsMu.Unlock()
+
+	h.corsOrigins = origins
+}
+
+func (h *HTTP) allowedOrigin(origin string) bool {
+	h.corsMu.RLock()
+	defer h.corsMu.RUnlock()
+
+	return slices.Contains(h.corsOrigins, origin) || slices.Contains(h.corsOrigins, "*")
+}
+
+// cors adds the CORS headers for the allowed origins, preflight requests are answered
+// by preflightHandler.
+func (h *HTTP) cors(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		if origin != "" {
+			w.Header().Add("Vary", "Origin")
+		}
+		if origin != "" && h.allowedOrigin(origin) {
+			w.Header().Set("Access-Control-Allow-Origin", origin)
+			w.Header().Set("Access-Control-Expose-Headers", strings.Join(corsExposedHeaders, ", "))
+			if r.Method == http.MethodOptions {
+				w.Header().Set("Access-Control-Allow-Methods", strings.Join(corsMethods, ", "))
+				w.Header().Set("Access-Control-Allow-Headers", strings.Join(corsHeaders, ", "))
+				w.Header().Set("Access-Control-Max-Age", "600")
+			}
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+func preflightHandler(w http.ResponseWriter, _ *http.Request) {
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -2,12 +2,20 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"eth-fetcher/app"
+	"eth-fetcher/auth"
 	"eth-fetcher/database/models"
+	"expvar"
 
 	"eth-fetcher/helpers/rlp"
 	"fmt"
+	"io"
+	"mime"
 	"net/http"
+	"strconv"
+	"sync"
+	"time"
 
 	"github.com/gorilla/mux"
 	"go.uber.org/zap"
@@ -19,34 +27,125 @@ type HTTP struct {
 	auth    Auth
 	log     *zap.SugaredLogger
 	Router  *mux.Router
+
+	corsMu      sync.RWMutex
+	corsOrigins []string
 }
 
 type APP interface {
-	GetTransactionsByHashes(transactionHashes []string) ([]*models.Transaction, error)
+	GetTransactionsByHashes(userID string, transactionHashes []string) ([]*models.Transaction, error)
 	GetAllTransactions() ([]*models.Transaction, error)
 	AddUserTransactions(userID string, transactions []*models.Transaction) error
 	GetUserTransactions(userID string) ([]*models.Transaction, error)
 	CheckUserCredentials(username, password string) (*models.User, error)
+	IssueRefreshToken(userID string) (string, error)
+	RefreshSession(refreshToken string) (*models.User, string, error)
+	Logout(userID, tokenID string, tokenExpiresAt time.Time, refreshToken string) error
+	Register(username, password string) (*models.User, error)
+	GetProfile(userID string) (*models.User, error)
+	ChangePassword(userID, currentPassword, newPassword string) error
+	CreateAPIKey(userID string, callerScopes []models.Scope, name string, scopes []models.Scope, expiresAt *time.Time) (*models.APIKey, string, error)
+	ListAPIKeys(userID string) ([]*models.APIKey, error)
+	RevokeAPIKey(userID, id string) error
+	ListUsers(userID string) ([]*models.User, error)
+	AddUser(userID, username, password string, role models.Role) (*models.User, error)
+	SetUserRole(userID, id string, role models.Role) (*models.User, error)
+	SetUserDisabled(userID, id string, disabled bool) (*models.User, error)
+	DeleteUser(userID, id string) error
+	ResetUserPassword(userID, id, password string) error
+	AddAddressLabel(userID string, label *models.AddressLabel, global bool) error
+	GetAddressLabels(userID string) ([]*models.AddressLabel, error)
+	DeleteAddressLabel(userID, address string, global bool) error
+	LabelTransactions(userID string, transactions []*models.Transaction) []*models.Transaction
+	GetAddressActivity(address, cursor string, limit int) (*app.AddressActivity, error)
+	BackfillAddress(userID, address string, fromBlock, toBlock int64) (*models.BackfillJob, error)
+	StartBackfill(userID string, fromBlock, toBlock int64) (*models.BackfillJob, error)
+	ResumeBackfill(userID, id string) (*models.BackfillJob, error)
+	CancelBackfill(userID, id string) error
+	GetBackfillJob(userID, id string) (*models.BackfillJob, error)
+	GetBackfillJobs(userID string) ([]*models.BackfillJob, error)
+	StartLookupJob(userID string, transactionHashes []string) (*app.LookupJob, error)
+	GetLookupJob(userID, id string) (*app.LookupJob, error)
+	Prune(userID string, dryRun bool) (*app.PruneReport, error)
 }
 
 type Auth interface {
-	AuthenticateRequest(r *http.Request) (string, error)
-	GenerateToken(subject string) (string, error)
+	AuthenticateRequest(r *http.Request) (auth.Identity, error)
+	GenerateToken(subject, role string) (string, error)
+	JWKS() (auth.JWKS, error)
 }
 
+// Session is the caller of a request, all fields are empty for anonymous callers.
 type Session struct {
 	UserID string
+	// Role is taken from the token, the app checks the stored role for admin
+	// operations.
+	Role models.Role
+	// Scopes limit sessions of API keys, nil allows all scopes.
+	Scopes []models.Scope
+	// TokenID and TokenExpiresAt identify the access token of the session, they are
+	// empty for API keys.
+	TokenID        string
+	TokenExpiresAt time.Time
 }
 
 type handleFunc func(Session, *http.Request) (any, error)
 
+const (
+	jsonContentType = "application/json"
+	rlpContentType  = "application/x-rlp"
+
+	maxRequestBodySize = 10 << 20
+)
+
 func (h *HTTP) InitRoutes() {
+	reader := requireRole(models.RoleReader)
+	analyst := requireRole(models.RoleAnalyst)
+	admin := requireRole(models.RoleAdmin)
+	txScope := requireScope(models.ScopeTransactions)
+	labelsScope := requireScope(models.ScopeLabels)
+	accountScope := requireScope(models.ScopeAccount)
+	adminScope := requireScope(models.ScopeAdmin)
+
 	router := mux.NewRouter()
-	router.HandleFunc("/api/eth", h.HandleHTTPRequest(h.GetTransactionsHandler)).Methods("GET")
-	router.HandleFunc("/api/all", h.HandleHTTPRequest(h.GetTransactionsHandler)).Methods("GET")
-	router.HandleFunc("/api/eth/{rlphex}", h.HandleHTTPRequest(h.GetTransactionsByRLPHandler)).Methods("GET")
-	router.HandleFunc("/api/authenticate", h.HandleHTTPRequest(h.AuthenticateHandler)).Methods("POST")
-	router.HandleFunc("/api/my", h.HandleHTTPRequest(h.GetUserTransactions)).Methods("GET")
+	router.HandleFunc("/api/eth", h.HandleHTTPRequest(h.GetTransactionsHandler, txScope)).Methods("GET")
+	router.HandleFunc("/api/eth", h.HandleHTTPRequest(h.PostTransactionsHandler, txScope)).Methods("POST")
+	router.HandleFunc("/api/all", h.HandleHTTPRequest(h.GetTransactionsHandler, analyst, txScope)).Methods("GET")
+	router.HandleFunc("/api/eth/{rlphex}", h.HandleHTTPRequest(h.GetTransactionsByRLPHandler, txScope)).Methods("GET")
+	router.HandleFunc("/api/authenticate", h.HandleCredentialsRequest(h.AuthenticateHandler)).Methods("POST")
+	router.HandleFunc("/api/token/refresh", h.HandleCredentialsRequest(h.RefreshTokenHandler)).Methods("POST")
+	router.HandleFunc("/api/logout", h.HandleHTTPRequest(h.LogoutHandler, reader, accountScope)).Methods("POST")
+	router.HandleFunc("/api/register", h.HandleCredentialsRequest(h.RegisterHandler)).Methods("POST")
+	router.HandleFunc("/api/me", h.HandleHTTPRequest(h.GetProfileHandler, reader, accountScope)).Methods("GET")
+	router.HandleFunc("/api/me/password", h.HandleHTTPRequest(h.ChangePasswordHandler, reader, accountScope)).Methods("POST")
+	router.HandleFunc("/api/me/keys", h.HandleHTTPRequest(h.GetAPIKeysHandler, reader, accountScope)).Methods("GET")
+	router.HandleFunc("/api/me/keys", h.HandleHTTPRequest(h.CreateAPIKeyHandler, reader, accountScope)).Methods("POST")
+	router.HandleFunc("/api/me/keys/{id}", h.HandleHTTPRequest(h.RevokeAPIKeyHandler, reader, accountScope)).Methods("DELETE")
+	router.HandleFunc("/api/my", h.HandleHTTPRequest(h.GetUserTransactions, reader, txScope)).Methods("GET")
+	router.HandleFunc("/api/address/{address}", h.HandleHTTPRequest(h.GetAddressActivityHandler, txScope)).Methods("GET")
+	router.HandleFunc("/api/backfill/{id}", h.HandleHTTPRequest(h.GetBackfillJobHandler, analyst, txScope)).Methods("GET")
+	router.HandleFunc("/api/labels", h.HandleHTTPRequest(h.GetAddressLabelsHandler, reader, labelsScope)).Methods("GET")
+	router.HandleFunc("/api/labels", h.HandleHTTPRequest(h.AddAddressLabelHandler, reader, labelsScope)).Methods("POST")
+	router.HandleFunc("/api/labels/{address}", h.HandleHTTPRequest(h.DeleteAddressLabelHandler, reader, labelsScope)).Methods("DELETE")
+	router.HandleFunc("/api/jobs/lookup", h.HandleHTTPRequest(h.StartLookupJobHandler, txScope)).Methods("POST")
+	router.HandleFunc("/api/jobs/{id}", h.HandleHTTPRequest(h.GetLookupJobHandler, txScope)).Methods("GET")
+	router.HandleFunc("/api/admin/backfill", h.HandleHTTPRequest(h.StartBackfillHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/backfill", h.HandleHTTPRequest(h.GetBackfillJobsHandler, admin, adminScope)).Methods("GET")
+	router.HandleFunc("/api/admin/backfill/{id}", h.HandleHTTPRequest(h.GetBackfillJobHandler, admin, adminScope)).Methods("GET")
+	router.HandleFunc("/api/admin/backfill/{id}", h.HandleHTTPRequest(h.CancelBackfillHandler, admin, adminScope)).Methods("DELETE")
+	router.HandleFunc("/api/admin/backfill/{id}/resume", h.HandleHTTPRequest(h.ResumeBackfillHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/prune", h.HandleHTTPRequest(h.PruneHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/metrics", h.HandleHTTPRequest(h.MetricsHandler, admin, adminScope)).Methods("GET")
+	router.HandleFunc("/api/admin/users", h.HandleHTTPRequest(h.GetUsersHandler, admin, adminScope)).Methods("GET")
+	router.HandleFunc("/api/admin/users", h.HandleHTTPRequest(h.AddUserHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/users/{id}", h.HandleHTTPRequest(h.DeleteUserHandler, admin, adminScope)).Methods("DELETE")
+	router.HandleFunc("/api/admin/users/{id}/role", h.HandleHTTPRequest(h.SetUserRoleHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/users/{id}/disable", h.HandleHTTPRequest(h.DisableUserHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/users/{id}/enable", h.HandleHTTPRequest(h.EnableUserHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/api/admin/users/{id}/password", h.HandleHTTPRequest(h.ResetUserPasswordHandler, admin, adminScope)).Methods("POST")
+	router.HandleFunc("/.well-known/jwks.json", h.JWKSHandler).Methods("GET")
+	router.Methods("OPTIONS").HandlerFunc(preflightHandler)
+	router.Use(h.cors)
 
 	h.Router = router
 }
@@ -68,44 +167,68 @@ func (a *HTTP) GetTransactionsByRLPHandler(s Session, r *http.Request) (any, err
 		return nil, err
 	}
 
-	transactions, err := a.app.GetTransactionsByHashes(transactionHashes)
+	return a.getTransactionsByHashes(s, transactionHashes)
+}
+
+// PostTransactionsHandler looks up the hashes sent in the request body, either as a
+// JSON array or as a binary RLP encoded list with the application/x-rlp content type.
+func (a *HTTP) PostTransactionsHandler(s Session, r *http.Request) (any, error) {
+	mediaType, _, err := mime.ParseMediaType(r.Header.Get("Content-Type"))
 	if err != nil {
-		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+		return nil, &ErrorResponse{Msg: "the content type must be application/json or application/x-rlp", Code: http.StatusUnsupportedMediaType}
+	}
+	if mediaType != jsonContentType && mediaType != rlpContentType {
+		return nil, &ErrorResponse{Msg: fmt.Sprintf("unsupported content type %s", mediaType), Code: http.StatusUnsupportedMediaType}
 	}
 
-	if s.UserID != "" {
-		err := a.app.AddUserTransactions(s.UserID, transactions)
-		if err != nil {
-			a.log.Errorf("error adding user transactions: %v", err)
-		}
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxRequestBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusRequestEntityTooLarge}
+	}
+	if err != nil {
+		return nil, &ErrorResponse{Msg: fmt.Sprintf("reading the request body: %v", err), Code: http.StatusBadRequest}
 	}
 
-	response := GetTransactionsResponse{Transactions: transactions}
+	var transactionHashes []string
+	if mediaType == rlpContentType {
+		transactionHashes, err = rlp.BytesToStrings(body)
+	} else {
+		err = json.Unmarshal(body, &transactionHashes)
+	}
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
 
-	return response, nil
+	return a.getTransactionsByHashes(s, transactionHashes)
 }
 
 func (a *HTTP) GetTransactionsHandler(s Session, r *http.Request) (any, error) {
 	transactionHashes := r.URL.Query()["transactionHashes"]
-	var (
-		transactions []*models.Transaction
-		err          error
-	)
-
-	if len(transactionHashes) == 0 {
-		transactions, err = a.app.GetAllTransactions()
-	} else {
-		transactions, err = a.app.GetTransactionsByHashes(transactionHashes)
+	if len(transactionHashes) > 0 {
+		return a.getTransactionsByHashes(s, transactionHashes)
 	}
 
+	transactions, err := a.app.GetAllTransactions()
 	if err != nil {
-		if err == app.ErrBadRequest {
-			return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
-		}
+		return nil, errorResponse(err)
+	}
 
-		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusInternalServerError}
+	return a.transactionsResponse(s, transactions), nil
+}
+
+// getTransactionsByHashes looks up the transactions and adds them to the history
+// of the authenticated user.
+func (a *HTTP) getTransactionsByHashes(s Session, transactionHashes []string) (any, error) {
+	transactions, err := a.app.GetTransactionsByHashes(s.UserID, transactionHashes)
+	if err != nil {
+		return nil, errorResponse(err)
 	}
 
+	return a.transactionsResponse(s, transactions), nil
+}
+
+func (a *HTTP) transactionsResponse(s Session, transactions []*models.Transaction) GetTransactionsResponse {
 	if s.UserID != "" {
 		err := a.app.AddUserTransactions(s.UserID, transactions)
 		if err != nil {
@@ -113,9 +236,7 @@ func (a *HTTP) GetTransactionsHandler(s Session, r *http.Request) (any, error) {
 		}
 	}
 
-	response := GetTransactionsResponse{Transactions: transactions}
-
-	return response, nil
+	return GetTransactionsResponse{Transactions: a.app.LabelTransactions(s.UserID, transactions)}
 }
 
 func (a *HTTP) GetUserTransactions(s Session, r *http.Request) (any, error) {
@@ -128,23 +249,49 @@ func (a *HTTP) GetUserTransactions(s Session, r *http.Request) (any, error) {
 		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusInternalServerError}
 	}
 
-	response := GetTransactionsResponse{Transactions: transactions}
+	response := GetTransactionsResponse{Transactions: a.app.LabelTransactions(s.UserID, transactions)}
 
 	return response, nil
 }
 
-func (a *HTTP) HandleHTTPRequest(fn handleFunc) http.HandlerFunc {
-	return func(w http.ResponseWriter, r *http.Request) {
-		session := Session{}
+// HandleHTTPRequest authenticates the request and calls fn if the session passes
+// all policies. Requests without a token or API key are handled as anonymous,
+// requests with invalid ones are rejected.
+func (a *HTTP) HandleHTTPRequest(fn handleFunc, policies ...policy) http.HandlerFunc {
+	return a.handle(fn, func(r *http.Request) (Session, error) {
+		session, err := a.session(r)
+		if err != nil {
+			return Session{}, err
+		}
+		return session, authorize(session, policies)
+	})
+}
+
+// HandleCredentialsRequest calls fn with an anonymous session without authenticating
+// the request. It serves the routes exchanging credentials for tokens, so a client
+// still sending its expired token can log in again or refresh it.
+func (a *HTTP) HandleCredentialsRequest(fn handleFunc) http.HandlerFunc {
+	return a.handle(fn, func(*http.Request) (Session, error) {
+		return Session{}, nil
+	})
+}
 
-		userID, err := a.auth.AuthenticateRequest(r)
+// handle calls fn with the session of the request and writes its response or error.
+func (a *HTTP) handle(fn handleFunc, session func(*http.Request) (Session, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var response any
+		session, err := session(r)
 		if err == nil {
-			session.UserID = userID
+			response, err = fn(session, r)
 		}
-
-		response, err := fn(session, r)
 		if err != nil {
 			if e, ok := err.(*ErrorResponse); ok {
+				if e.RetryAfter > 0 {
+					w.Header().Set("Retry-After", strconv.Itoa(e.RetryAfter))
+				}
+				if e.Challenge != "" {
+					w.Header().Set("WWW-Authenticate", e.Challenge)
+				}
 				w.WriteHeader(e.Code)
 				json.NewEncoder(w).Encode(e)
 				return
@@ -161,6 +308,29 @@ func (a *HTTP) HandleHTTPRequest(fn handleFunc) http.HandlerFunc {
 	}
 }
 
+// session returns the session of the caller, an anonymous one for requests without
+// credentials.
+func (a *HTTP) session(r *http.Request) (Session, error) {
+	identity, err := a.auth.AuthenticateRequest(r)
+	switch {
+	case errors.Is(err, auth.ErrNoToken):
+		return Session{}, nil
+	case errors.Is(err, auth.ErrInvalidToken), errors.Is(err, auth.ErrInvalidAPIKey):
+		return Session{}, &ErrorResponse{Msg: err.Error(), Code: http.StatusUnauthorized, Challenge: invalidTokenChallenge(err.Error())}
+	case err != nil:
+		a.log.Errorf("error authenticating request: %v", err)
+		return Session{}, &ErrorResponse{Msg: "authentication failed", Code: http.StatusInternalServerError}
+	}
+
+	return Session{
+		UserID:         identity.UserID,
+		Role:           models.Role(identity.Role),
+		Scopes:         identity.Scopes,
+		TokenID:        identity.TokenID,
+		TokenExpiresAt: identity.TokenExpiresAt,
+	}, nil
+}
+
 func (a *HTTP) AuthenticateHandler(s Session, r *http.Request) (any, error) {
 	var req AuthenticateRequest
 
@@ -174,10 +344,284 @@ func (a *HTTP) AuthenticateHandler(s Session, r *http.Request) (any, error) {
 		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusUnauthorized}
 	}
 
-	token, err := a.auth.GenerateToken(user.ID)
+	token, err := a.auth.GenerateToken(user.ID, string(user.Role))
 	if err != nil {
 		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusUnauthorized}
 	}
+	refreshToken, err := a.app.IssueRefreshToken(user.ID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return AuthenticateResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// GetAddressActivityHandler returns the cached activity of an address with a page of
+// its transactions. When the backfillFrom and backfillTo block numbers are given, a
+// backfill job scanning the range on the eth node is started as well.
+func (a *HTTP) GetAddressActivityHandler(s Session, r *http.Request) (any, error) {
+	address := mux.Vars(r)["address"]
+	query := r.URL.Query()
+
+	var backfill *BackfillJobResponse
+	if query.Has("backfillFrom") || query.Has("backfillTo") {
+		err := requireRole(models.RoleAnalyst)(s)
+		if err != nil {
+			return nil, err
+		}
+
+		fromBlock, err := strconv.ParseInt(query.Get("backfillFrom"), 10, 64)
+		if err != nil {
+			return nil, &ErrorResponse{Msg: "invalid backfillFrom", Code: http.StatusBadRequest}
+		}
+		toBlock, err := strconv.ParseInt(query.Get("backfillTo"), 10, 64)
+		if err != nil {
+			return nil, &ErrorResponse{Msg: "invalid backfillTo", Code: http.StatusBadRequest}
+		}
+
+		job, err := a.app.BackfillAddress(s.UserID, address, fromBlock, toBlock)
+		if err != nil {
+			return nil, errorResponse(err)
+		}
+		response := newBackfillJobResponse(job)
+		backfill = &response
+	}
+
+	var limit int
+	if query.Has("limit") {
+		var err error
+		limit, err = strconv.Atoi(query.Get("limit"))
+		if err != nil {
+			return nil, &ErrorResponse{Msg: "invalid limit", Code: http.StatusBadRequest}
+		}
+	}
+
+	activity, err := a.app.GetAddressActivity(address, query.Get("cursor"), limit)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return GetAddressActivityResponse{
+		Address:               activity.Address,
+		TransactionCount:      activity.TransactionCount,
+		SentCount:             activity.SentCount,
+		ReceivedCount:         activity.ReceivedCount,
+		ContractCreationCount: activity.ContractCreationCount,
+		FirstSeenBlock:        activity.FirstSeenBlock,
+		LastSeenBlock:         activity.LastSeenBlock,
+		TotalValueIn:          activity.TotalValueIn,
+		TotalValueOut:         activity.TotalValueOut,
+		BackfillJob:           backfill,
+		Transactions:          a.app.LabelTransactions(s.UserID, activity.Transactions),
+		NextCursor:            activity.NextCursor,
+	}, nil
+}
+
+func (a *HTTP) GetAddressLabelsHandler(s Session, r *http.Request) (any, error) {
+	labels, err := a.app.GetAddressLabels(s.UserID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return GetAddressLabelsResponse{Labels: labels}, nil
+}
+
+func (a *HTTP) AddAddressLabelHandler(s Session, r *http.Request) (any, error) {
+	var req AddAddressLabelRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
 
-	return AuthenticateResponse{Token: token}, nil
+	label := &models.AddressLabel{Address: req.Address, Name: req.Name}
+	err = a.app.AddAddressLabel(s.UserID, label, req.Global)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return label, nil
+}
+
+func (a *HTTP) DeleteAddressLabelHandler(s Session, r *http.Request) (any, error) {
+	address := mux.Vars(r)["address"]
+	global := r.URL.Query().Get("global") == "true"
+
+	err := a.app.DeleteAddressLabel(s.UserID, address, global)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
+
+func (a *HTTP) StartBackfillHandler(s Session, r *http.Request) (any, error) {
+	query := r.URL.Query()
+
+	fromBlock, err := strconv.ParseInt(query.Get("from"), 10, 64)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: "invalid from", Code: http.StatusBadRequest}
+	}
+	toBlock, err := strconv.ParseInt(query.Get("to"), 10, 64)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: "invalid to", Code: http.StatusBadRequest}
+	}
+
+	job, err := a.app.StartBackfill(s.UserID, fromBlock, toBlock)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newBackfillJobResponse(job), nil
+}
+
+func (a *HTTP) ResumeBackfillHandler(s Session, r *http.Request) (any, error) {
+	job, err := a.app.ResumeBackfill(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newBackfillJobResponse(job), nil
+}
+
+func (a *HTTP) CancelBackfillHandler(s Session, r *http.Request) (any, error) {
+	err := a.app.CancelBackfill(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
+
+func (a *HTTP) GetBackfillJobHandler(s Session, r *http.Request) (any, error) {
+	job, err := a.app.GetBackfillJob(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newBackfillJobResponse(job), nil
+}
+
+func (a *HTTP) GetBackfillJobsHandler(s Session, r *http.Request) (any, error) {
+	jobs, err := a.app.GetBackfillJobs(s.UserID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	response := GetBackfillJobsResponse{Jobs: make([]BackfillJobResponse, len(jobs))}
+	for i, job := range jobs {
+		response.Jobs[i] = newBackfillJobResponse(job)
+	}
+
+	return response, nil
+}
+
+// PruneHandler applies the retention policy immediately. With dryRun=true it only
+// reports what would be pruned.
+func (a *HTTP) PruneHandler(s Session, r *http.Request) (any, error) {
+	dryRun := false
+	if v := r.URL.Query().Get("dryRun"); v != "" {
+		var err error
+		dryRun, err = strconv.ParseBool(v)
+		if err != nil {
+			return nil, &ErrorResponse{Msg: "invalid dryRun", Code: http.StatusBadRequest}
+		}
+	}
+
+	report, err := a.app.Prune(s.UserID, dryRun)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return PruneResponse{
+		DryRun:           report.DryRun,
+		UserTransactions: report.UserTransactions,
+		Transactions:     report.Transactions,
+	}, nil
+}
+
+// metricsVars are the expvar variables published by MetricsHandler. The other
+// variables, like the command line and memstats, are not published.
+var metricsVars = []string{"tx_cache"}
+
+// MetricsHandler returns the published counters, variables that are not registered
+// because their component is disabled are left out.
+func (a *HTTP) MetricsHandler(s Session, r *http.Request) (any, error) {
+	response := make(map[string]json.RawMessage, len(metricsVars))
+	for _, name := range metricsVars {
+		if v := expvar.Get(name); v != nil {
+			response[name] = json.RawMessage(v.String())
+		}
+	}
+
+	return response, nil
+}
+
+// StartLookupJobHandler starts looking up the hashes in the background. The results
+// are polled with GetLookupJobHandler.
+func (a *HTTP) StartLookupJobHandler(s Session, r *http.Request) (any, error) {
+	var req StartLookupJobRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	job, err := a.app.StartLookupJob(s.UserID, req.TransactionHashes)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return a.newLookupJobResponse(s, job), nil
+}
+
+func (a *HTTP) GetLookupJobHandler(s Session, r *http.Request) (any, error) {
+	job, err := a.app.GetLookupJob(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return a.newLookupJobResponse(s, job), nil
+}
+
+func (a *HTTP) newLookupJobResponse(s Session, job *app.LookupJob) LookupJobResponse {
+	response := LookupJobResponse{
+		ID:           job.ID,
+		Status:       job.Status,
+		Total:        job.Total,
+		Processed:    job.Processed,
+		FailedHashes: job.FailedHashes,
+	}
+	if len(job.Transactions) > 0 {
+		response.Transactions = a.app.LabelTransactions(s.UserID, job.Transactions)
+	}
+
+	return response
+}
+
+// overloadedRetryAfter is how many seconds clients are asked to wait when the node
+// fetch queue is full.
+const overloadedRetryAfter = 1
+
+// errorResponse maps app errors to the matching HTTP status code.
+func errorResponse(err error) *ErrorResponse {
+	var invalidHashes *app.InvalidHashesError
+	switch {
+	case errors.As(err, &invalidHashes):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest, InvalidHashes: invalidHashes.Hashes}
+	case errors.Is(err, app.ErrBadRequest):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	case errors.Is(err, app.ErrUnauthorized):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusUnauthorized}
+	case errors.Is(err, app.ErrForbidden):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusForbidden}
+	case errors.Is(err, app.ErrNotFound):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusNotFound}
+	case errors.Is(err, app.ErrOverloaded):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusServiceUnavailable, RetryAfter: overloadedRetryAfter}
+	case errors.Is(err, app.ErrTooManyRequests):
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusTooManyRequests}
+	default:
+		return &ErrorResponse{Msg: err.Error(), Code: http.StatusInternalServerError}
+	}
 }
@@ -2,17 +2,25 @@ package handlers_test
 
 import (
 	"bytes"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"io"
+	"math/big"
 	"net/http"
 	"net/http/httptest"
 	"testing"
+	"testing/iotest"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
 
+	apppkg "eth-fetcher/app"
+	authpkg "eth-fetcher/auth"
 	"eth-fetcher/database/models"
 	"eth-fetcher/handlers"
 	"eth-fetcher/handlers/mocks"
@@ -42,6 +50,12 @@ var tx2 = &models.Transaction{
 	Value:       50000000000000000,
 }
 
+var (
+	reader  = authpkg.Identity{UserID: "user1", Role: "reader"}
+	analyst = authpkg.Identity{UserID: "user1", Role: "analyst"}
+	admin   = authpkg.Identity{UserID: "admin", Role: "admin"}
+)
+
 func Setup(t *testing.T) (*mocks.APP, *mocks.Auth, *handlers.HTTP) {
 	app := mocks.NewAPP(t)
 	auth := mocks.NewAuth(t)
@@ -53,6 +67,13 @@ func Setup(t *testing.T) (*mocks.APP, *mocks.Auth, *handlers.HTTP) {
 	return app, auth, httpHandler
 }
 
+// passThroughLabels makes LabelTransactions return the transactions unchanged.
+func passThroughLabels(app *mocks.APP, userID string) {
+	app.EXPECT().LabelTransactions(userID, mock.Anything).RunAndReturn(func(_ string, txs []*models.Transaction) []*models.Transaction {
+		return txs
+	})
+}
+
 func TestHTTP_GetAllTransactions(t *testing.T) {
 	app, auth, httpHandler := Setup(t)
 	httpHandler.InitRoutes()
@@ -61,10 +82,12 @@ func TestHTTP_GetAllTransactions(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return true
-	})).Return("", nil)
+	})).Return(analyst, nil)
 	app.On("GetAllTransactions").Return([]*models.Transaction{
 		tx1,
 	}, nil)
+	app.EXPECT().AddUserTransactions("user1", []*models.Transaction{tx1}).Return(nil)
+	passThroughLabels(app, "user1")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -91,11 +114,12 @@ func TestHTTP_GetUserTransactions(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return r.Header.Get("AUTH_TOKEN") != ""
-	})).Return("user1", nil)
+	})).Return(reader, nil)
 	app.On("GetUserTransactions", "user1").Return([]*models.Transaction{
 		tx1,
 		tx2,
 	}, nil)
+	passThroughLabels(app, "user1")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -121,11 +145,12 @@ func TestHTTP_GetTransactionsByRLPHandler(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return true
-	})).Return("", nil)
-	app.On("GetTransactionsByHashes", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", "0xc5f96bf1b54d3314425d2379bd77d7ed4e644f7c6e849a74832028b328d4d798"}).Return([]*models.Transaction{
+	})).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", "0xc5f96bf1b54d3314425d2379bd77d7ed4e644f7c6e849a74832028b328d4d798"}).Return([]*models.Transaction{
 		tx1,
 		tx2,
 	}, nil)
+	passThroughLabels(app, "")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -151,10 +176,11 @@ func TestHTTP_GetTransactionsByHashesHandler(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return true
-	})).Return("", nil)
-	app.On("GetTransactionsByHashes", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return([]*models.Transaction{
+	})).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return([]*models.Transaction{
 		tx1,
 	}, nil)
+	passThroughLabels(app, "")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -181,14 +207,15 @@ func TestHTTP_GetTransactionsByHashesHandler_ForUser(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return r.Header.Get("AUTH_TOKEN") != ""
-	})).Return("user1", nil)
-	app.On("GetTransactionsByHashes", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return([]*models.Transaction{
+	})).Return(reader, nil)
+	app.On("GetTransactionsByHashes", "user1", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return([]*models.Transaction{
 		tx1,
 	}, nil)
 	app.On("AddUserTransactions", "user1", []*models.Transaction{
 		tx1,
 	}).Return(nil)
 
+	passThroughLabels(app, "user1")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -213,8 +240,8 @@ func TestHTTP_GetTransactionsByHashesHandler_Error(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return true
-	})).Return("", nil)
-	app.On("GetTransactionsByHashes", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return(nil, assert.AnError)
+	})).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return(nil, assert.AnError)
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusInternalServerError, w.Code)
 
@@ -240,8 +267,8 @@ func TestHTTP_GetTransactionsByRLPHandler_ForUser(t *testing.T) {
 
 	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
 		return r.Header.Get("AUTH_TOKEN") != ""
-	})).Return("user1", nil)
-	app.On("GetTransactionsByHashes", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", "0xc5f96bf1b54d3314425d2379bd77d7ed4e644f7c6e849a74832028b328d4d798"}).Return([]*models.Transaction{
+	})).Return(reader, nil)
+	app.On("GetTransactionsByHashes", "user1", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", "0xc5f96bf1b54d3314425d2379bd77d7ed4e644f7c6e849a74832028b328d4d798"}).Return([]*models.Transaction{
 		tx1,
 		tx2,
 	}, nil)
@@ -250,6 +277,7 @@ func TestHTTP_GetTransactionsByRLPHandler_ForUser(t *testing.T) {
 		tx2,
 	}).Return(nil)
 
+	passThroughLabels(app, "user1")
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -275,15 +303,14 @@ func TestHTTP_AuthenticateHandler(t *testing.T) {
 
 	w := httptest.NewRecorder()
 
-	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
-		return true
-	})).Return("", nil)
 	app.On("CheckUserCredentials", "user1", "password1").Return(&models.User{
 		ID:       "user1",
 		Username: "user1",
 		Password: "password1",
+		Role:     models.RoleReader,
 	}, nil)
-	auth.On("GenerateToken", "user1").Return("user1.token", nil)
+	auth.On("GenerateToken", "user1", "reader").Return("user1.token", nil)
+	app.EXPECT().IssueRefreshToken("user1").Return("ethr_refresh", nil)
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusOK, w.Code)
 
@@ -297,19 +324,62 @@ func TestHTTP_AuthenticateHandler(t *testing.T) {
 	assert.NoError(t, err)
 
 	assert.Equal(t, "user1.token", response.Token)
+	assert.Equal(t, "ethr_refresh", response.RefreshToken)
 }
 
-func TestHTTP_AuthenticateHandler_Error(t *testing.T) {
+func TestHTTP_RefreshTokenHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+
+	app.EXPECT().RefreshSession("ethr_old").Return(&models.User{ID: "user1", Role: models.RoleAnalyst}, "ethr_new", nil)
+	auth.EXPECT().GenerateToken("user1", "analyst").Return("user1.token", nil)
+
+	r, _ := http.NewRequest("POST", "/api/token/refresh", bytes.NewBufferString(`{"refreshToken":"ethr_old"}`))
+	w := httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.AuthenticateResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, handlers.AuthenticateResponse{Token: "user1.token", RefreshToken: "ethr_new"}, response)
+
+	// Used, expired and unknown refresh tokens are rejected
+	app.EXPECT().RefreshSession("ethr_old2").Return(nil, "", apppkg.ErrUnauthorized)
+	r, _ = http.NewRequest("POST", "/api/token/refresh", bytes.NewBufferString(`{"refreshToken":"ethr_old2"}`))
+	w = httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+}
+
+func TestHTTP_RefreshTokenHandler_ExpiredBearer(t *testing.T) {
 	app, auth, httpHandler := Setup(t)
+
+	app.EXPECT().RefreshSession("ethr_old").Return(&models.User{ID: "user1", Role: models.RoleReader}, "ethr_new", nil)
+	auth.EXPECT().GenerateToken("user1", "reader").Return("user1.token", nil)
+
+	// The expired access token the client still sends is not authenticated
+	r, _ := http.NewRequest("POST", "/api/token/refresh", bytes.NewBufferString(`{"refreshToken":"ethr_old"}`))
+	r.Header.Set("Authorization", "Bearer expired.token")
+	w := httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("WWW-Authenticate"))
+	auth.AssertNotCalled(t, "AuthenticateRequest", mock.Anything)
+
+	var response handlers.AuthenticateResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, handlers.AuthenticateResponse{Token: "user1.token", RefreshToken: "ethr_new"}, response)
+}
+
+func TestHTTP_AuthenticateHandler_Error(t *testing.T) {
+	app, _, httpHandler := Setup(t)
 	httpHandler.InitRoutes()
 	body := []byte(`{"username": "user1", "password": "password1"}`)
 	r, _ := http.NewRequest("POST", "/api/authenticate", bytes.NewBuffer(body))
 
 	w := httptest.NewRecorder()
 
-	auth.EXPECT().AuthenticateRequest(mock.MatchedBy(func(r *http.Request) bool {
-		return true
-	})).Return("", nil)
 	app.On("CheckUserCredentials", "user1", "password1").Return(nil, assert.AnError)
 	httpHandler.Router.ServeHTTP(w, r)
 	assert.Equal(t, http.StatusUnauthorized, w.Code)
@@ -325,3 +395,915 @@ func TestHTTP_AuthenticateHandler_Error(t *testing.T) {
 
 	assert.Equal(t, assert.AnError.Error(), response.Msg)
 }
+
+func TestHTTP_GetTransactionsByHashesHandler_Labelled(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/eth?transactionHashes=0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", nil)
+	w := httptest.NewRecorder()
+
+	labelled := *tx1
+	labelled.FromLabel = "Binance 14"
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return([]*models.Transaction{
+		tx1,
+	}, nil)
+	app.EXPECT().LabelTransactions("", []*models.Transaction{tx1}).Return([]*models.Transaction{&labelled})
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetTransactionsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Len(t, response.Transactions, 1)
+	assert.Equal(t, "Binance 14", response.Transactions[0].FromLabel)
+}
+
+func TestHTTP_GetAddressLabelsHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/labels", nil)
+	w := httptest.NewRecorder()
+
+	labels := []*models.AddressLabel{
+		{Address: "0x28c6c06298d514db089934071355e5743bf21d60", Name: "Binance 14"},
+		{Address: "0x7a250d5630b4cf539739df2c5dacb4c659f2488d", Name: "router", UserID: "user1"},
+	}
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetAddressLabels("user1").Return(labels, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetAddressLabelsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, labels, response.Labels)
+}
+
+func TestHTTP_AddAddressLabelHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := []byte(`{"address": "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", "name": "router"}`)
+	r, _ := http.NewRequest("POST", "/api/labels", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().AddAddressLabel("user1", &models.AddressLabel{Address: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", Name: "router"}, false).Return(nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_AddAddressLabelHandler_Forbidden(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := []byte(`{"address": "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", "name": "router", "global": true}`)
+	r, _ := http.NewRequest("POST", "/api/labels", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().AddAddressLabel("user1", mock.Anything, true).Return(apppkg.ErrForbidden)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTP_DeleteAddressLabelHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("DELETE", "/api/labels/0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D?global=true", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().DeleteAddressLabel("admin", "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", true).Return(nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_GetAddressActivityHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/address/0x28C6c06298d514Db089934071355E5743bf21d60?backfillFrom=100&backfillTo=200", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(analyst, nil)
+	app.EXPECT().BackfillAddress("user1", "0x28C6c06298d514Db089934071355E5743bf21d60", int64(100), int64(200)).Return(&models.BackfillJob{
+		ID:         "job1",
+		Address:    "0x28c6c06298d514db089934071355e5743bf21d60",
+		FromBlock:  100,
+		ToBlock:    200,
+		Checkpoint: 100,
+		Status:     models.BackfillStatusRunning,
+	}, nil)
+	app.EXPECT().GetAddressActivity("0x28C6c06298d514Db089934071355E5743bf21d60", "", 0).Return(&apppkg.AddressActivity{
+		Address:      "0x28c6c06298d514db089934071355e5743bf21d60",
+		Transactions: []*models.Transaction{tx1},
+		AddressStats: models.AddressStats{
+			TransactionCount: 1,
+			SentCount:        1,
+			FirstSeenBlock:   7976373,
+			LastSeenBlock:    7976373,
+			TotalValueIn:     big.NewInt(0),
+			TotalValueOut:    big.NewInt(50000000000000000),
+		},
+	}, nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetAddressActivityResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, response.TransactionCount)
+	assert.Equal(t, 1, response.SentCount)
+	assert.Equal(t, "job1", response.BackfillJob.ID)
+	assert.Equal(t, "0x28c6c06298d514db089934071355e5743bf21d60", response.BackfillJob.Address)
+	assert.Equal(t, "50000000000000000", response.TotalValueOut.String())
+	assert.Equal(t, tx1, response.Transactions[0])
+}
+
+func TestHTTP_GetAddressActivityHandler_Page(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/address/0x28C6c06298d514Db089934071355E5743bf21d60?limit=1&cursor=7976000_0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetAddressActivity("0x28C6c06298d514Db089934071355E5743bf21d60", "7976000_0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", 1).Return(&apppkg.AddressActivity{
+		Address:      "0x28c6c06298d514db089934071355e5743bf21d60",
+		Transactions: []*models.Transaction{tx1},
+		NextCursor:   "7976373_0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524",
+		AddressStats: models.AddressStats{
+			TransactionCount: 3,
+			TotalValueIn:     big.NewInt(0),
+			TotalValueOut:    big.NewInt(0),
+		},
+	}, nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetAddressActivityResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 3, response.TransactionCount)
+	assert.Len(t, response.Transactions, 1)
+	assert.Equal(t, "7976373_0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", response.NextCursor)
+}
+
+func TestHTTP_GetAddressActivityHandler_InvalidLimit(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/address/0x28C6c06298d514Db089934071355E5743bf21d60?limit=all", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHTTP_GetAddressActivityHandler_InvalidRange(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/address/0x28C6c06298d514Db089934071355E5743bf21d60?backfillFrom=100", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(analyst, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHTTP_StartBackfillHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/admin/backfill?from=100&to=199", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().StartBackfill("admin", int64(100), int64(199)).Return(&models.BackfillJob{
+		ID:         "job1",
+		FromBlock:  100,
+		ToBlock:    199,
+		Checkpoint: 150,
+		Status:     models.BackfillStatusRunning,
+	}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.BackfillJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "job1", response.ID)
+	assert.Equal(t, models.BackfillStatusRunning, response.Status)
+	assert.Equal(t, 0.5, response.Progress)
+}
+
+func TestHTTP_StartBackfillHandler_Forbidden(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/admin/backfill?from=100&to=199", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTP_GetBackfillJobHandler_Analyst(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/backfill/job1", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(analyst, nil)
+	app.EXPECT().GetBackfillJob("user1", "job1").Return(&models.BackfillJob{
+		ID:         "job1",
+		Address:    "0x28c6c06298d514db089934071355e5743bf21d60",
+		FromBlock:  100,
+		ToBlock:    199,
+		Checkpoint: 200,
+		Status:     models.BackfillStatusCompleted,
+	}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.BackfillJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, models.BackfillStatusCompleted, response.Status)
+	assert.Equal(t, float64(1), response.Progress)
+}
+
+func TestHTTP_CancelBackfillHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("DELETE", "/api/admin/backfill/job1", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().CancelBackfill("admin", "job1").Return(nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_StartLookupJobHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := []byte(`{"transactionHashes": ["0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"]}`)
+	r, _ := http.NewRequest("POST", "/api/jobs/lookup", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().StartLookupJob("user1", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return(&apppkg.LookupJob{
+		ID:     "job1",
+		Status: apppkg.LookupJobRunning,
+		Total:  1,
+	}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.LookupJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, "job1", response.ID)
+	assert.Equal(t, apppkg.LookupJobRunning, response.Status)
+	assert.Equal(t, 1, response.Total)
+	assert.Empty(t, response.Transactions)
+}
+
+func TestHTTP_StartLookupJobHandler_TooManyRunning(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := []byte(`{"transactionHashes": ["0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"]}`)
+	r, _ := http.NewRequest("POST", "/api/jobs/lookup", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().StartLookupJob("user1", mock.Anything).Return(nil, fmt.Errorf("%w: 3 lookup jobs are running", apppkg.ErrTooManyRequests))
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+}
+
+func TestHTTP_GetLookupJobHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/jobs/job1", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetLookupJob("user1", "job1").Return(&apppkg.LookupJob{
+		ID:           "job1",
+		Status:       apppkg.LookupJobRunning,
+		Total:        2,
+		Processed:    1,
+		Transactions: []*models.Transaction{tx1},
+	}, nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.LookupJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, 1, response.Processed)
+	assert.Equal(t, []*models.Transaction{tx1}, response.Transactions)
+}
+
+func TestHTTP_GetLookupJobHandler_Partial(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/jobs/job1", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetLookupJob("user1", "job1").Return(&apppkg.LookupJob{
+		ID:           "job1",
+		Status:       apppkg.LookupJobPartial,
+		Total:        2,
+		Processed:    2,
+		Transactions: []*models.Transaction{tx1},
+		FailedHashes: []string{"0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"},
+	}, nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.LookupJobResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, apppkg.LookupJobPartial, response.Status)
+	assert.Equal(t, []string{"0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"}, response.FailedHashes)
+}
+
+func TestHTTP_GetLookupJobHandler_NotFound(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/jobs/job1", nil)
+	w := httptest.NewRecorder()
+
+	// Requests without a valid token are anonymous
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, authpkg.ErrNoToken)
+	app.EXPECT().GetLookupJob("", "job1").Return(nil, apppkg.ErrNotFound)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHTTP_PostTransactionsHandler_JSON(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := []byte(`["0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"]`)
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.On("GetTransactionsByHashes", "user1", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"}).Return([]*models.Transaction{
+		tx1,
+		tx2,
+	}, nil)
+	app.On("AddUserTransactions", "user1", []*models.Transaction{
+		tx1,
+		tx2,
+	}).Return(nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetTransactionsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Len(t, response.Transactions, 2)
+	assert.Equal(t, tx1, response.Transactions[0])
+	assert.Equal(t, tx2, response.Transactions[1])
+}
+
+func TestHTTP_PostTransactionsHandler_RLP(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body, _ := hex.DecodeString("f90110b842307839623266366133633265316165643263636366393262613636366332326430353361643064386135646137616131666435343737646364363537376234353234b842307835613537653330353163623932653264343832353135623037653762336431383531373232613734363534363537626436346131346333396361336639636632b842307837316239653262343464343034393863303861363239383866616337373664306561633062356239363133633337663966366639613462383838613862303537b842307863356639366266316235346433333134343235643233373962643737643765643465363434663763366538343961373438333230323862333238643464373938")
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewBuffer(body))
+	r.Header.Set("Content-Type", "application/x-rlp")
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2", "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057", "0xc5f96bf1b54d3314425d2379bd77d7ed4e644f7c6e849a74832028b328d4d798"}).Return([]*models.Transaction{
+		tx1,
+		tx2,
+	}, nil)
+	passThroughLabels(app, "")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetTransactionsResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Len(t, response.Transactions, 2)
+}
+
+func TestHTTP_PostTransactionsHandler_InvalidBody(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewBufferString(`{"transactionHashes": "0x9b"}`))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHTTP_PostTransactionsHandler_UnsupportedContentType(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewBufferString("0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"))
+	r.Header.Set("Content-Type", "text/plain")
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestHTTP_PostTransactionsHandler_MissingContentType(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewBufferString(`["0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"]`))
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusUnsupportedMediaType, w.Code)
+}
+
+func TestHTTP_PostTransactionsHandler_TooLarge(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/eth", bytes.NewReader(make([]byte, 10<<20+1)))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+}
+
+func TestHTTP_PostTransactionsHandler_ReadError(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/eth", iotest.ErrReader(io.ErrUnexpectedEOF))
+	r.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+
+	// Failing to read the body is not the client sending too much
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHTTP_GetTransactionsByHashesHandler_InvalidHashes(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/eth?transactionHashes=0x1234", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, nil)
+	app.On("GetTransactionsByHashes", "", []string{"0x1234"}).Return(nil, &apppkg.InvalidHashesError{Hashes: []string{"0x1234"}})
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response handlers.ErrorResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, []string{"0x1234"}, response.InvalidHashes)
+}
+
+func TestHTTP_GetTransactionsByHashesHandler_Overloaded(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/eth?transactionHashes=0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.On("GetTransactionsByHashes", "user1", []string{"0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"}).Return(nil, apppkg.ErrOverloaded)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+}
+
+func TestHTTP_PruneHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/admin/prune?dryRun=true", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().Prune("admin", true).Return(&apppkg.PruneReport{DryRun: true, UserTransactions: 3, Transactions: 5}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.PruneResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+
+	assert.Equal(t, handlers.PruneResponse{DryRun: true, UserTransactions: 3, Transactions: 5}, response)
+}
+
+func TestHTTP_PruneHandler_InvalidDryRun(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/admin/prune?dryRun=maybe", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHTTP_MetricsHandler(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/admin/metrics", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]json.RawMessage
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.NotContains(t, response, "cmdline")
+	assert.NotContains(t, response, "memstats")
+}
+
+func TestHTTP_DebugVarsNotServed(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/debug/vars", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil).Maybe()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.NotEqual(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "memstats")
+}
+
+func TestHTTP_CORS(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	httpHandler.SetCORSOrigins([]string{"https://app.example.com"})
+
+	// Preflight requests are answered without authentication
+	r, _ := http.NewRequest("OPTIONS", "/api/eth", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w := httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "AUTH_TOKEN")
+	assert.Contains(t, w.Header().Get("Access-Control-Allow-Headers"), "Authorization")
+
+	r, _ = http.NewRequest("GET", "/api/my", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetUserTransactions("user1").Return([]*models.Transaction{}, nil)
+	passThroughLabels(app, "user1")
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "https://app.example.com", w.Header().Get("Access-Control-Allow-Origin"))
+	assert.Contains(t, w.Header().Get("Access-Control-Expose-Headers"), "WWW-Authenticate")
+
+	// Other origins get no CORS headers, also after the origins changed
+	httpHandler.SetCORSOrigins([]string{"https://other.example.com"})
+	r, _ = http.NewRequest("OPTIONS", "/api/eth", nil)
+	r.Header.Set("Origin", "https://app.example.com")
+	w = httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Empty(t, w.Header().Get("Access-Control-Allow-Origin"))
+}
+
+func TestHTTP_RegisterHandler(t *testing.T) {
+	app, _, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"username":"erin","password":"supersecret"}`)
+	r, _ := http.NewRequest("POST", "/api/register", body)
+	w := httptest.NewRecorder()
+
+	app.EXPECT().Register("erin", "supersecret").Return(&models.User{ID: "user5", Username: "erin", Password: "hash"}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "hash")
+
+	var response handlers.UserResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, handlers.UserResponse{ID: "user5", Username: "erin"}, response)
+}
+
+func TestHTTP_RegisterHandler_Disabled(t *testing.T) {
+	app, _, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"username":"erin","password":"supersecret"}`)
+	r, _ := http.NewRequest("POST", "/api/register", body)
+	w := httptest.NewRecorder()
+
+	app.EXPECT().Register("erin", "supersecret").Return(nil, apppkg.ErrForbidden)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTP_GetProfileHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/me", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().GetProfile("user1").Return(&models.User{ID: "user1", Username: "alice", Role: models.RoleAdmin}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.UserResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, handlers.UserResponse{ID: "user1", Username: "alice", Role: models.RoleAdmin}, response)
+}
+
+func TestHTTP_ChangePasswordHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"currentPassword":"password1","newPassword":"newpassword"}`)
+	r, _ := http.NewRequest("POST", "/api/me/password", body)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().ChangePassword("user1", "password1", "newpassword").Return(nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_LogoutHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	expiresAt := time.Now().Add(15 * time.Minute)
+	session := reader
+	session.TokenID = "jti1"
+	session.TokenExpiresAt = expiresAt
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(session, nil)
+	app.EXPECT().Logout("user1", "jti1", expiresAt, "ethr_refresh").Return(nil).Once()
+	r, _ := http.NewRequest("POST", "/api/logout", bytes.NewBufferString(`{"refreshToken":"ethr_refresh"}`))
+	w := httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The body is optional
+	app.EXPECT().Logout("user1", "jti1", expiresAt, "").Return(nil).Once()
+	r, _ = http.NewRequest("POST", "/api/logout", http.NoBody)
+	w = httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_JWKSHandler(t *testing.T) {
+	_, auth, httpHandler := Setup(t)
+	jwks := authpkg.JWKS{Keys: []authpkg.JWK{{Kty: "EC", Use: "sig", Alg: "ES256", Kid: "key1", Crv: "P-256", X: "x", Y: "y"}}}
+
+	// The keys are public, requests are not authenticated
+	auth.EXPECT().JWKS().Return(jwks, nil).Once()
+	r, _ := http.NewRequest("GET", "/.well-known/jwks.json", nil)
+	w := httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/json", w.Header().Get("Content-Type"))
+	assert.Equal(t, "public, max-age=300", w.Header().Get("Cache-Control"))
+	assert.JSONEq(t, `{"keys":[{"kty":"EC","use":"sig","alg":"ES256","kid":"key1","crv":"P-256","x":"x","y":"y"}]}`, w.Body.String())
+
+	auth.EXPECT().JWKS().Return(authpkg.JWKS{}, assert.AnError).Once()
+	w = httptest.NewRecorder()
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+}
+
+func TestHTTP_GetUsersHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/admin/users", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().ListUsers("admin").Return([]*models.User{{ID: "user1", Username: "alice", Role: models.RoleAdmin}, {ID: "user2", Username: "bob", Role: models.RoleReader, Disabled: true}}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetUsersResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []handlers.UserResponse{{ID: "user1", Username: "alice", Role: models.RoleAdmin}, {ID: "user2", Username: "bob", Role: models.RoleReader, Disabled: true}}, response.Users)
+}
+
+func TestHTTP_AddUserHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"username":"erin","password":"supersecret","role":"analyst"}`)
+	r, _ := http.NewRequest("POST", "/api/admin/users", body)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().AddUser("admin", "erin", "supersecret", models.RoleAnalyst).Return(&models.User{ID: "user5", Username: "erin", Role: models.RoleAnalyst}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"analyst"`)
+}
+
+func TestHTTP_AddUserHandler_DefaultRole(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"username":"erin","password":"supersecret"}`)
+	r, _ := http.NewRequest("POST", "/api/admin/users", body)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().AddUser("admin", "erin", "supersecret", models.RoleReader).Return(nil, apppkg.ErrForbidden)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTP_DisableUserHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("POST", "/api/admin/users/user2/disable", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().SetUserDisabled("admin", "user2", true).Return(&models.User{ID: "user2", Username: "bob", Role: models.RoleReader, Disabled: true}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"disabled":true`)
+}
+
+func TestHTTP_DeleteUserHandler_NotFound(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("DELETE", "/api/admin/users/unknown", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().DeleteUser("admin", "unknown").Return(apppkg.ErrNotFound)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHTTP_ResetUserPasswordHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"password":"newpassword"}`)
+	r, _ := http.NewRequest("POST", "/api/admin/users/user2/password", body)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().ResetUserPassword("admin", "user2", "newpassword").Return(nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestHTTP_SetUserRoleHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"role":"analyst"}`)
+	r, _ := http.NewRequest("POST", "/api/admin/users/user2/role", body)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(admin, nil)
+	app.EXPECT().SetUserRole("admin", "user2", models.RoleAnalyst).Return(&models.User{ID: "user2", Username: "bob", Role: models.RoleAnalyst}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"role":"analyst"`)
+}
+
+func TestHTTP_RolePolicies(t *testing.T) {
+	tests := map[string]struct {
+		method, path string
+		identity     authpkg.Identity
+		code         int
+	}{
+		"anonymous reader route":  {"GET", "/api/my", authpkg.Identity{}, http.StatusUnauthorized},
+		"anonymous analyst route": {"GET", "/api/all", authpkg.Identity{}, http.StatusUnauthorized},
+		"reader on analyst route": {"GET", "/api/all", reader, http.StatusForbidden},
+		"reader on admin route":   {"GET", "/api/admin/users", reader, http.StatusForbidden},
+		"analyst on admin route":  {"POST", "/api/admin/prune", analyst, http.StatusForbidden},
+		"analyst on metrics":      {"GET", "/api/admin/metrics", analyst, http.StatusForbidden},
+		"reader backfilling":      {"GET", "/api/address/0x28C6c06298d514Db089934071355E5743bf21d60?backfillFrom=1&backfillTo=2", reader, http.StatusForbidden},
+		"reader on backfill job":  {"GET", "/api/backfill/job1", reader, http.StatusForbidden},
+		"key without scope":       {"GET", "/api/all", authpkg.Identity{UserID: "user1", Role: "admin", Scopes: []models.Scope{models.ScopeLabels}}, http.StatusForbidden},
+		"key without admin scope": {"GET", "/api/admin/users", authpkg.Identity{UserID: "admin", Role: "admin", Scopes: []models.Scope{models.ScopeTransactions}}, http.StatusForbidden},
+		"key on public route":     {"GET", "/api/eth?transactionHashes=0x9b", authpkg.Identity{UserID: "user1", Role: "reader", Scopes: []models.Scope{models.ScopeAccount}}, http.StatusForbidden},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, auth, httpHandler := Setup(t)
+			r, _ := http.NewRequest(tt.method, tt.path, nil)
+			w := httptest.NewRecorder()
+
+			auth.EXPECT().AuthenticateRequest(mock.Anything).Return(tt.identity, nil)
+			httpHandler.Router.ServeHTTP(w, r)
+			assert.Equal(t, tt.code, w.Code)
+		})
+	}
+}
+
+func TestHTTP_InvalidCredentials(t *testing.T) {
+	tests := map[string]struct {
+		path      string
+		err       error
+		code      int
+		challenge string
+	}{
+		"expired token on public route": {
+			path:      "/api/eth?transactionHashes=0x9b",
+			err:       fmt.Errorf("%w: token is expired", authpkg.ErrInvalidToken),
+			code:      http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="invalid token: token is expired"`,
+		},
+		"revoked token": {
+			path:      "/api/my",
+			err:       fmt.Errorf("%w: token revoked", authpkg.ErrInvalidToken),
+			code:      http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="invalid token: token revoked"`,
+		},
+		"invalid API key": {
+			path:      "/api/all",
+			err:       fmt.Errorf("%w: unknown key", authpkg.ErrInvalidAPIKey),
+			code:      http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="invalid API key: unknown key"`,
+		},
+		"quotes in the description": {
+			path:      "/api/my",
+			err:       fmt.Errorf(`%w: unknown key "k1"`, authpkg.ErrInvalidToken),
+			code:      http.StatusUnauthorized,
+			challenge: `Bearer error="invalid_token", error_description="invalid token: unknown key k1"`,
+		},
+		"no token on reader route": {
+			path:      "/api/my",
+			err:       authpkg.ErrNoToken,
+			code:      http.StatusUnauthorized,
+			challenge: "Bearer",
+		},
+		"revocation list unavailable": {
+			path: "/api/my",
+			err:  errors.New("checking the revocation list: connection refused"),
+			code: http.StatusInternalServerError,
+		},
+	}
+
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, auth, httpHandler := Setup(t)
+			r, _ := http.NewRequest("GET", tt.path, nil)
+			w := httptest.NewRecorder()
+
+			// The handler is not called, invalid credentials are not downgraded to anonymous
+			auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{}, tt.err)
+			httpHandler.Router.ServeHTTP(w, r)
+			assert.Equal(t, tt.code, w.Code)
+			assert.Equal(t, tt.challenge, w.Header().Get("WWW-Authenticate"))
+			assert.NotContains(t, w.Body.String(), "connection refused")
+		})
+	}
+}
+
+func TestHTTP_CreateAPIKeyHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"name":"batch","scopes":["transactions"],"expiresAt":"2030-01-01T00:00:00Z"}`)
+	r, _ := http.NewRequest("POST", "/api/me/keys", body)
+	w := httptest.NewRecorder()
+
+	expiresAt := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().CreateAPIKey("user1", []models.Scope(nil), "batch", []models.Scope{models.ScopeTransactions}, &expiresAt).Return(&models.APIKey{
+		ID:        "key1",
+		UserID:    "user1",
+		Name:      "batch",
+		Prefix:    "ethf_01234567",
+		Hash:      "hash",
+		Scopes:    []models.Scope{models.ScopeTransactions},
+		ExpiresAt: &expiresAt,
+	}, "ethf_0123456789", nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.APIKeyResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, "ethf_0123456789", response.Key)
+	assert.Equal(t, "ethf_01234567", response.Prefix)
+	assert.NotContains(t, w.Body.String(), "hash")
+}
+
+func TestHTTP_CreateAPIKeyHandler_ScopedKey(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	body := bytes.NewBufferString(`{"name":"escalate"}`)
+	r, _ := http.NewRequest("POST", "/api/me/keys", body)
+	w := httptest.NewRecorder()
+
+	// The scopes of the key the request is authenticated with limit the new key
+	scopes := []models.Scope{models.ScopeAccount}
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(authpkg.Identity{UserID: "user1", Role: "reader", Scopes: scopes}, nil)
+	app.EXPECT().CreateAPIKey("user1", scopes, "escalate", []models.Scope(nil), (*time.Time)(nil)).Return(nil, "", apppkg.ErrForbidden)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+func TestHTTP_GetAPIKeysHandler(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("GET", "/api/me/keys", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().ListAPIKeys("user1").Return([]*models.APIKey{{ID: "key1", Name: "batch", Prefix: "ethf_01234567", Hash: "hash"}}, nil)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response handlers.GetAPIKeysResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NoError(t, err)
+	assert.Equal(t, []handlers.APIKeyResponse{{ID: "key1", Name: "batch", Prefix: "ethf_01234567"}}, response.Keys)
+}
+
+func TestHTTP_RevokeAPIKeyHandler_NotFound(t *testing.T) {
+	app, auth, httpHandler := Setup(t)
+	r, _ := http.NewRequest("DELETE", "/api/me/keys/key2", nil)
+	w := httptest.NewRecorder()
+
+	auth.EXPECT().AuthenticateRequest(mock.Anything).Return(reader, nil)
+	app.EXPECT().RevokeAPIKey("user1", "key2").Return(apppkg.ErrNotFound)
+	httpHandler.Router.ServeHTTP(w, r)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
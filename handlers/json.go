@@ -1,6 +1,10 @@
 package handlers
 
-import "eth-fetcher/database/models"
+import (
+	"eth-fetcher/database/models"
+	"math/big"
+	"time"
+)
 
 type GetTransactionsResponse struct {
 	Transactions []*models.Transaction `json:"transactions"`
@@ -19,9 +23,61 @@ type GetTransactionsResponse struct {
 // 	Value           int64       `json:"value"`
 // }
 
+type GetAddressActivityResponse struct {
+	Address               string                `json:"address"`
+	TransactionCount      int                   `json:"transactionCount"`
+	SentCount             int                   `json:"sentCount"`
+	ReceivedCount         int                   `json:"receivedCount"`
+	ContractCreationCount int                   `json:"contractCreationCount"`
+	FirstSeenBlock        int64                 `json:"firstSeenBlock"`
+	LastSeenBlock         int64                 `json:"lastSeenBlock"`
+	TotalValueIn          *big.Int              `json:"totalValueIn"`
+	TotalValueOut         *big.Int              `json:"totalValueOut"`
+	BackfillJob           *BackfillJobResponse  `json:"backfillJob,omitempty"`
+	Transactions          []*models.Transaction `json:"transactions"`
+	NextCursor            string                `json:"nextCursor,omitempty"`
+}
+
+type BackfillJobResponse struct {
+	*models.BackfillJob
+	Progress float64 `json:"progress"`
+}
+
+func newBackfillJobResponse(job *models.BackfillJob) BackfillJobResponse {
+	return BackfillJobResponse{BackfillJob: job, Progress: job.Progress()}
+}
+
+type GetBackfillJobsResponse struct {
+	Jobs []BackfillJobResponse `json:"jobs"`
+}
+
+type PruneResponse struct {
+	DryRun           bool  `json:"dryRun"`
+	UserTransactions int64 `json:"userTransactions"`
+	Transactions     int64 `json:"transactions"`
+}
+
+type StartLookupJobRequest struct {
+	TransactionHashes []string `json:"transactionHashes"`
+}
+
+type LookupJobResponse struct {
+	ID           string                `json:"id"`
+	Status       string                `json:"status"`
+	Total        int                   `json:"total"`
+	Processed    int                   `json:"processed"`
+	Transactions []*models.Transaction `json:"transactions"`
+	FailedHashes []string              `json:"failedHashes,omitempty"`
+}
+
 type ErrorResponse struct {
-	Msg  string `json:"error"`
-	Code int    `json:"code"`
+	Msg           string   `json:"error"`
+	Code          int      `json:"code"`
+	InvalidHashes []string `json:"invalidHashes,omitempty"`
+	// RetryAfter is sent in the Retry-After header in seconds when set.
+	RetryAfter int `json:"-"`
+	// Challenge is sent in the WWW-Authenticate header when set.
+	Challenge string `json:"-"`
 }
 
 func (e *ErrorResponse) Error() string {
@@ -34,5 +90,107 @@ type AuthenticateRequest struct {
 }
 
 type AuthenticateResponse struct {
-	Token string `json:"token"`
+	Token        string `json:"token"`
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type LogoutRequest struct {
+	RefreshToken string `json:"refreshToken"`
+}
+
+type RegisterRequest struct {
+	Username string `json:"username"`
+	Password string `json:"password"`
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"currentPassword"`
+	NewPassword     string `json:"newPassword"`
+}
+
+type AddUserRequest struct {
+	Username string      `json:"username"`
+	Password string      `json:"password"`
+	Role     models.Role `json:"role"`
+}
+
+type SetUserRoleRequest struct {
+	Role models.Role `json:"role"`
+}
+
+type ResetPasswordRequest struct {
+	Password string `json:"password"`
+}
+
+// UserResponse is a user without the password hash.
+type UserResponse struct {
+	ID        string      `json:"id"`
+	Username  string      `json:"username"`
+	Role      models.Role `json:"role"`
+	Disabled  bool        `json:"disabled"`
+	Issuer    string      `json:"issuer,omitempty"`
+	CreatedAt time.Time   `json:"createdAt"`
+}
+
+func newUserResponse(user *models.User) UserResponse {
+	return UserResponse{
+		ID:        user.ID,
+		Username:  user.Username,
+		Role:      user.Role,
+		Disabled:  user.Disabled,
+		Issuer:    user.Issuer.String,
+		CreatedAt: user.CreatedAt,
+	}
+}
+
+type GetUsersResponse struct {
+	Users []UserResponse `json:"users"`
+}
+
+type CreateAPIKeyRequest struct {
+	Name      string         `json:"name"`
+	Scopes    []models.Scope `json:"scopes"`
+	ExpiresAt *time.Time     `json:"expiresAt"`
+}
+
+// APIKeyResponse is an API key without its hash, Key is only set when it is created.
+type APIKeyResponse struct {
+	ID         string         `json:"id"`
+	Name       string         `json:"name"`
+	Prefix     string         `json:"prefix"`
+	Key        string         `json:"key,omitempty"`
+	Scopes     []models.Scope `json:"scopes,omitempty"`
+	ExpiresAt  *time.Time     `json:"expiresAt,omitempty"`
+	LastUsedAt *time.Time     `json:"lastUsedAt,omitempty"`
+	CreatedAt  time.Time      `json:"createdAt"`
+}
+
+func newAPIKeyResponse(key *models.APIKey) APIKeyResponse {
+	return APIKeyResponse{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     key.Scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
+
+type GetAPIKeysResponse struct {
+	Keys []APIKeyResponse `json:"keys"`
+}
+
+type GetAddressLabelsResponse struct {
+	Labels []*models.AddressLabel `json:"labels"`
+}
+
+type AddAddressLabelRequest struct {
+	Address string `json:"address"`
+	Name    string `json:"name"`
+	Global  bool   `json:"global"`
 }
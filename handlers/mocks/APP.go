@@ -3,9 +3,13 @@
 package mocks
 
 import (
-	models "eth-fetcher/database/models"
+	app "eth-fetcher/app"
 
 	mock "github.com/stretchr/testify/mock"
+
+	models "eth-fetcher/database/models"
+
+	time "time"
 )
 
 // APP is an autogenerated mock type for the APP type
@@ -21,6 +25,115 @@ func (_m *APP) EXPECT() *APP_Expecter {
 	return &APP_Expecter{mock: &_m.Mock}
 }
 
+// AddAddressLabel provides a mock function with given fields: userID, label, global
+func (_m *APP) AddAddressLabel(userID string, label *models.AddressLabel, global bool) error {
+	ret := _m.Called(userID, label, global)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddAddressLabel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, *models.AddressLabel, bool) error); ok {
+		r0 = rf(userID, label, global)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_AddAddressLabel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddAddressLabel'
+type APP_AddAddressLabel_Call struct {
+	*mock.Call
+}
+
+// AddAddressLabel is a helper method to define mock.On call
+//   - userID string
+//   - label *models.AddressLabel
+//   - global bool
+func (_e *APP_Expecter) AddAddressLabel(userID interface{}, label interface{}, global interface{}) *APP_AddAddressLabel_Call {
+	return &APP_AddAddressLabel_Call{Call: _e.mock.On("AddAddressLabel", userID, label, global)}
+}
+
+func (_c *APP_AddAddressLabel_Call) Run(run func(userID string, label *models.AddressLabel, global bool)) *APP_AddAddressLabel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(*models.AddressLabel), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *APP_AddAddressLabel_Call) Return(_a0 error) *APP_AddAddressLabel_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_AddAddressLabel_Call) RunAndReturn(run func(string, *models.AddressLabel, bool) error) *APP_AddAddressLabel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// AddUser provides a mock function with given fields: userID, username, password, role
+func (_m *APP) AddUser(userID string, username string, password string, role models.Role) (*models.User, error) {
+	ret := _m.Called(userID, username, password, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for AddUser")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, string, models.Role) (*models.User, error)); ok {
+		return rf(userID, username, password, role)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, string, models.Role) *models.User); ok {
+		r0 = rf(userID, username, password, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, string, models.Role) error); ok {
+		r1 = rf(userID, username, password, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_AddUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'AddUser'
+type APP_AddUser_Call struct {
+	*mock.Call
+}
+
+// AddUser is a helper method to define mock.On call
+//   - userID string
+//   - username string
+//   - password string
+//   - role models.Role
+func (_e *APP_Expecter) AddUser(userID interface{}, username interface{}, password interface{}, role interface{}) *APP_AddUser_Call {
+	return &APP_AddUser_Call{Call: _e.mock.On("AddUser", userID, username, password, role)}
+}
+
+func (_c *APP_AddUser_Call) Run(run func(userID string, username string, password string, role models.Role)) *APP_AddUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string), args[3].(models.Role))
+	})
+	return _c
+}
+
+func (_c *APP_AddUser_Call) Return(_a0 *models.User, _a1 error) *APP_AddUser_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_AddUser_Call) RunAndReturn(run func(string, string, string, models.Role) (*models.User, error)) *APP_AddUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // AddUserTransactions provides a mock function with given fields: userID, transactions
 func (_m *APP) AddUserTransactions(userID string, transactions []*models.Transaction) error {
 	ret := _m.Called(userID, transactions)
@@ -68,29 +181,29 @@ func (_c *APP_AddUserTransactions_Call) RunAndReturn(run func(string, []*models.
 	return _c
 }
 
-// CheckUserCredentials provides a mock function with given fields: username, password
-func (_m *APP) CheckUserCredentials(username string, password string) (*models.User, error) {
-	ret := _m.Called(username, password)
+// BackfillAddress provides a mock function with given fields: userID, address, fromBlock, toBlock
+func (_m *APP) BackfillAddress(userID string, address string, fromBlock int64, toBlock int64) (*models.BackfillJob, error) {
+	ret := _m.Called(userID, address, fromBlock, toBlock)
 
 	if len(ret) == 0 {
-		panic("no return value specified for CheckUserCredentials")
+		panic("no return value specified for BackfillAddress")
 	}
 
-	var r0 *models.User
+	var r0 *models.BackfillJob
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string, string) (*models.User, error)); ok {
-		return rf(username, password)
+	if rf, ok := ret.Get(0).(func(string, string, int64, int64) (*models.BackfillJob, error)); ok {
+		return rf(userID, address, fromBlock, toBlock)
 	}
-	if rf, ok := ret.Get(0).(func(string, string) *models.User); ok {
-		r0 = rf(username, password)
+	if rf, ok := ret.Get(0).(func(string, string, int64, int64) *models.BackfillJob); ok {
+		r0 = rf(userID, address, fromBlock, toBlock)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*models.User)
+			r0 = ret.Get(0).(*models.BackfillJob)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string, string) error); ok {
-		r1 = rf(username, password)
+	if rf, ok := ret.Get(1).(func(string, string, int64, int64) error); ok {
+		r1 = rf(userID, address, fromBlock, toBlock)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -98,115 +211,155 @@ func (_m *APP) CheckUserCredentials(username string, password string) (*models.U
 	return r0, r1
 }
 
-// APP_CheckUserCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckUserCredentials'
-type APP_CheckUserCredentials_Call struct {
+// APP_BackfillAddress_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BackfillAddress'
+type APP_BackfillAddress_Call struct {
 	*mock.Call
 }
 
-// CheckUserCredentials is a helper method to define mock.On call
-//   - username string
-//   - password string
-func (_e *APP_Expecter) CheckUserCredentials(username interface{}, password interface{}) *APP_CheckUserCredentials_Call {
-	return &APP_CheckUserCredentials_Call{Call: _e.mock.On("CheckUserCredentials", username, password)}
+// BackfillAddress is a helper method to define mock.On call
+//   - userID string
+//   - address string
+//   - fromBlock int64
+//   - toBlock int64
+func (_e *APP_Expecter) BackfillAddress(userID interface{}, address interface{}, fromBlock interface{}, toBlock interface{}) *APP_BackfillAddress_Call {
+	return &APP_BackfillAddress_Call{Call: _e.mock.On("BackfillAddress", userID, address, fromBlock, toBlock)}
 }
 
-func (_c *APP_CheckUserCredentials_Call) Run(run func(username string, password string)) *APP_CheckUserCredentials_Call {
+func (_c *APP_BackfillAddress_Call) Run(run func(userID string, address string, fromBlock int64, toBlock int64)) *APP_BackfillAddress_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string), args[1].(string))
+		run(args[0].(string), args[1].(string), args[2].(int64), args[3].(int64))
 	})
 	return _c
 }
 
-func (_c *APP_CheckUserCredentials_Call) Return(_a0 *models.User, _a1 error) *APP_CheckUserCredentials_Call {
+func (_c *APP_BackfillAddress_Call) Return(_a0 *models.BackfillJob, _a1 error) *APP_BackfillAddress_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *APP_CheckUserCredentials_Call) RunAndReturn(run func(string, string) (*models.User, error)) *APP_CheckUserCredentials_Call {
+func (_c *APP_BackfillAddress_Call) RunAndReturn(run func(string, string, int64, int64) (*models.BackfillJob, error)) *APP_BackfillAddress_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetAllTransactions provides a mock function with given fields:
-func (_m *APP) GetAllTransactions() ([]*models.Transaction, error) {
-	ret := _m.Called()
+// CancelBackfill provides a mock function with given fields: userID, id
+func (_m *APP) CancelBackfill(userID string, id string) error {
+	ret := _m.Called(userID, id)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetAllTransactions")
+		panic("no return value specified for CancelBackfill")
 	}
 
-	var r0 []*models.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func() ([]*models.Transaction, error)); ok {
-		return rf()
-	}
-	if rf, ok := ret.Get(0).(func() []*models.Transaction); ok {
-		r0 = rf()
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, id)
 	} else {
-		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
-		}
+		r0 = ret.Error(0)
 	}
 
-	if rf, ok := ret.Get(1).(func() error); ok {
-		r1 = rf()
+	return r0
+}
+
+// APP_CancelBackfill_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CancelBackfill'
+type APP_CancelBackfill_Call struct {
+	*mock.Call
+}
+
+// CancelBackfill is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) CancelBackfill(userID interface{}, id interface{}) *APP_CancelBackfill_Call {
+	return &APP_CancelBackfill_Call{Call: _e.mock.On("CancelBackfill", userID, id)}
+}
+
+func (_c *APP_CancelBackfill_Call) Run(run func(userID string, id string)) *APP_CancelBackfill_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_CancelBackfill_Call) Return(_a0 error) *APP_CancelBackfill_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_CancelBackfill_Call) RunAndReturn(run func(string, string) error) *APP_CancelBackfill_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ChangePassword provides a mock function with given fields: userID, currentPassword, newPassword
+func (_m *APP) ChangePassword(userID string, currentPassword string, newPassword string) error {
+	ret := _m.Called(userID, currentPassword, newPassword)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ChangePassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(userID, currentPassword, newPassword)
 	} else {
-		r1 = ret.Error(1)
+		r0 = ret.Error(0)
 	}
 
-	return r0, r1
+	return r0
 }
 
-// APP_GetAllTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTransactions'
-type APP_GetAllTransactions_Call struct {
+// APP_ChangePassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ChangePassword'
+type APP_ChangePassword_Call struct {
 	*mock.Call
 }
 
-// GetAllTransactions is a helper method to define mock.On call
-func (_e *APP_Expecter) GetAllTransactions() *APP_GetAllTransactions_Call {
-	return &APP_GetAllTransactions_Call{Call: _e.mock.On("GetAllTransactions")}
+// ChangePassword is a helper method to define mock.On call
+//   - userID string
+//   - currentPassword string
+//   - newPassword string
+func (_e *APP_Expecter) ChangePassword(userID interface{}, currentPassword interface{}, newPassword interface{}) *APP_ChangePassword_Call {
+	return &APP_ChangePassword_Call{Call: _e.mock.On("ChangePassword", userID, currentPassword, newPassword)}
 }
 
-func (_c *APP_GetAllTransactions_Call) Run(run func()) *APP_GetAllTransactions_Call {
+func (_c *APP_ChangePassword_Call) Run(run func(userID string, currentPassword string, newPassword string)) *APP_ChangePassword_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run()
+		run(args[0].(string), args[1].(string), args[2].(string))
 	})
 	return _c
 }
 
-func (_c *APP_GetAllTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetAllTransactions_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *APP_ChangePassword_Call) Return(_a0 error) *APP_ChangePassword_Call {
+	_c.Call.Return(_a0)
 	return _c
 }
 
-func (_c *APP_GetAllTransactions_Call) RunAndReturn(run func() ([]*models.Transaction, error)) *APP_GetAllTransactions_Call {
+func (_c *APP_ChangePassword_Call) RunAndReturn(run func(string, string, string) error) *APP_ChangePassword_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetTransactionsByHashes provides a mock function with given fields: transactionHashes
-func (_m *APP) GetTransactionsByHashes(transactionHashes []string) ([]*models.Transaction, error) {
-	ret := _m.Called(transactionHashes)
+// CheckUserCredentials provides a mock function with given fields: username, password
+func (_m *APP) CheckUserCredentials(username string, password string) (*models.User, error) {
+	ret := _m.Called(username, password)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetTransactionsByHashes")
+		panic("no return value specified for CheckUserCredentials")
 	}
 
-	var r0 []*models.Transaction
+	var r0 *models.User
 	var r1 error
-	if rf, ok := ret.Get(0).(func([]string) ([]*models.Transaction, error)); ok {
-		return rf(transactionHashes)
+	if rf, ok := ret.Get(0).(func(string, string) (*models.User, error)); ok {
+		return rf(username, password)
 	}
-	if rf, ok := ret.Get(0).(func([]string) []*models.Transaction); ok {
-		r0 = rf(transactionHashes)
+	if rf, ok := ret.Get(0).(func(string, string) *models.User); ok {
+		r0 = rf(username, password)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
+			r0 = ret.Get(0).(*models.User)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func([]string) error); ok {
-		r1 = rf(transactionHashes)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(username, password)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -214,88 +367,1567 @@ func (_m *APP) GetTransactionsByHashes(transactionHashes []string) ([]*models.Tr
 	return r0, r1
 }
 
-// APP_GetTransactionsByHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsByHashes'
-type APP_GetTransactionsByHashes_Call struct {
+// APP_CheckUserCredentials_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CheckUserCredentials'
+type APP_CheckUserCredentials_Call struct {
 	*mock.Call
 }
 
-// GetTransactionsByHashes is a helper method to define mock.On call
-//   - transactionHashes []string
-func (_e *APP_Expecter) GetTransactionsByHashes(transactionHashes interface{}) *APP_GetTransactionsByHashes_Call {
-	return &APP_GetTransactionsByHashes_Call{Call: _e.mock.On("GetTransactionsByHashes", transactionHashes)}
+// CheckUserCredentials is a helper method to define mock.On call
+//   - username string
+//   - password string
+func (_e *APP_Expecter) CheckUserCredentials(username interface{}, password interface{}) *APP_CheckUserCredentials_Call {
+	return &APP_CheckUserCredentials_Call{Call: _e.mock.On("CheckUserCredentials", username, password)}
 }
 
-func (_c *APP_GetTransactionsByHashes_Call) Run(run func(transactionHashes []string)) *APP_GetTransactionsByHashes_Call {
+func (_c *APP_CheckUserCredentials_Call) Run(run func(username string, password string)) *APP_CheckUserCredentials_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].([]string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
 
-func (_c *APP_GetTransactionsByHashes_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetTransactionsByHashes_Call {
+func (_c *APP_CheckUserCredentials_Call) Return(_a0 *models.User, _a1 error) *APP_CheckUserCredentials_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *APP_GetTransactionsByHashes_Call) RunAndReturn(run func([]string) ([]*models.Transaction, error)) *APP_GetTransactionsByHashes_Call {
+func (_c *APP_CheckUserCredentials_Call) RunAndReturn(run func(string, string) (*models.User, error)) *APP_CheckUserCredentials_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GetUserTransactions provides a mock function with given fields: userID
-func (_m *APP) GetUserTransactions(userID string) ([]*models.Transaction, error) {
-	ret := _m.Called(userID)
+// CreateAPIKey provides a mock function with given fields: userID, callerScopes, name, scopes, expiresAt
+func (_m *APP) CreateAPIKey(userID string, callerScopes []models.Scope, name string, scopes []models.Scope, expiresAt *time.Time) (*models.APIKey, string, error) {
+	ret := _m.Called(userID, callerScopes, name, scopes, expiresAt)
 
 	if len(ret) == 0 {
-		panic("no return value specified for GetUserTransactions")
+		panic("no return value specified for CreateAPIKey")
 	}
 
-	var r0 []*models.Transaction
-	var r1 error
-	if rf, ok := ret.Get(0).(func(string) ([]*models.Transaction, error)); ok {
-		return rf(userID)
+	var r0 *models.APIKey
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string, []models.Scope, string, []models.Scope, *time.Time) (*models.APIKey, string, error)); ok {
+		return rf(userID, callerScopes, name, scopes, expiresAt)
 	}
-	if rf, ok := ret.Get(0).(func(string) []*models.Transaction); ok {
-		r0 = rf(userID)
+	if rf, ok := ret.Get(0).(func(string, []models.Scope, string, []models.Scope, *time.Time) *models.APIKey); ok {
+		r0 = rf(userID, callerScopes, name, scopes, expiresAt)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).([]*models.Transaction)
+			r0 = ret.Get(0).(*models.APIKey)
 		}
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(userID)
+	if rf, ok := ret.Get(1).(func(string, []models.Scope, string, []models.Scope, *time.Time) string); ok {
+		r1 = rf(userID, callerScopes, name, scopes, expiresAt)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(string)
 	}
 
-	return r0, r1
+	if rf, ok := ret.Get(2).(func(string, []models.Scope, string, []models.Scope, *time.Time) error); ok {
+		r2 = rf(userID, callerScopes, name, scopes, expiresAt)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// APP_GetUserTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserTransactions'
-type APP_GetUserTransactions_Call struct {
+// APP_CreateAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'CreateAPIKey'
+type APP_CreateAPIKey_Call struct {
 	*mock.Call
 }
 
-// GetUserTransactions is a helper method to define mock.On call
+// CreateAPIKey is a helper method to define mock.On call
 //   - userID string
-func (_e *APP_Expecter) GetUserTransactions(userID interface{}) *APP_GetUserTransactions_Call {
-	return &APP_GetUserTransactions_Call{Call: _e.mock.On("GetUserTransactions", userID)}
+//   - callerScopes []models.Scope
+//   - name string
+//   - scopes []models.Scope
+//   - expiresAt *time.Time
+func (_e *APP_Expecter) CreateAPIKey(userID interface{}, callerScopes interface{}, name interface{}, scopes interface{}, expiresAt interface{}) *APP_CreateAPIKey_Call {
+	return &APP_CreateAPIKey_Call{Call: _e.mock.On("CreateAPIKey", userID, callerScopes, name, scopes, expiresAt)}
 }
 
-func (_c *APP_GetUserTransactions_Call) Run(run func(userID string)) *APP_GetUserTransactions_Call {
+func (_c *APP_CreateAPIKey_Call) Run(run func(userID string, callerScopes []models.Scope, name string, scopes []models.Scope, expiresAt *time.Time)) *APP_CreateAPIKey_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].([]models.Scope), args[2].(string), args[3].([]models.Scope), args[4].(*time.Time))
 	})
 	return _c
 }
 
-func (_c *APP_GetUserTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetUserTransactions_Call {
-	_c.Call.Return(_a0, _a1)
+func (_c *APP_CreateAPIKey_Call) Return(_a0 *models.APIKey, _a1 string, _a2 error) *APP_CreateAPIKey_Call {
+	_c.Call.Return(_a0, _a1, _a2)
 	return _c
 }
 
-func (_c *APP_GetUserTransactions_Call) RunAndReturn(run func(string) ([]*models.Transaction, error)) *APP_GetUserTransactions_Call {
+func (_c *APP_CreateAPIKey_Call) RunAndReturn(run func(string, []models.Scope, string, []models.Scope, *time.Time) (*models.APIKey, string, error)) *APP_CreateAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteAddressLabel provides a mock function with given fields: userID, address, global
+func (_m *APP) DeleteAddressLabel(userID string, address string, global bool) error {
+	ret := _m.Called(userID, address, global)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteAddressLabel")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, bool) error); ok {
+		r0 = rf(userID, address, global)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_DeleteAddressLabel_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteAddressLabel'
+type APP_DeleteAddressLabel_Call struct {
+	*mock.Call
+}
+
+// DeleteAddressLabel is a helper method to define mock.On call
+//   - userID string
+//   - address string
+//   - global bool
+func (_e *APP_Expecter) DeleteAddressLabel(userID interface{}, address interface{}, global interface{}) *APP_DeleteAddressLabel_Call {
+	return &APP_DeleteAddressLabel_Call{Call: _e.mock.On("DeleteAddressLabel", userID, address, global)}
+}
+
+func (_c *APP_DeleteAddressLabel_Call) Run(run func(userID string, address string, global bool)) *APP_DeleteAddressLabel_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *APP_DeleteAddressLabel_Call) Return(_a0 error) *APP_DeleteAddressLabel_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_DeleteAddressLabel_Call) RunAndReturn(run func(string, string, bool) error) *APP_DeleteAddressLabel_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// DeleteUser provides a mock function with given fields: userID, id
+func (_m *APP) DeleteUser(userID string, id string) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for DeleteUser")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_DeleteUser_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'DeleteUser'
+type APP_DeleteUser_Call struct {
+	*mock.Call
+}
+
+// DeleteUser is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) DeleteUser(userID interface{}, id interface{}) *APP_DeleteUser_Call {
+	return &APP_DeleteUser_Call{Call: _e.mock.On("DeleteUser", userID, id)}
+}
+
+func (_c *APP_DeleteUser_Call) Run(run func(userID string, id string)) *APP_DeleteUser_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_DeleteUser_Call) Return(_a0 error) *APP_DeleteUser_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_DeleteUser_Call) RunAndReturn(run func(string, string) error) *APP_DeleteUser_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAddressActivity provides a mock function with given fields: address, cursor, limit
+func (_m *APP) GetAddressActivity(address string, cursor string, limit int) (*app.AddressActivity, error) {
+	ret := _m.Called(address, cursor, limit)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddressActivity")
+	}
+
+	var r0 *app.AddressActivity
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, int) (*app.AddressActivity, error)); ok {
+		return rf(address, cursor, limit)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, int) *app.AddressActivity); ok {
+		r0 = rf(address, cursor, limit)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.AddressActivity)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, int) error); ok {
+		r1 = rf(address, cursor, limit)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetAddressActivity_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAddressActivity'
+type APP_GetAddressActivity_Call struct {
+	*mock.Call
+}
+
+// GetAddressActivity is a helper method to define mock.On call
+//   - address string
+//   - cursor string
+//   - limit int
+func (_e *APP_Expecter) GetAddressActivity(address interface{}, cursor interface{}, limit interface{}) *APP_GetAddressActivity_Call {
+	return &APP_GetAddressActivity_Call{Call: _e.mock.On("GetAddressActivity", address, cursor, limit)}
+}
+
+func (_c *APP_GetAddressActivity_Call) Run(run func(address string, cursor string, limit int)) *APP_GetAddressActivity_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(int))
+	})
+	return _c
+}
+
+func (_c *APP_GetAddressActivity_Call) Return(_a0 *app.AddressActivity, _a1 error) *APP_GetAddressActivity_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetAddressActivity_Call) RunAndReturn(run func(string, string, int) (*app.AddressActivity, error)) *APP_GetAddressActivity_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAddressLabels provides a mock function with given fields: userID
+func (_m *APP) GetAddressLabels(userID string) ([]*models.AddressLabel, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAddressLabels")
+	}
+
+	var r0 []*models.AddressLabel
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.AddressLabel, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.AddressLabel); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.AddressLabel)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetAddressLabels_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAddressLabels'
+type APP_GetAddressLabels_Call struct {
+	*mock.Call
+}
+
+// GetAddressLabels is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) GetAddressLabels(userID interface{}) *APP_GetAddressLabels_Call {
+	return &APP_GetAddressLabels_Call{Call: _e.mock.On("GetAddressLabels", userID)}
+}
+
+func (_c *APP_GetAddressLabels_Call) Run(run func(userID string)) *APP_GetAddressLabels_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetAddressLabels_Call) Return(_a0 []*models.AddressLabel, _a1 error) *APP_GetAddressLabels_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetAddressLabels_Call) RunAndReturn(run func(string) ([]*models.AddressLabel, error)) *APP_GetAddressLabels_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetAllTransactions provides a mock function with given fields:
+func (_m *APP) GetAllTransactions() ([]*models.Transaction, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetAllTransactions")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func() ([]*models.Transaction, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() []*models.Transaction); ok {
+		r0 = rf()
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetAllTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetAllTransactions'
+type APP_GetAllTransactions_Call struct {
+	*mock.Call
+}
+
+// GetAllTransactions is a helper method to define mock.On call
+func (_e *APP_Expecter) GetAllTransactions() *APP_GetAllTransactions_Call {
+	return &APP_GetAllTransactions_Call{Call: _e.mock.On("GetAllTransactions")}
+}
+
+func (_c *APP_GetAllTransactions_Call) Run(run func()) *APP_GetAllTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *APP_GetAllTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetAllTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetAllTransactions_Call) RunAndReturn(run func() ([]*models.Transaction, error)) *APP_GetAllTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBackfillJob provides a mock function with given fields: userID, id
+func (_m *APP) GetBackfillJob(userID string, id string) (*models.BackfillJob, error) {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBackfillJob")
+	}
+
+	var r0 *models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*models.BackfillJob, error)); ok {
+		return rf(userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *models.BackfillJob); ok {
+		r0 = rf(userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetBackfillJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBackfillJob'
+type APP_GetBackfillJob_Call struct {
+	*mock.Call
+}
+
+// GetBackfillJob is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) GetBackfillJob(userID interface{}, id interface{}) *APP_GetBackfillJob_Call {
+	return &APP_GetBackfillJob_Call{Call: _e.mock.On("GetBackfillJob", userID, id)}
+}
+
+func (_c *APP_GetBackfillJob_Call) Run(run func(userID string, id string)) *APP_GetBackfillJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetBackfillJob_Call) Return(_a0 *models.BackfillJob, _a1 error) *APP_GetBackfillJob_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetBackfillJob_Call) RunAndReturn(run func(string, string) (*models.BackfillJob, error)) *APP_GetBackfillJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetBackfillJobs provides a mock function with given fields: userID
+func (_m *APP) GetBackfillJobs(userID string) ([]*models.BackfillJob, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetBackfillJobs")
+	}
+
+	var r0 []*models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.BackfillJob, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.BackfillJob); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetBackfillJobs_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetBackfillJobs'
+type APP_GetBackfillJobs_Call struct {
+	*mock.Call
+}
+
+// GetBackfillJobs is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) GetBackfillJobs(userID interface{}) *APP_GetBackfillJobs_Call {
+	return &APP_GetBackfillJobs_Call{Call: _e.mock.On("GetBackfillJobs", userID)}
+}
+
+func (_c *APP_GetBackfillJobs_Call) Run(run func(userID string)) *APP_GetBackfillJobs_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetBackfillJobs_Call) Return(_a0 []*models.BackfillJob, _a1 error) *APP_GetBackfillJobs_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetBackfillJobs_Call) RunAndReturn(run func(string) ([]*models.BackfillJob, error)) *APP_GetBackfillJobs_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetLookupJob provides a mock function with given fields: userID, id
+func (_m *APP) GetLookupJob(userID string, id string) (*app.LookupJob, error) {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetLookupJob")
+	}
+
+	var r0 *app.LookupJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*app.LookupJob, error)); ok {
+		return rf(userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *app.LookupJob); ok {
+		r0 = rf(userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.LookupJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetLookupJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetLookupJob'
+type APP_GetLookupJob_Call struct {
+	*mock.Call
+}
+
+// GetLookupJob is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) GetLookupJob(userID interface{}, id interface{}) *APP_GetLookupJob_Call {
+	return &APP_GetLookupJob_Call{Call: _e.mock.On("GetLookupJob", userID, id)}
+}
+
+func (_c *APP_GetLookupJob_Call) Run(run func(userID string, id string)) *APP_GetLookupJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetLookupJob_Call) Return(_a0 *app.LookupJob, _a1 error) *APP_GetLookupJob_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetLookupJob_Call) RunAndReturn(run func(string, string) (*app.LookupJob, error)) *APP_GetLookupJob_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetProfile provides a mock function with given fields: userID
+func (_m *APP) GetProfile(userID string) (*models.User, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetProfile")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (*models.User, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetProfile_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetProfile'
+type APP_GetProfile_Call struct {
+	*mock.Call
+}
+
+// GetProfile is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) GetProfile(userID interface{}) *APP_GetProfile_Call {
+	return &APP_GetProfile_Call{Call: _e.mock.On("GetProfile", userID)}
+}
+
+func (_c *APP_GetProfile_Call) Run(run func(userID string)) *APP_GetProfile_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetProfile_Call) Return(_a0 *models.User, _a1 error) *APP_GetProfile_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetProfile_Call) RunAndReturn(run func(string) (*models.User, error)) *APP_GetProfile_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetTransactionsByHashes provides a mock function with given fields: userID, transactionHashes
+func (_m *APP) GetTransactionsByHashes(userID string, transactionHashes []string) ([]*models.Transaction, error) {
+	ret := _m.Called(userID, transactionHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetTransactionsByHashes")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) ([]*models.Transaction, error)); ok {
+		return rf(userID, transactionHashes)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) []*models.Transaction); ok {
+		r0 = rf(userID, transactionHashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(userID, transactionHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetTransactionsByHashes_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetTransactionsByHashes'
+type APP_GetTransactionsByHashes_Call struct {
+	*mock.Call
+}
+
+// GetTransactionsByHashes is a helper method to define mock.On call
+//   - userID string
+//   - transactionHashes []string
+func (_e *APP_Expecter) GetTransactionsByHashes(userID interface{}, transactionHashes interface{}) *APP_GetTransactionsByHashes_Call {
+	return &APP_GetTransactionsByHashes_Call{Call: _e.mock.On("GetTransactionsByHashes", userID, transactionHashes)}
+}
+
+func (_c *APP_GetTransactionsByHashes_Call) Run(run func(userID string, transactionHashes []string)) *APP_GetTransactionsByHashes_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *APP_GetTransactionsByHashes_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetTransactionsByHashes_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetTransactionsByHashes_Call) RunAndReturn(run func(string, []string) ([]*models.Transaction, error)) *APP_GetTransactionsByHashes_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// GetUserTransactions provides a mock function with given fields: userID
+func (_m *APP) GetUserTransactions(userID string) ([]*models.Transaction, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for GetUserTransactions")
+	}
+
+	var r0 []*models.Transaction
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.Transaction, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.Transaction); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_GetUserTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'GetUserTransactions'
+type APP_GetUserTransactions_Call struct {
+	*mock.Call
+}
+
+// GetUserTransactions is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) GetUserTransactions(userID interface{}) *APP_GetUserTransactions_Call {
+	return &APP_GetUserTransactions_Call{Call: _e.mock.On("GetUserTransactions", userID)}
+}
+
+func (_c *APP_GetUserTransactions_Call) Run(run func(userID string)) *APP_GetUserTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_GetUserTransactions_Call) Return(_a0 []*models.Transaction, _a1 error) *APP_GetUserTransactions_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_GetUserTransactions_Call) RunAndReturn(run func(string) ([]*models.Transaction, error)) *APP_GetUserTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// IssueRefreshToken provides a mock function with given fields: userID
+func (_m *APP) IssueRefreshToken(userID string) (string, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for IssueRefreshToken")
+	}
+
+	var r0 string
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) string); ok {
+		r0 = rf(userID)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_IssueRefreshToken_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'IssueRefreshToken'
+type APP_IssueRefreshToken_Call struct {
+	*mock.Call
+}
+
+// IssueRefreshToken is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) IssueRefreshToken(userID interface{}) *APP_IssueRefreshToken_Call {
+	return &APP_IssueRefreshToken_Call{Call: _e.mock.On("IssueRefreshToken", userID)}
+}
+
+func (_c *APP_IssueRefreshToken_Call) Run(run func(userID string)) *APP_IssueRefreshToken_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_IssueRefreshToken_Call) Return(_a0 string, _a1 error) *APP_IssueRefreshToken_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_IssueRefreshToken_Call) RunAndReturn(run func(string) (string, error)) *APP_IssueRefreshToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// LabelTransactions provides a mock function with given fields: userID, transactions
+func (_m *APP) LabelTransactions(userID string, transactions []*models.Transaction) []*models.Transaction {
+	ret := _m.Called(userID, transactions)
+
+	if len(ret) == 0 {
+		panic("no return value specified for LabelTransactions")
+	}
+
+	var r0 []*models.Transaction
+	if rf, ok := ret.Get(0).(func(string, []*models.Transaction) []*models.Transaction); ok {
+		r0 = rf(userID, transactions)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.Transaction)
+		}
+	}
+
+	return r0
+}
+
+// APP_LabelTransactions_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'LabelTransactions'
+type APP_LabelTransactions_Call struct {
+	*mock.Call
+}
+
+// LabelTransactions is a helper method to define mock.On call
+//   - userID string
+//   - transactions []*models.Transaction
+func (_e *APP_Expecter) LabelTransactions(userID interface{}, transactions interface{}) *APP_LabelTransactions_Call {
+	return &APP_LabelTransactions_Call{Call: _e.mock.On("LabelTransactions", userID, transactions)}
+}
+
+func (_c *APP_LabelTransactions_Call) Run(run func(userID string, transactions []*models.Transaction)) *APP_LabelTransactions_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]*models.Transaction))
+	})
+	return _c
+}
+
+func (_c *APP_LabelTransactions_Call) Return(_a0 []*models.Transaction) *APP_LabelTransactions_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_LabelTransactions_Call) RunAndReturn(run func(string, []*models.Transaction) []*models.Transaction) *APP_LabelTransactions_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListAPIKeys provides a mock function with given fields: userID
+func (_m *APP) ListAPIKeys(userID string) ([]*models.APIKey, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListAPIKeys")
+	}
+
+	var r0 []*models.APIKey
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.APIKey, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.APIKey); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.APIKey)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_ListAPIKeys_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListAPIKeys'
+type APP_ListAPIKeys_Call struct {
+	*mock.Call
+}
+
+// ListAPIKeys is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) ListAPIKeys(userID interface{}) *APP_ListAPIKeys_Call {
+	return &APP_ListAPIKeys_Call{Call: _e.mock.On("ListAPIKeys", userID)}
+}
+
+func (_c *APP_ListAPIKeys_Call) Run(run func(userID string)) *APP_ListAPIKeys_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_ListAPIKeys_Call) Return(_a0 []*models.APIKey, _a1 error) *APP_ListAPIKeys_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_ListAPIKeys_Call) RunAndReturn(run func(string) ([]*models.APIKey, error)) *APP_ListAPIKeys_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ListUsers provides a mock function with given fields: userID
+func (_m *APP) ListUsers(userID string) ([]*models.User, error) {
+	ret := _m.Called(userID)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ListUsers")
+	}
+
+	var r0 []*models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string) ([]*models.User, error)); ok {
+		return rf(userID)
+	}
+	if rf, ok := ret.Get(0).(func(string) []*models.User); ok {
+		r0 = rf(userID)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) error); ok {
+		r1 = rf(userID)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_ListUsers_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ListUsers'
+type APP_ListUsers_Call struct {
+	*mock.Call
+}
+
+// ListUsers is a helper method to define mock.On call
+//   - userID string
+func (_e *APP_Expecter) ListUsers(userID interface{}) *APP_ListUsers_Call {
+	return &APP_ListUsers_Call{Call: _e.mock.On("ListUsers", userID)}
+}
+
+func (_c *APP_ListUsers_Call) Run(run func(userID string)) *APP_ListUsers_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_ListUsers_Call) Return(_a0 []*models.User, _a1 error) *APP_ListUsers_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_ListUsers_Call) RunAndReturn(run func(string) ([]*models.User, error)) *APP_ListUsers_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Logout provides a mock function with given fields: userID, tokenID, tokenExpiresAt, refreshToken
+func (_m *APP) Logout(userID string, tokenID string, tokenExpiresAt time.Time, refreshToken string) error {
+	ret := _m.Called(userID, tokenID, tokenExpiresAt, refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Logout")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, time.Time, string) error); ok {
+		r0 = rf(userID, tokenID, tokenExpiresAt, refreshToken)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_Logout_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Logout'
+type APP_Logout_Call struct {
+	*mock.Call
+}
+
+// Logout is a helper method to define mock.On call
+//   - userID string
+//   - tokenID string
+//   - tokenExpiresAt time.Time
+//   - refreshToken string
+func (_e *APP_Expecter) Logout(userID interface{}, tokenID interface{}, tokenExpiresAt interface{}, refreshToken interface{}) *APP_Logout_Call {
+	return &APP_Logout_Call{Call: _e.mock.On("Logout", userID, tokenID, tokenExpiresAt, refreshToken)}
+}
+
+func (_c *APP_Logout_Call) Run(run func(userID string, tokenID string, tokenExpiresAt time.Time, refreshToken string)) *APP_Logout_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(time.Time), args[3].(string))
+	})
+	return _c
+}
+
+func (_c *APP_Logout_Call) Return(_a0 error) *APP_Logout_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_Logout_Call) RunAndReturn(run func(string, string, time.Time, string) error) *APP_Logout_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Prune provides a mock function with given fields: userID, dryRun
+func (_m *APP) Prune(userID string, dryRun bool) (*app.PruneReport, error) {
+	ret := _m.Called(userID, dryRun)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Prune")
+	}
+
+	var r0 *app.PruneReport
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, bool) (*app.PruneReport, error)); ok {
+		return rf(userID, dryRun)
+	}
+	if rf, ok := ret.Get(0).(func(string, bool) *app.PruneReport); ok {
+		r0 = rf(userID, dryRun)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.PruneReport)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, bool) error); ok {
+		r1 = rf(userID, dryRun)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_Prune_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Prune'
+type APP_Prune_Call struct {
+	*mock.Call
+}
+
+// Prune is a helper method to define mock.On call
+//   - userID string
+//   - dryRun bool
+func (_e *APP_Expecter) Prune(userID interface{}, dryRun interface{}) *APP_Prune_Call {
+	return &APP_Prune_Call{Call: _e.mock.On("Prune", userID, dryRun)}
+}
+
+func (_c *APP_Prune_Call) Run(run func(userID string, dryRun bool)) *APP_Prune_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(bool))
+	})
+	return _c
+}
+
+func (_c *APP_Prune_Call) Return(_a0 *app.PruneReport, _a1 error) *APP_Prune_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_Prune_Call) RunAndReturn(run func(string, bool) (*app.PruneReport, error)) *APP_Prune_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RefreshSession provides a mock function with given fields: refreshToken
+func (_m *APP) RefreshSession(refreshToken string) (*models.User, string, error) {
+	ret := _m.Called(refreshToken)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RefreshSession")
+	}
+
+	var r0 *models.User
+	var r1 string
+	var r2 error
+	if rf, ok := ret.Get(0).(func(string) (*models.User, string, error)); ok {
+		return rf(refreshToken)
+	}
+	if rf, ok := ret.Get(0).(func(string) *models.User); ok {
+		r0 = rf(refreshToken)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string) string); ok {
+		r1 = rf(refreshToken)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	if rf, ok := ret.Get(2).(func(string) error); ok {
+		r2 = rf(refreshToken)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// APP_RefreshSession_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RefreshSession'
+type APP_RefreshSession_Call struct {
+	*mock.Call
+}
+
+// RefreshSession is a helper method to define mock.On call
+//   - refreshToken string
+func (_e *APP_Expecter) RefreshSession(refreshToken interface{}) *APP_RefreshSession_Call {
+	return &APP_RefreshSession_Call{Call: _e.mock.On("RefreshSession", refreshToken)}
+}
+
+func (_c *APP_RefreshSession_Call) Run(run func(refreshToken string)) *APP_RefreshSession_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string))
+	})
+	return _c
+}
+
+func (_c *APP_RefreshSession_Call) Return(_a0 *models.User, _a1 string, _a2 error) *APP_RefreshSession_Call {
+	_c.Call.Return(_a0, _a1, _a2)
+	return _c
+}
+
+func (_c *APP_RefreshSession_Call) RunAndReturn(run func(string) (*models.User, string, error)) *APP_RefreshSession_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// Register provides a mock function with given fields: username, password
+func (_m *APP) Register(username string, password string) (*models.User, error) {
+	ret := _m.Called(username, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for Register")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*models.User, error)); ok {
+		return rf(username, password)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *models.User); ok {
+		r0 = rf(username, password)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(username, password)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_Register_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'Register'
+type APP_Register_Call struct {
+	*mock.Call
+}
+
+// Register is a helper method to define mock.On call
+//   - username string
+//   - password string
+func (_e *APP_Expecter) Register(username interface{}, password interface{}) *APP_Register_Call {
+	return &APP_Register_Call{Call: _e.mock.On("Register", username, password)}
+}
+
+func (_c *APP_Register_Call) Run(run func(username string, password string)) *APP_Register_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_Register_Call) Return(_a0 *models.User, _a1 error) *APP_Register_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_Register_Call) RunAndReturn(run func(string, string) (*models.User, error)) *APP_Register_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResetUserPassword provides a mock function with given fields: userID, id, password
+func (_m *APP) ResetUserPassword(userID string, id string, password string) error {
+	ret := _m.Called(userID, id, password)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResetUserPassword")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string, string) error); ok {
+		r0 = rf(userID, id, password)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_ResetUserPassword_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResetUserPassword'
+type APP_ResetUserPassword_Call struct {
+	*mock.Call
+}
+
+// ResetUserPassword is a helper method to define mock.On call
+//   - userID string
+//   - id string
+//   - password string
+func (_e *APP_Expecter) ResetUserPassword(userID interface{}, id interface{}, password interface{}) *APP_ResetUserPassword_Call {
+	return &APP_ResetUserPassword_Call{Call: _e.mock.On("ResetUserPassword", userID, id, password)}
+}
+
+func (_c *APP_ResetUserPassword_Call) Run(run func(userID string, id string, password string)) *APP_ResetUserPassword_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(string))
+	})
+	return _c
+}
+
+func (_c *APP_ResetUserPassword_Call) Return(_a0 error) *APP_ResetUserPassword_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_ResetUserPassword_Call) RunAndReturn(run func(string, string, string) error) *APP_ResetUserPassword_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// ResumeBackfill provides a mock function with given fields: userID, id
+func (_m *APP) ResumeBackfill(userID string, id string) (*models.BackfillJob, error) {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for ResumeBackfill")
+	}
+
+	var r0 *models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string) (*models.BackfillJob, error)); ok {
+		return rf(userID, id)
+	}
+	if rf, ok := ret.Get(0).(func(string, string) *models.BackfillJob); ok {
+		r0 = rf(userID, id)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(userID, id)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_ResumeBackfill_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'ResumeBackfill'
+type APP_ResumeBackfill_Call struct {
+	*mock.Call
+}
+
+// ResumeBackfill is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) ResumeBackfill(userID interface{}, id interface{}) *APP_ResumeBackfill_Call {
+	return &APP_ResumeBackfill_Call{Call: _e.mock.On("ResumeBackfill", userID, id)}
+}
+
+func (_c *APP_ResumeBackfill_Call) Run(run func(userID string, id string)) *APP_ResumeBackfill_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_ResumeBackfill_Call) Return(_a0 *models.BackfillJob, _a1 error) *APP_ResumeBackfill_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_ResumeBackfill_Call) RunAndReturn(run func(string, string) (*models.BackfillJob, error)) *APP_ResumeBackfill_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// RevokeAPIKey provides a mock function with given fields: userID, id
+func (_m *APP) RevokeAPIKey(userID string, id string) error {
+	ret := _m.Called(userID, id)
+
+	if len(ret) == 0 {
+		panic("no return value specified for RevokeAPIKey")
+	}
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(string, string) error); ok {
+		r0 = rf(userID, id)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// APP_RevokeAPIKey_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'RevokeAPIKey'
+type APP_RevokeAPIKey_Call struct {
+	*mock.Call
+}
+
+// RevokeAPIKey is a helper method to define mock.On call
+//   - userID string
+//   - id string
+func (_e *APP_Expecter) RevokeAPIKey(userID interface{}, id interface{}) *APP_RevokeAPIKey_Call {
+	return &APP_RevokeAPIKey_Call{Call: _e.mock.On("RevokeAPIKey", userID, id)}
+}
+
+func (_c *APP_RevokeAPIKey_Call) Run(run func(userID string, id string)) *APP_RevokeAPIKey_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string))
+	})
+	return _c
+}
+
+func (_c *APP_RevokeAPIKey_Call) Return(_a0 error) *APP_RevokeAPIKey_Call {
+	_c.Call.Return(_a0)
+	return _c
+}
+
+func (_c *APP_RevokeAPIKey_Call) RunAndReturn(run func(string, string) error) *APP_RevokeAPIKey_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserDisabled provides a mock function with given fields: userID, id, disabled
+func (_m *APP) SetUserDisabled(userID string, id string, disabled bool) (*models.User, error) {
+	ret := _m.Called(userID, id, disabled)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserDisabled")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, bool) (*models.User, error)); ok {
+		return rf(userID, id, disabled)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, bool) *models.User); ok {
+		r0 = rf(userID, id, disabled)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, bool) error); ok {
+		r1 = rf(userID, id, disabled)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_SetUserDisabled_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserDisabled'
+type APP_SetUserDisabled_Call struct {
+	*mock.Call
+}
+
+// SetUserDisabled is a helper method to define mock.On call
+//   - userID string
+//   - id string
+//   - disabled bool
+func (_e *APP_Expecter) SetUserDisabled(userID interface{}, id interface{}, disabled interface{}) *APP_SetUserDisabled_Call {
+	return &APP_SetUserDisabled_Call{Call: _e.mock.On("SetUserDisabled", userID, id, disabled)}
+}
+
+func (_c *APP_SetUserDisabled_Call) Run(run func(userID string, id string, disabled bool)) *APP_SetUserDisabled_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(bool))
+	})
+	return _c
+}
+
+func (_c *APP_SetUserDisabled_Call) Return(_a0 *models.User, _a1 error) *APP_SetUserDisabled_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_SetUserDisabled_Call) RunAndReturn(run func(string, string, bool) (*models.User, error)) *APP_SetUserDisabled_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// SetUserRole provides a mock function with given fields: userID, id, role
+func (_m *APP) SetUserRole(userID string, id string, role models.Role) (*models.User, error) {
+	ret := _m.Called(userID, id, role)
+
+	if len(ret) == 0 {
+		panic("no return value specified for SetUserRole")
+	}
+
+	var r0 *models.User
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, string, models.Role) (*models.User, error)); ok {
+		return rf(userID, id, role)
+	}
+	if rf, ok := ret.Get(0).(func(string, string, models.Role) *models.User); ok {
+		r0 = rf(userID, id, role)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.User)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, string, models.Role) error); ok {
+		r1 = rf(userID, id, role)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_SetUserRole_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'SetUserRole'
+type APP_SetUserRole_Call struct {
+	*mock.Call
+}
+
+// SetUserRole is a helper method to define mock.On call
+//   - userID string
+//   - id string
+//   - role models.Role
+func (_e *APP_Expecter) SetUserRole(userID interface{}, id interface{}, role interface{}) *APP_SetUserRole_Call {
+	return &APP_SetUserRole_Call{Call: _e.mock.On("SetUserRole", userID, id, role)}
+}
+
+func (_c *APP_SetUserRole_Call) Run(run func(userID string, id string, role models.Role)) *APP_SetUserRole_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(string), args[2].(models.Role))
+	})
+	return _c
+}
+
+func (_c *APP_SetUserRole_Call) Return(_a0 *models.User, _a1 error) *APP_SetUserRole_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_SetUserRole_Call) RunAndReturn(run func(string, string, models.Role) (*models.User, error)) *APP_SetUserRole_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartBackfill provides a mock function with given fields: userID, fromBlock, toBlock
+func (_m *APP) StartBackfill(userID string, fromBlock int64, toBlock int64) (*models.BackfillJob, error) {
+	ret := _m.Called(userID, fromBlock, toBlock)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartBackfill")
+	}
+
+	var r0 *models.BackfillJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, int64, int64) (*models.BackfillJob, error)); ok {
+		return rf(userID, fromBlock, toBlock)
+	}
+	if rf, ok := ret.Get(0).(func(string, int64, int64) *models.BackfillJob); ok {
+		r0 = rf(userID, fromBlock, toBlock)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*models.BackfillJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, int64, int64) error); ok {
+		r1 = rf(userID, fromBlock, toBlock)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_StartBackfill_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartBackfill'
+type APP_StartBackfill_Call struct {
+	*mock.Call
+}
+
+// StartBackfill is a helper method to define mock.On call
+//   - userID string
+//   - fromBlock int64
+//   - toBlock int64
+func (_e *APP_Expecter) StartBackfill(userID interface{}, fromBlock interface{}, toBlock interface{}) *APP_StartBackfill_Call {
+	return &APP_StartBackfill_Call{Call: _e.mock.On("StartBackfill", userID, fromBlock, toBlock)}
+}
+
+func (_c *APP_StartBackfill_Call) Run(run func(userID string, fromBlock int64, toBlock int64)) *APP_StartBackfill_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].(int64), args[2].(int64))
+	})
+	return _c
+}
+
+func (_c *APP_StartBackfill_Call) Return(_a0 *models.BackfillJob, _a1 error) *APP_StartBackfill_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_StartBackfill_Call) RunAndReturn(run func(string, int64, int64) (*models.BackfillJob, error)) *APP_StartBackfill_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// StartLookupJob provides a mock function with given fields: userID, transactionHashes
+func (_m *APP) StartLookupJob(userID string, transactionHashes []string) (*app.LookupJob, error) {
+	ret := _m.Called(userID, transactionHashes)
+
+	if len(ret) == 0 {
+		panic("no return value specified for StartLookupJob")
+	}
+
+	var r0 *app.LookupJob
+	var r1 error
+	if rf, ok := ret.Get(0).(func(string, []string) (*app.LookupJob, error)); ok {
+		return rf(userID, transactionHashes)
+	}
+	if rf, ok := ret.Get(0).(func(string, []string) *app.LookupJob); ok {
+		r0 = rf(userID, transactionHashes)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*app.LookupJob)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(string, []string) error); ok {
+		r1 = rf(userID, transactionHashes)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// APP_StartLookupJob_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'StartLookupJob'
+type APP_StartLookupJob_Call struct {
+	*mock.Call
+}
+
+// StartLookupJob is a helper method to define mock.On call
+//   - userID string
+//   - transactionHashes []string
+func (_e *APP_Expecter) StartLookupJob(userID interface{}, transactionHashes interface{}) *APP_StartLookupJob_Call {
+	return &APP_StartLookupJob_Call{Call: _e.mock.On("StartLookupJob", userID, transactionHashes)}
+}
+
+func (_c *APP_StartLookupJob_Call) Run(run func(userID string, transactionHashes []string)) *APP_StartLookupJob_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(string), args[1].([]string))
+	})
+	return _c
+}
+
+func (_c *APP_StartLookupJob_Call) Return(_a0 *app.LookupJob, _a1 error) *APP_StartLookupJob_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *APP_StartLookupJob_Call) RunAndReturn(run func(string, []string) (*app.LookupJob, error)) *APP_StartLookupJob_Call {
 	_c.Call.Return(run)
 	return _c
 }
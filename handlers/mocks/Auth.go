@@ -3,6 +3,8 @@
 package mocks
 
 import (
+	auth "eth-fetcher/auth"
+
 	http "net/http"
 
 	mock "github.com/stretchr/testify/mock"
@@ -22,22 +24,22 @@ func (_m *Auth) EXPECT() *Auth_Expecter {
 }
 
 // AuthenticateRequest provides a mock function with given fields: r
-func (_m *Auth) AuthenticateRequest(r *http.Request) (string, error) {
+func (_m *Auth) AuthenticateRequest(r *http.Request) (auth.Identity, error) {
 	ret := _m.Called(r)
 
 	if len(ret) == 0 {
 		panic("no return value specified for AuthenticateRequest")
 	}
 
-	var r0 string
+	var r0 auth.Identity
 	var r1 error
-	if rf, ok := ret.Get(0).(func(*http.Request) (string, error)); ok {
+	if rf, ok := ret.Get(0).(func(*http.Request) (auth.Identity, error)); ok {
 		return rf(r)
 	}
-	if rf, ok := ret.Get(0).(func(*http.Request) string); ok {
+	if rf, ok := ret.Get(0).(func(*http.Request) auth.Identity); ok {
 		r0 = rf(r)
 	} else {
-		r0 = ret.Get(0).(string)
+		r0 = ret.Get(0).(auth.Identity)
 	}
 
 	if rf, ok := ret.Get(1).(func(*http.Request) error); ok {
@@ -67,19 +69,19 @@ func (_c *Auth_AuthenticateRequest_Call) Run(run func(r *http.Request)) *Auth_Au
 	return _c
 }
 
-func (_c *Auth_AuthenticateRequest_Call) Return(_a0 string, _a1 error) *Auth_AuthenticateRequest_Call {
+func (_c *Auth_AuthenticateRequest_Call) Return(_a0 auth.Identity, _a1 error) *Auth_AuthenticateRequest_Call {
 	_c.Call.Return(_a0, _a1)
 	return _c
 }
 
-func (_c *Auth_AuthenticateRequest_Call) RunAndReturn(run func(*http.Request) (string, error)) *Auth_AuthenticateRequest_Call {
+func (_c *Auth_AuthenticateRequest_Call) RunAndReturn(run func(*http.Request) (auth.Identity, error)) *Auth_AuthenticateRequest_Call {
 	_c.Call.Return(run)
 	return _c
 }
 
-// GenerateToken provides a mock function with given fields: subject
-func (_m *Auth) GenerateToken(subject string) (string, error) {
-	ret := _m.Called(subject)
+// GenerateToken provides a mock function with given fields: subject, role
+func (_m *Auth) GenerateToken(subject string, role string) (string, error) {
+	ret := _m.Called(subject, role)
 
 	if len(ret) == 0 {
 		panic("no return value specified for GenerateToken")
@@ -87,17 +89,17 @@ func (_m *Auth) GenerateToken(subject string) (string, error) {
 
 	var r0 string
 	var r1 error
-	if rf, ok := ret.Get(0).(func(string) (string, error)); ok {
-		return rf(subject)
+	if rf, ok := ret.Get(0).(func(string, string) (string, error)); ok {
+		return rf(subject, role)
 	}
-	if rf, ok := ret.Get(0).(func(string) string); ok {
-		r0 = rf(subject)
+	if rf, ok := ret.Get(0).(func(string, string) string); ok {
+		r0 = rf(subject, role)
 	} else {
 		r0 = ret.Get(0).(string)
 	}
 
-	if rf, ok := ret.Get(1).(func(string) error); ok {
-		r1 = rf(subject)
+	if rf, ok := ret.Get(1).(func(string, string) error); ok {
+		r1 = rf(subject, role)
 	} else {
 		r1 = ret.Error(1)
 	}
@@ -112,13 +114,14 @@ type Auth_GenerateToken_Call struct {
 
 // GenerateToken is a helper method to define mock.On call
 //   - subject string
-func (_e *Auth_Expecter) GenerateToken(subject interface{}) *Auth_GenerateToken_Call {
-	return &Auth_GenerateToken_Call{Call: _e.mock.On("GenerateToken", subject)}
+//   - role string
+func (_e *Auth_Expecter) GenerateToken(subject interface{}, role interface{}) *Auth_GenerateToken_Call {
+	return &Auth_GenerateToken_Call{Call: _e.mock.On("GenerateToken", subject, role)}
 }
 
-func (_c *Auth_GenerateToken_Call) Run(run func(subject string)) *Auth_GenerateToken_Call {
+func (_c *Auth_GenerateToken_Call) Run(run func(subject string, role string)) *Auth_GenerateToken_Call {
 	_c.Call.Run(func(args mock.Arguments) {
-		run(args[0].(string))
+		run(args[0].(string), args[1].(string))
 	})
 	return _c
 }
@@ -128,7 +131,62 @@ func (_c *Auth_GenerateToken_Call) Return(_a0 string, _a1 error) *Auth_GenerateT
 	return _c
 }
 
-func (_c *Auth_GenerateToken_Call) RunAndReturn(run func(string) (string, error)) *Auth_GenerateToken_Call {
+func (_c *Auth_GenerateToken_Call) RunAndReturn(run func(string, string) (string, error)) *Auth_GenerateToken_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// JWKS provides a mock function with given fields:
+func (_m *Auth) JWKS() (auth.JWKS, error) {
+	ret := _m.Called()
+
+	if len(ret) == 0 {
+		panic("no return value specified for JWKS")
+	}
+
+	var r0 auth.JWKS
+	var r1 error
+	if rf, ok := ret.Get(0).(func() (auth.JWKS, error)); ok {
+		return rf()
+	}
+	if rf, ok := ret.Get(0).(func() auth.JWKS); ok {
+		r0 = rf()
+	} else {
+		r0 = ret.Get(0).(auth.JWKS)
+	}
+
+	if rf, ok := ret.Get(1).(func() error); ok {
+		r1 = rf()
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Auth_JWKS_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'JWKS'
+type Auth_JWKS_Call struct {
+	*mock.Call
+}
+
+// JWKS is a helper method to define mock.On call
+func (_e *Auth_Expecter) JWKS() *Auth_JWKS_Call {
+	return &Auth_JWKS_Call{Call: _e.mock.On("JWKS")}
+}
+
+func (_c *Auth_JWKS_Call) Run(run func()) *Auth_JWKS_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run()
+	})
+	return _c
+}
+
+func (_c *Auth_JWKS_Call) Return(_a0 auth.JWKS, _a1 error) *Auth_JWKS_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Auth_JWKS_Call) RunAndReturn(run func() (auth.JWKS, error)) *Auth_JWKS_Call {
 	_c.Call.Return(run)
 	return _c
 }
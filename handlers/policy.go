@@ -0,0 +1,63 @@
+package handlers
+
+import (
+	"eth-fetcher/database/models"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+)
+
+// policy decides whether a session may call a route. Permissions that depend on
+// the request are checked by the handlers.
+type policy func(Session) error
+
+// requireRole admits sessions with role or a role that includes it.
+func requireRole(role models.Role) policy {
+	return func(s Session) error {
+		if s.UserID == "" {
+			return &ErrorResponse{Msg: "authentication required", Code: http.StatusUnauthorized, Challenge: "Bearer"}
+		}
+		if !s.Role.Includes(role) {
+			return &ErrorResponse{Msg: fmt.Sprintf("the %s role is required", role), Code: http.StatusForbidden}
+		}
+		return nil
+	}
+}
+
+// requireScope admits sessions that are not limited to scopes or include scope.
+// Anonymous sessions pass, routes that need a user also require a role.
+func requireScope(scope models.Scope) policy {
+	return func(s Session) error {
+		if s.Scopes != nil && !slices.Contains(s.Scopes, scope) {
+			return &ErrorResponse{Msg: fmt.Sprintf("the API key lacks the %s scope", scope), Code: http.StatusForbidden}
+		}
+		return nil
+	}
+}
+
+// authorize returns the error of the first policy the session does not pass.
+func authorize(s Session, policies []policy) error {
+	for _, p := range policies {
+		err := p(s)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// invalidTokenChallenge is the WWW-Authenticate challenge of RFC 6750 for a request
+// with invalid credentials. Characters that are not allowed in the description are
+// dropped.
+func invalidTokenChallenge(description string) string {
+	description = strings.Map(func(r rune) rune {
+		if r < 0x20 || r > 0x7e || r == '"' || r == '\\' {
+			return -1
+		}
+		return r
+	}, description)
+
+	return fmt.Sprintf(`Bearer error="invalid_token", error_description="%s"`, description)
+}
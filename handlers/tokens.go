@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+)
+
+// jwksMaxAge is how long clients may cache the JWKS, in seconds. Clients refetch it
+// when a token names an unknown key.
+const jwksMaxAge = 300
+
+// RefreshTokenHandler exchanges a refresh token for a new access token and a new
+// refresh token, the old refresh token cannot be used again.
+func (a *HTTP) RefreshTokenHandler(s Session, r *http.Request) (any, error) {
+	var req RefreshTokenRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	user, refreshToken, err := a.app.RefreshSession(req.RefreshToken)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	token, err := a.auth.GenerateToken(user.ID, string(user.Role))
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusInternalServerError}
+	}
+
+	return AuthenticateResponse{Token: token, RefreshToken: refreshToken}, nil
+}
+
+// LogoutHandler revokes the access token of the request and, if one is sent, the
+// refresh token issued with it.
+func (a *HTTP) LogoutHandler(s Session, r *http.Request) (any, error) {
+	var req LogoutRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil && !errors.Is(err, io.EOF) {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	err = a.app.Logout(s.UserID, s.TokenID, s.TokenExpiresAt, req.RefreshToken)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
+
+// JWKSHandler publishes the public keys verifying the tokens. It is served without
+// authentication so that other services can verify the tokens.
+func (a *HTTP) JWKSHandler(w http.ResponseWriter, r *http.Request) {
+	jwks, err := a.auth.JWKS()
+	if err != nil {
+		a.log.Errorf("error encoding the JWKS: %v", err)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(&ErrorResponse{Msg: err.Error(), Code: http.StatusInternalServerError})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", fmt.Sprintf("public, max-age=%d", jwksMaxAge))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(jwks)
+}
@@ -0,0 +1,145 @@
+package handlers
+
+import (
+	"encoding/json"
+	"eth-fetcher/database/models"
+	"net/http"
+
+	"github.com/gorilla/mux"
+)
+
+// RegisterHandler creates a user for anyone if registration is enabled. The user
+// authenticates with AuthenticateHandler afterwards.
+func (a *HTTP) RegisterHandler(s Session, r *http.Request) (any, error) {
+	var req RegisterRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	user, err := a.app.Register(req.Username, req.Password)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (a *HTTP) GetProfileHandler(s Session, r *http.Request) (any, error) {
+	user, err := a.app.GetProfile(s.UserID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (a *HTTP) ChangePasswordHandler(s Session, r *http.Request) (any, error) {
+	var req ChangePasswordRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	err = a.app.ChangePassword(s.UserID, req.CurrentPassword, req.NewPassword)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
+
+func (a *HTTP) GetUsersHandler(s Session, r *http.Request) (any, error) {
+	users, err := a.app.ListUsers(s.UserID)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	response := GetUsersResponse{Users: make([]UserResponse, len(users))}
+	for i, user := range users {
+		response.Users[i] = newUserResponse(user)
+	}
+
+	return response, nil
+}
+
+func (a *HTTP) AddUserHandler(s Session, r *http.Request) (any, error) {
+	var req AddUserRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	role := req.Role
+	if role == "" {
+		role = models.RoleReader
+	}
+
+	user, err := a.app.AddUser(s.UserID, req.Username, req.Password, role)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (a *HTTP) DeleteUserHandler(s Session, r *http.Request) (any, error) {
+	err := a.app.DeleteUser(s.UserID, mux.Vars(r)["id"])
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
+
+func (a *HTTP) SetUserRoleHandler(s Session, r *http.Request) (any, error) {
+	var req SetUserRoleRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	user, err := a.app.SetUserRole(s.UserID, mux.Vars(r)["id"], req.Role)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (a *HTTP) DisableUserHandler(s Session, r *http.Request) (any, error) {
+	return a.setUserDisabled(s, r, true)
+}
+
+func (a *HTTP) EnableUserHandler(s Session, r *http.Request) (any, error) {
+	return a.setUserDisabled(s, r, false)
+}
+
+func (a *HTTP) setUserDisabled(s Session, r *http.Request, disabled bool) (any, error) {
+	user, err := a.app.SetUserDisabled(s.UserID, mux.Vars(r)["id"], disabled)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return newUserResponse(user), nil
+}
+
+func (a *HTTP) ResetUserPasswordHandler(s Session, r *http.Request) (any, error) {
+	var req ResetPasswordRequest
+
+	err := json.NewDecoder(r.Body).Decode(&req)
+	if err != nil {
+		return nil, &ErrorResponse{Msg: err.Error(), Code: http.StatusBadRequest}
+	}
+
+	err = a.app.ResetUserPassword(s.UserID, mux.Vars(r)["id"], req.Password)
+	if err != nil {
+		return nil, errorResponse(err)
+	}
+
+	return struct{}{}, nil
+}
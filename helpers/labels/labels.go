@@ -0,0 +1,64 @@
+package labels
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"eth-fetcher/database/models"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ParseFile reads address labels from a .csv or .json file.
+//
+// CSV files contain address,name rows with an optional header row. JSON files
+// contain an array of {"address": ..., "name": ...} objects.
+func ParseFile(path string) ([]*models.AddressLabel, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".csv":
+		return ParseCSV(f)
+	case ".json":
+		return ParseJSON(f)
+	default:
+		return nil, fmt.Errorf("unsupported label file format: %s", path)
+	}
+}
+
+func ParseCSV(r io.Reader) ([]*models.AddressLabel, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = 2
+	reader.TrimLeadingSpace = true
+
+	records, err := reader.ReadAll()
+	if err != nil {
+		return nil, err
+	}
+
+	labels := make([]*models.AddressLabel, 0, len(records))
+	for i, record := range records {
+		if i == 0 && strings.EqualFold(record[0], "address") {
+			continue
+		}
+		labels = append(labels, &models.AddressLabel{Address: record[0], Name: record[1]})
+	}
+
+	return labels, nil
+}
+
+func ParseJSON(r io.Reader) ([]*models.AddressLabel, error) {
+	var labels []*models.AddressLabel
+	err := json.NewDecoder(r).Decode(&labels)
+	if err != nil {
+		return nil, err
+	}
+
+	return labels, nil
+}
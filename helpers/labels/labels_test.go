@@ -0,0 +1,86 @@
+package labels_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"eth-fetcher/helpers/labels"
+)
+
+func TestParseFile(t *testing.T) {
+	tests := []struct {
+		name     string
+		file     string
+		content  string
+		expected map[string]string
+		err      bool
+	}{
+		{
+			name:    "CSV with header",
+			file:    "labels.csv",
+			content: "address,name\n0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D,Uniswap V2: Router 2\n0x28C6c06298d514Db089934071355E5743bf21d60, Binance 14\n",
+			expected: map[string]string{
+				"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D": "Uniswap V2: Router 2",
+				"0x28C6c06298d514Db089934071355E5743bf21d60": "Binance 14",
+			},
+		},
+		{
+			name:    "CSV without header",
+			file:    "labels.csv",
+			content: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D,Uniswap V2: Router 2\n",
+			expected: map[string]string{
+				"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D": "Uniswap V2: Router 2",
+			},
+		},
+		{
+			name:    "CSV with missing column",
+			file:    "labels.csv",
+			content: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D\n",
+			err:     true,
+		},
+		{
+			name:    "JSON",
+			file:    "labels.json",
+			content: `[{"address": "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", "name": "Uniswap V2: Router 2"}]`,
+			expected: map[string]string{
+				"0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D": "Uniswap V2: Router 2",
+			},
+		},
+		{
+			name:    "Invalid JSON",
+			file:    "labels.json",
+			content: `{"address": "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D"}`,
+			err:     true,
+		},
+		{
+			name:    "Unsupported format",
+			file:    "labels.txt",
+			content: "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D Uniswap",
+			err:     true,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), test.file)
+			err := os.WriteFile(path, []byte(test.content), 0o600)
+			assert.NoError(t, err)
+
+			result, err := labels.ParseFile(path)
+			if test.err {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+
+			names := make(map[string]string, len(result))
+			for _, label := range result {
+				names[label.Address] = label.Name
+			}
+			assert.Equal(t, test.expected, names)
+		})
+	}
+}
@@ -16,9 +16,14 @@ func RLPtoStrings(rlphex string) ([]string, error) {
 		return nil, err
 	}
 
+	return BytesToStrings(bytes)
+}
+
+// BytesToStrings decodes a binary RLP encoded list of strings.
+func BytesToStrings(b []byte) ([]string, error) {
 	var decoded []string
 
-	err = rlp.DecodeBytes(bytes, &decoded)
+	err := rlp.DecodeBytes(b, &decoded)
 	if err != nil {
 		return nil, err
 	}
@@ -62,3 +62,18 @@ func TestRLPtoStrings(t *testing.T) {
 		})
 	}
 }
+
+func TestBytesToStrings(t *testing.T) {
+	result, err := rlp.BytesToStrings([]byte{0xcc, 0x85, 'h', 'e', 'l', 'l', 'o', 0x85, 'w', 'o', 'r', 'l', 'd'})
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(result) != 2 || result[0] != "hello" || result[1] != "world" {
+		t.Errorf("Expected [hello world], but got %v", result)
+	}
+
+	_, err = rlp.BytesToStrings([]byte("0x9b2f"))
+	if err == nil {
+		t.Errorf("Expected error for non RLP input, but got nil")
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"encoding/json"
+	"errors"
+	"eth-fetcher/config"
+	"eth-fetcher/handlers"
+	"eth-fetcher/helpers/rlp"
+	node "eth-fetcher/nodeconnect"
+	"os"
+
+	"go.uber.org/zap"
+)
+
+// fetch looks up the transactions from the database or the eth node like the API
+// does for unauthenticated requests and prints them as JSON.
+func fetch(cfg *config.Config, args []string, log *zap.SugaredLogger) error {
+	if len(args) == 0 {
+		return errors.New("usage: fetch <hash...>")
+	}
+
+	a, err := newApp(cfg, node.NewNode(cfg.NodeURL(), log), log)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	transactions, err := a.GetTransactionsByHashes("", args)
+	if err != nil {
+		return err
+	}
+
+	return printJSON(handlers.GetTransactionsResponse{Transactions: a.LabelTransactions("", transactions)})
+}
+
+// decodeRLP prints the transaction hashes of an RLP encoded list as JSON.
+func decodeRLP(_ *config.Config, args []string, _ *zap.SugaredLogger) error {
+	if len(args) != 1 {
+		return errors.New("usage: decode-rlp <hex>")
+	}
+
+	hashes, err := rlp.RLPtoStrings(args[0])
+	if err != nil {
+		return err
+	}
+
+	return printJSON(hashes)
+}
+
+func printJSON(v any) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
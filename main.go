@@ -1,49 +1,88 @@
 package main
 
 import (
-	"eth-fetcher/app"
-	"eth-fetcher/auth"
+	"errors"
 	"eth-fetcher/config"
-	"eth-fetcher/database"
-	"eth-fetcher/handlers"
-	node "eth-fetcher/nodeconnect"
+	"flag"
+	"fmt"
+	"io/fs"
 	"os"
-	"os/signal"
 
 	"github.com/joho/godotenv"
 	"go.uber.org/zap"
 )
 
+var (
+	// configLoader reloads the configuration of the running server.
+	configLoader *config.Loader
+	// logLevel follows the configured log level.
+	logLevel = zap.NewAtomicLevel()
+)
+
 func main() {
-	err := godotenv.Load(".env")
-	logger, _ := zap.NewProduction()
+	logConfig := zap.NewProductionConfig()
+	logConfig.Level = logLevel
+	logger, _ := logConfig.Build()
 	defer logger.Sync()
 	sLog := logger.Sugar()
-	if err != nil {
-		sLog.Fatal("error loading .env file")
-	}
 
-	cfg := config.LoadConfig()
+	// The .env file is optional, the settings can come from the environment only
+	err := godotenv.Load(".env")
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		sLog.Fatalf("error loading .env file: %v", err)
+	}
 
-	db, err := database.NewClient(cfg.DBConnectionURL)
+	flags := flag.NewFlagSet("eth-fetcher", flag.ContinueOnError)
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage())
+		flags.PrintDefaults()
+	}
+	configLoader = config.NewLoader(flags)
+	err = flags.Parse(os.Args[1:])
+	if errors.Is(err, flag.ErrHelp) {
+		return
+	}
 	if err != nil {
-		sLog.Fatalf("error creating db client: %v", err)
+		os.Exit(2)
 	}
 
-	tg := node.NewNode(cfg.ETHNodeURL, sLog)
+	name, args := "serve", []string(nil)
+	if flags.NArg() > 0 {
+		name, args = flags.Arg(0), flags.Args()[1:]
+	}
 
-	app := app.NewApp(db, tg, sLog)
+	if name == "help" {
+		flags.SetOutput(os.Stdout)
+		flags.Usage()
+		return
+	}
+	cmd, ok := findCommand(name)
+	if !ok {
+		fmt.Fprintf(os.Stderr, "unknown command %s\n\n", name)
+		flags.Usage()
+		os.Exit(2)
+	}
 
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt)
-	go func() {
-		for range c {
-			app.Shutdown()
-			os.Exit(1)
+	var cfg *config.Config
+	if !cmd.noConfig {
+		load := configLoader.Load
+		if cmd.dbConfig {
+			load = configLoader.LoadDatabase
+		}
+		cfg, err = load()
+		if err != nil {
+			sLog.Fatal(err)
 		}
-	}()
-	jwtAuth := auth.NewJWTAuth(cfg.JWT.Secret, cfg.JWT.Duration)
-	handler := handlers.NewHTTP(app, cfg.APIPort, jwtAuth, sLog)
-	handler.InitRoutes()
-	handler.Run()
+		setLogLevel(cfg.LogLevel)
+	}
+
+	err = cmd.run(cfg, args, sLog)
+	if err != nil {
+		sLog.Fatal(err)
+	}
+}
+
+func setLogLevel(level string) {
+	// The level was validated with the config
+	_ = logLevel.UnmarshalText([]byte(level))
 }
@@ -0,0 +1,33 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCommands_Config(t *testing.T) {
+	tests := map[string]struct {
+		noConfig bool
+		dbConfig bool
+	}{
+		"serve":      {},
+		"fetch":      {},
+		"decode-rlp": {noConfig: true},
+		"users":      {dbConfig: true},
+		"migrate":    {dbConfig: true},
+		"export":     {dbConfig: true},
+		"import":     {dbConfig: true},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			cmd, ok := findCommand(name)
+			require.True(t, ok)
+			assert.Equal(t, test.noConfig, cmd.noConfig, "noConfig")
+			assert.Equal(t, test.dbConfig, cmd.dbConfig, "dbConfig")
+		})
+	}
+	assert.Len(t, commands, len(tests))
+}
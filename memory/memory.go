@@ -0,0 +1,917 @@
+package memory
+
+import (
+	"eth-fetcher/app"
+	"eth-fetcher/database/models"
+	"fmt"
+	"slices"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/segmentio/ksuid"
+)
+
+// DB is an app.DB that keeps everything in memory. It is meant for tests and
+// ephemeral runs, nothing survives a restart.
+type DB struct {
+	mu sync.RWMutex
+
+	transactions map[string]models.Transaction
+	// order keeps the transaction hashes in insertion order
+	order []string
+	// fetchedAt is when each transaction was first stored
+	fetchedAt map[string]time.Time
+	// addresses indexes the hashes of the transactions by their addresses
+	addresses map[string]map[string]struct{}
+
+	users map[string]*models.User
+	// views maps user IDs to their viewed transactions in the order first viewed
+	views map[string][]view
+
+	labels map[labelKey]models.AddressLabel
+	jobs   map[string]models.BackfillJob
+	keys   map[string]models.APIKey
+
+	refreshTokens map[string]models.RefreshToken
+	revoked       map[string]time.Time
+	signingKeys   map[string]models.SigningKey
+}
+
+type view struct {
+	hash     string
+	viewedAt time.Time
+}
+
+type labelKey struct {
+	address string
+	userID  string
+}
+
+// New creates an empty store.
+func New() (*DB, error) {
+	db := &DB{
+		transactions: make(map[string]models.Transaction),
+		fetchedAt:    make(map[string]time.Time),
+		addresses:    make(map[string]map[string]struct{}),
+		users:        make(map[string]*models.User),
+		views:        make(map[string][]view),
+		labels:       make(map[labelKey]models.AddressLabel),
+		jobs:         make(map[string]models.BackfillJob),
+		keys:         make(map[string]models.APIKey),
+
+		refreshTokens: make(map[string]models.RefreshToken),
+		revoked:       make(map[string]time.Time),
+		signingKeys:   make(map[string]models.SigningKey),
+	}
+
+	return db, nil
+}
+
+// SaveTransactions inserts the transactions, the ones that already exist are
+// overwritten with the new values.
+func (db *DB) SaveTransactions(transactions []*models.Transaction) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, tx := range transactions {
+		db.saveTransaction(tx, true)
+	}
+
+	return nil
+}
+
+func (db *DB) GetTransactionsByHashes(hashes []string) ([]*models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var transactions []*models.Transaction
+	for _, hash := range hashes {
+		if tx, ok := db.transactions[hash]; ok {
+			transactions = append(transactions, &tx)
+		}
+	}
+
+	return transactions, nil
+}
+
+func (db *DB) GetAllTransactions() ([]*models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	return db.transactionsByHashes(db.order), nil
+}
+
+// GetTransactionsPage returns up to limit transactions with a hash after the given
+// one, ordered by hash. An empty hash starts at the first page.
+func (db *DB) GetTransactionsPage(after string, limit int) ([]*models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var hashes []string
+	for hash := range db.transactions {
+		if hash > after {
+			hashes = append(hashes, hash)
+		}
+	}
+	slices.Sort(hashes)
+	if len(hashes) > limit {
+		hashes = hashes[:limit]
+	}
+
+	return db.transactionsByHashes(hashes), nil
+}
+
+// GetTransactionsByAddress returns up to limit transactions sent by, sent to or
+// creating the address after the cursor, ordered by block number and hash. The
+// address is expected in lowercase.
+func (db *DB) GetTransactionsByAddress(address string, after *models.TransactionCursor, limit int) ([]*models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var transactions []*models.Transaction
+	for _, tx := range db.addressTransactions(address) {
+		if after != nil && (tx.BlockNumber < after.BlockNumber ||
+			(tx.BlockNumber == after.BlockNumber && tx.TxHash <= after.TxHash)) {
+			continue
+		}
+		transactions = append(transactions, tx)
+	}
+	sort.Slice(transactions, func(i, j int) bool {
+		if transactions[i].BlockNumber != transactions[j].BlockNumber {
+			return transactions[i].BlockNumber < transactions[j].BlockNumber
+		}
+		return transactions[i].TxHash < transactions[j].TxHash
+	})
+	if len(transactions) > limit {
+		transactions = transactions[:limit]
+	}
+
+	return transactions, nil
+}
+
+// GetAddressStats summarizes the transactions sent by, sent to or creating the
+// address. The address is expected in lowercase.
+func (db *DB) GetAddressStats(address string) (*models.AddressStats, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	stats := models.NewAddressStats()
+	for _, tx := range db.addressTransactions(address) {
+		stats.Add(tx, address)
+	}
+
+	return stats, nil
+}
+
+// addressTransactions returns the transactions sent by, sent to or creating the
+// address in no particular order. The caller must hold mu.
+func (db *DB) addressTransactions(address string) []*models.Transaction {
+	transactions := make([]*models.Transaction, 0, len(db.addresses[address]))
+	for hash := range db.addresses[address] {
+		tx := db.transactions[hash]
+		transactions = append(transactions, &tx)
+	}
+	return transactions
+}
+
+// AddUserTransactions stores the transactions that are not stored yet and adds them
+// to the history of the user. Transactions already in the history are marked as
+// viewed again.
+func (db *DB) AddUserTransactions(userID string, transactions []*models.Transaction) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	for _, tx := range transactions {
+		db.saveTransaction(tx, false)
+		i := slices.IndexFunc(db.views[userID], func(v view) bool { return v.hash == tx.TxHash })
+		if i >= 0 {
+			db.views[userID][i].viewedAt = now
+			continue
+		}
+		db.views[userID] = append(db.views[userID], view{hash: tx.TxHash, viewedAt: now})
+	}
+
+	return nil
+}
+
+func (db *DB) GetUserTransactions(userID string) ([]*models.Transaction, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	hashes := make([]string, len(db.views[userID]))
+	for i, v := range db.views[userID] {
+		hashes[i] = v.hash
+	}
+
+	return db.transactionsByHashes(hashes), nil
+}
+
+// GetUserViews returns the history of the user with the time of the last view, oldest
+// first.
+func (db *DB) GetUserViews(userID string) ([]*models.TransactionView, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	views := make([]*models.TransactionView, len(db.views[userID]))
+	for i, v := range db.views[userID] {
+		views[i] = &models.TransactionView{TxHash: v.hash, ViewedAt: v.viewedAt}
+	}
+	sort.SliceStable(views, func(i, j int) bool {
+		return views[i].ViewedAt.Before(views[j].ViewedAt)
+	})
+
+	return views, nil
+}
+
+// RestoreUserViews adds the stored transactions to the history of the user as viewed
+// at the given times. Views already in the history keep the later time.
+func (db *DB) RestoreUserViews(userID string, views []*models.TransactionView) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, v := range views {
+		if _, ok := db.transactions[v.TxHash]; !ok {
+			continue
+		}
+		i := slices.IndexFunc(db.views[userID], func(existing view) bool { return existing.hash == v.TxHash })
+		if i < 0 {
+			db.views[userID] = append(db.views[userID], view{hash: v.TxHash, viewedAt: v.ViewedAt})
+			continue
+		}
+		if v.ViewedAt.After(db.views[userID][i].viewedAt) {
+			db.views[userID][i].viewedAt = v.ViewedAt
+		}
+	}
+
+	return nil
+}
+
+// PruneTransactions deletes the transactions that were fetched and last viewed by any
+// user before notViewedSince, together with their history entries. Transactions from,
+// to or creating a labelled address are kept. With dryRun nothing is deleted, the
+// number of transactions that would be is returned.
+func (db *DB) PruneTransactions(notViewedSince time.Time, dryRun bool) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	lastViewed := make(map[string]time.Time)
+	for _, views := range db.views {
+		for _, v := range views {
+			if v.viewedAt.After(lastViewed[v.hash]) {
+				lastViewed[v.hash] = v.viewedAt
+			}
+		}
+	}
+	labelled := make(map[string]bool, len(db.labels))
+	for key := range db.labels {
+		labelled[key.address] = true
+	}
+
+	pruned := make(map[string]bool)
+	for _, hash := range db.order {
+		tx := db.transactions[hash]
+		if !db.fetchedAt[hash].Before(notViewedSince) || !lastViewed[hash].Before(notViewedSince) ||
+			labelled[tx.From] ||
+			(tx.To.Valid && labelled[tx.To.String]) ||
+			(tx.ContractAddress.Valid && labelled[tx.ContractAddress.String]) {
+			continue
+		}
+		pruned[hash] = true
+	}
+	if dryRun || len(pruned) == 0 {
+		return int64(len(pruned)), nil
+	}
+
+	for userID, views := range db.views {
+		db.views[userID] = slices.DeleteFunc(views, func(v view) bool { return pruned[v.hash] })
+	}
+	db.order = slices.DeleteFunc(db.order, func(hash string) bool { return pruned[hash] })
+	for hash := range pruned {
+		db.unindexAddresses(db.transactions[hash])
+		delete(db.transactions, hash)
+		delete(db.fetchedAt, hash)
+	}
+
+	return int64(len(pruned)), nil
+}
+
+// TrimUserTransactions removes the oldest viewed transactions from the history of every
+// user with more than maxPerUser of them. The transactions themselves are kept. With
+// dryRun nothing is removed, the number of entries that would be is returned.
+func (db *DB) TrimUserTransactions(maxPerUser int, dryRun bool) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var trimmed int64
+	for userID, views := range db.views {
+		if len(views) <= maxPerUser {
+			continue
+		}
+
+		newest := slices.Clone(views)
+		sort.Slice(newest, func(i, j int) bool {
+			if !newest[i].viewedAt.Equal(newest[j].viewedAt) {
+				return newest[i].viewedAt.After(newest[j].viewedAt)
+			}
+			return newest[i].hash < newest[j].hash
+		})
+		excess := make(map[string]bool)
+		for _, v := range newest[maxPerUser:] {
+			excess[v.hash] = true
+		}
+		trimmed += int64(len(excess))
+
+		if !dryRun {
+			db.views[userID] = slices.DeleteFunc(views, func(v view) bool { return excess[v.hash] })
+		}
+	}
+
+	return trimmed, nil
+}
+
+func (db *DB) GetUserByUsername(username string) (*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, user := range db.users {
+		if user.Username == username {
+			u := *user
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user %s %w", username, app.ErrNotFound)
+}
+
+func (db *DB) GetUserByExternalID(issuer, subject string) (*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, user := range db.users {
+		if user.Issuer.Valid && user.Issuer.String == issuer && user.Subject.Valid && user.Subject.String == subject {
+			u := *user
+			return &u, nil
+		}
+	}
+
+	return nil, fmt.Errorf("user %s of %s %w", subject, issuer, app.ErrNotFound)
+}
+
+func (db *DB) GetUserByID(userID string) (*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	user, ok := db.users[userID]
+	if !ok {
+		return nil, fmt.Errorf("user %s %w", userID, app.ErrNotFound)
+	}
+	u := *user
+
+	return &u, nil
+}
+
+// GetUsers returns all users ordered by username.
+func (db *DB) GetUsers() ([]*models.User, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	users := make([]*models.User, 0, len(db.users))
+	for _, user := range db.users {
+		u := *user
+		users = append(users, &u)
+	}
+	sort.Slice(users, func(i, j int) bool {
+		return users[i].Username < users[j].Username
+	})
+
+	return users, nil
+}
+
+// CreateUser inserts the user with a new ID. Usernames are unique.
+func (db *DB) CreateUser(user *models.User) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, u := range db.users {
+		if u.Username == user.Username {
+			return fmt.Errorf("user %s already exists", user.Username)
+		}
+		if user.Issuer.Valid && u.Issuer == user.Issuer && u.Subject == user.Subject {
+			return fmt.Errorf("user %s of %s already exists", user.Subject.String, user.Issuer.String)
+		}
+	}
+
+	user.ID = ksuid.New().String()
+	if user.CreatedAt.IsZero() {
+		user.CreatedAt = time.Now().UTC()
+	}
+	u := *user
+	db.users[u.ID] = &u
+
+	return nil
+}
+
+// UpdateUserPassword replaces the password hash of the user.
+func (db *DB) UpdateUserPassword(userID, password string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	user, ok := db.users[userID]
+	if !ok {
+		return fmt.Errorf("user %s %w", userID, app.ErrNotFound)
+	}
+	user.Password = password
+
+	return nil
+}
+
+// SetUserRole replaces the role of the user.
+func (db *DB) SetUserRole(userID string, role models.Role) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	user, ok := db.users[userID]
+	if !ok {
+		return fmt.Errorf("user %s %w", userID, app.ErrNotFound)
+	}
+	user.Role = role
+
+	return nil
+}
+
+// SetUserDisabled disables or enables the user.
+func (db *DB) SetUserDisabled(userID string, disabled bool) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	user, ok := db.users[userID]
+	if !ok {
+		return fmt.Errorf("user %s %w", userID, app.ErrNotFound)
+	}
+	user.Disabled = disabled
+
+	return nil
+}
+
+// DeleteUser deletes the user together with its history, private labels, API keys and
+// refresh tokens.
+func (db *DB) DeleteUser(userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.users[userID]; !ok {
+		return fmt.Errorf("user %s %w", userID, app.ErrNotFound)
+	}
+	delete(db.users, userID)
+	delete(db.views, userID)
+	for key := range db.labels {
+		if key.userID == userID {
+			delete(db.labels, key)
+		}
+	}
+	for id, key := range db.keys {
+		if key.UserID == userID {
+			delete(db.keys, id)
+		}
+	}
+	for id, token := range db.refreshTokens {
+		if token.UserID == userID {
+			delete(db.refreshTokens, id)
+		}
+	}
+
+	return nil
+}
+
+// CreateAPIKey inserts the API key with a new ID.
+func (db *DB) CreateAPIKey(key *models.APIKey) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, k := range db.keys {
+		if k.Hash == key.Hash {
+			return fmt.Errorf("API key %s already exists", key.Prefix)
+		}
+	}
+
+	key.ID = ksuid.New().String()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now().UTC()
+	}
+	db.keys[key.ID] = *key
+
+	return nil
+}
+
+// GetAPIKeys returns the API keys of the user, oldest first.
+func (db *DB) GetAPIKeys(userID string) ([]*models.APIKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var keys []*models.APIKey
+	for _, key := range db.keys {
+		if key.UserID == userID {
+			key := key
+			keys = append(keys, &key)
+		}
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (db *DB) GetAPIKeyByHash(hash string) (*models.APIKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, key := range db.keys {
+		if key.Hash == hash {
+			return &key, nil
+		}
+	}
+
+	return nil, fmt.Errorf("API key %w", app.ErrNotFound)
+}
+
+// TouchAPIKey sets the time the API key was last used.
+func (db *DB) TouchAPIKey(id string, usedAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, ok := db.keys[id]
+	if !ok {
+		return nil
+	}
+	key.LastUsedAt = &usedAt
+	db.keys[id] = key
+
+	return nil
+}
+
+// DeleteAPIKey deletes the API key of the user.
+func (db *DB) DeleteAPIKey(userID, id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key, ok := db.keys[id]
+	if !ok || key.UserID != userID {
+		return fmt.Errorf("API key %s %w", id, app.ErrNotFound)
+	}
+	delete(db.keys, id)
+
+	return nil
+}
+
+// CreateRefreshToken inserts the refresh token with a new ID.
+func (db *DB) CreateRefreshToken(token *models.RefreshToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, t := range db.refreshTokens {
+		if t.Hash == token.Hash {
+			return fmt.Errorf("refresh token already exists")
+		}
+	}
+
+	token.ID = ksuid.New().String()
+	if token.CreatedAt.IsZero() {
+		token.CreatedAt = time.Now().UTC()
+	}
+	db.refreshTokens[token.ID] = *token
+
+	return nil
+}
+
+func (db *DB) GetRefreshTokenByHash(hash string) (*models.RefreshToken, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	for _, token := range db.refreshTokens {
+		if token.Hash == hash {
+			return &token, nil
+		}
+	}
+
+	return nil, fmt.Errorf("refresh token %w", app.ErrNotFound)
+}
+
+// UseRefreshToken marks the refresh token as exchanged. It fails if the token was
+// exchanged already.
+func (db *DB) UseRefreshToken(id string, usedAt time.Time) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	token, ok := db.refreshTokens[id]
+	if !ok || token.UsedAt != nil {
+		return fmt.Errorf("unused refresh token %s %w", id, app.ErrNotFound)
+	}
+	token.UsedAt = &usedAt
+	db.refreshTokens[id] = token
+
+	return nil
+}
+
+// DeleteRefreshTokenFamily deletes all refresh tokens of the family.
+func (db *DB) DeleteRefreshTokenFamily(familyID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, token := range db.refreshTokens {
+		if token.FamilyID == familyID {
+			delete(db.refreshTokens, id)
+		}
+	}
+
+	return nil
+}
+
+// DeleteUserRefreshTokens deletes all refresh tokens of the user.
+func (db *DB) DeleteUserRefreshTokens(userID string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for id, token := range db.refreshTokens {
+		if token.UserID == userID {
+			delete(db.refreshTokens, id)
+		}
+	}
+
+	return nil
+}
+
+// RevokeToken adds the access token to the revocation list.
+func (db *DB) RevokeToken(token *models.RevokedToken) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	db.revoked[token.ID] = token.ExpiresAt
+
+	return nil
+}
+
+// IsTokenRevoked reports whether the access token is on the revocation list.
+func (db *DB) IsTokenRevoked(id string) (bool, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	_, ok := db.revoked[id]
+
+	return ok, nil
+}
+
+// PruneTokens deletes the refresh tokens and revoked access tokens that expired before
+// now and returns how many.
+func (db *DB) PruneTokens(now time.Time) (int64, error) {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	var pruned int64
+	for id, token := range db.refreshTokens {
+		if token.ExpiresAt.Before(now) {
+			delete(db.refreshTokens, id)
+			pruned++
+		}
+	}
+	for id, expiresAt := range db.revoked {
+		if expiresAt.Before(now) {
+			delete(db.revoked, id)
+			pruned++
+		}
+	}
+
+	return pruned, nil
+}
+
+// CreateSigningKey inserts the signing key with a new ID.
+func (db *DB) CreateSigningKey(key *models.SigningKey) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	key.ID = ksuid.New().String()
+	if key.CreatedAt.IsZero() {
+		key.CreatedAt = time.Now().UTC()
+	}
+	db.signingKeys[key.ID] = *key
+
+	return nil
+}
+
+// GetSigningKeys returns all signing keys, oldest first.
+func (db *DB) GetSigningKeys() ([]*models.SigningKey, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	keys := make([]*models.SigningKey, 0, len(db.signingKeys))
+	for _, key := range db.signingKeys {
+		key := key
+		keys = append(keys, &key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].CreatedAt.Equal(keys[j].CreatedAt) {
+			return keys[i].ID < keys[j].ID
+		}
+		return keys[i].CreatedAt.Before(keys[j].CreatedAt)
+	})
+
+	return keys, nil
+}
+
+func (db *DB) DeleteSigningKey(id string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	if _, ok := db.signingKeys[id]; !ok {
+		return fmt.Errorf("signing key %s %w", id, app.ErrNotFound)
+	}
+	delete(db.signingKeys, id)
+
+	return nil
+}
+
+// SaveAddressLabels inserts the labels, renaming the ones that already exist for
+// the same address and owner.
+func (db *DB) SaveAddressLabels(labels []*models.AddressLabel) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	for _, label := range labels {
+		db.labels[labelKey{address: label.Address, userID: label.UserID}] = *label
+	}
+
+	return nil
+}
+
+// GetAddressLabels returns the global labels together with the private labels of the user.
+func (db *DB) GetAddressLabels(userID string) ([]*models.AddressLabel, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var labels []*models.AddressLabel
+	for _, label := range db.labels {
+		if label.UserID == "" || label.UserID == userID {
+			label := label
+			labels = append(labels, &label)
+		}
+	}
+	sort.Slice(labels, func(i, j int) bool {
+		if labels[i].Address != labels[j].Address {
+			return labels[i].Address < labels[j].Address
+		}
+		return labels[i].UserID < labels[j].UserID
+	})
+
+	return labels, nil
+}
+
+// FindAddressLabels returns the global and the user's private labels for the given addresses.
+func (db *DB) FindAddressLabels(userID string, addresses []string) ([]*models.AddressLabel, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var labels []*models.AddressLabel
+	for _, address := range addresses {
+		for _, owner := range []string{"", userID} {
+			if label, ok := db.labels[labelKey{address: address, userID: owner}]; ok {
+				labels = append(labels, &label)
+			}
+			if userID == "" {
+				break
+			}
+		}
+	}
+
+	return labels, nil
+}
+
+func (db *DB) DeleteAddressLabel(userID, address string) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	delete(db.labels, labelKey{address: address, userID: userID})
+
+	return nil
+}
+
+// SaveBackfillJob inserts a new backfill job or updates an existing one.
+func (db *DB) SaveBackfillJob(job *models.BackfillJob) error {
+	db.mu.Lock()
+	defer db.mu.Unlock()
+
+	now := time.Now()
+	if job.ID == "" {
+		job.ID = ksuid.New().String()
+	}
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = now
+	}
+	job.UpdatedAt = now
+	db.jobs[job.ID] = *job
+
+	return nil
+}
+
+func (db *DB) GetBackfillJob(id string) (*models.BackfillJob, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	job, ok := db.jobs[id]
+	if !ok {
+		return nil, fmt.Errorf("backfill job %s %w", id, app.ErrNotFound)
+	}
+
+	return &job, nil
+}
+
+// GetBackfillJobs returns the backfill jobs with the given status, or all jobs
+// when status is empty, newest first.
+func (db *DB) GetBackfillJobs(status string) ([]*models.BackfillJob, error) {
+	db.mu.RLock()
+	defer db.mu.RUnlock()
+
+	var jobs []*models.BackfillJob
+	for _, job := range db.jobs {
+		if status == "" || job.Status == status {
+			job := job
+			jobs = append(jobs, &job)
+		}
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].CreatedAt.After(jobs[j].CreatedAt)
+	})
+
+	return jobs, nil
+}
+
+func (db *DB) Close() error {
+	return nil
+}
+
+// saveTransaction stores a copy of the transaction without its labels. The caller
+// must hold mu.
+func (db *DB) saveTransaction(tx *models.Transaction, overwrite bool) {
+	previous, exists := db.transactions[tx.TxHash]
+	if exists && !overwrite {
+		return
+	}
+	if exists {
+		db.unindexAddresses(previous)
+	} else {
+		db.order = append(db.order, tx.TxHash)
+		db.fetchedAt[tx.TxHash] = time.Now()
+	}
+
+	stored := *tx
+	stored.LowercaseAddresses()
+	stored.FromLabel = ""
+	stored.ToLabel = ""
+	stored.ContractAddressLabel = ""
+	db.transactions[tx.TxHash] = stored
+	for _, address := range transactionAddresses(stored) {
+		if db.addresses[address] == nil {
+			db.addresses[address] = make(map[string]struct{})
+		}
+		db.addresses[address][tx.TxHash] = struct{}{}
+	}
+}
+
+// unindexAddresses removes the transaction from the address index. The caller must
+// hold mu.
+func (db *DB) unindexAddresses(tx models.Transaction) {
+	for _, address := range transactionAddresses(tx) {
+		delete(db.addresses[address], tx.TxHash)
+		if len(db.addresses[address]) == 0 {
+			delete(db.addresses, address)
+		}
+	}
+}
+
+// transactionAddresses returns the addresses the transaction is indexed by.
+func transactionAddresses(tx models.Transaction) []string {
+	addresses := []string{tx.From}
+	if tx.To.Valid {
+		addresses = append(addresses, tx.To.String)
+	}
+	if tx.ContractAddress.Valid {
+		addresses = append(addresses, tx.ContractAddress.String)
+	}
+	return addresses
+}
+
+// transactionsByHashes returns copies of the stored transactions. The caller must
+// hold mu.
+func (db *DB) transactionsByHashes(hashes []string) []*models.Transaction {
+	transactions := make([]*models.Transaction, 0, len(hashes))
+	for _, hash := range hashes {
+		tx := db.transactions[hash]
+		transactions = append(transactions, &tx)
+	}
+
+	return transactions
+}
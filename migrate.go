@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"eth-fetcher/config"
+	"eth-fetcher/database"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"go.uber.org/zap"
+)
+
+const migrateUsage = "usage: migrate up | down [steps] | goto <version> | version"
+
+// migrate changes the schema of the database to the version given by the arguments.
+func migrate(cfg *config.Config, args []string, log *zap.SugaredLogger) error {
+	if strings.HasPrefix(cfg.DBConnectionURL, "memory://") {
+		return errors.New("the memory store has no schema to migrate")
+	}
+	if len(args) == 0 {
+		return errors.New(migrateUsage)
+	}
+
+	c, err := database.Open(cfg.DatabaseURL(), database.WithConnectRetry(cfg.Database.ConnectTimeout, log))
+	if err != nil {
+		return err
+	}
+	defer c.Close()
+
+	switch args[0] {
+	case "up":
+		err = c.MigrateUp()
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps < 1 {
+				return fmt.Errorf("invalid number of steps %s", args[1])
+			}
+		}
+		err = c.MigrateDown(steps)
+	case "goto":
+		if len(args) < 2 {
+			return errors.New(migrateUsage)
+		}
+		var target int
+		target, err = strconv.Atoi(args[1])
+		if err != nil {
+			return fmt.Errorf("invalid version %s", args[1])
+		}
+		err = c.Migrate(target)
+	case "version":
+	default:
+		return errors.New(migrateUsage)
+	}
+	if err != nil {
+		return err
+	}
+
+	version, err := c.SchemaVersion()
+	if err != nil {
+		return err
+	}
+	latest, err := c.LatestSchemaVersion()
+	if err != nil {
+		return err
+	}
+	log.Infof("database schema is at version %d of %d", version, latest)
+
+	return nil
+}
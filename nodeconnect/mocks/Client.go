@@ -4,11 +4,14 @@ package mocks
 
 import (
 	context "context"
+	big "math/big"
 
 	common "github.com/ethereum/go-ethereum/common"
 
 	mock "github.com/stretchr/testify/mock"
 
+	rpc "github.com/ethereum/go-ethereum/rpc"
+
 	types "github.com/ethereum/go-ethereum/core/types"
 )
 
@@ -25,6 +28,124 @@ func (_m *Client) EXPECT() *Client_Expecter {
 	return &Client_Expecter{mock: &_m.Mock}
 }
 
+// BlockByNumber provides a mock function with given fields: ctx, number
+func (_m *Client) BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error) {
+	ret := _m.Called(ctx, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BlockByNumber")
+	}
+
+	var r0 *types.Block
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *big.Int) (*types.Block, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *big.Int) *types.Block); ok {
+		r0 = rf(ctx, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Block)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *big.Int) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_BlockByNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BlockByNumber'
+type Client_BlockByNumber_Call struct {
+	*mock.Call
+}
+
+// BlockByNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - number *big.Int
+func (_e *Client_Expecter) BlockByNumber(ctx interface{}, number interface{}) *Client_BlockByNumber_Call {
+	return &Client_BlockByNumber_Call{Call: _e.mock.On("BlockByNumber", ctx, number)}
+}
+
+func (_c *Client_BlockByNumber_Call) Run(run func(ctx context.Context, number *big.Int)) *Client_BlockByNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*big.Int))
+	})
+	return _c
+}
+
+func (_c *Client_BlockByNumber_Call) Return(_a0 *types.Block, _a1 error) *Client_BlockByNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_BlockByNumber_Call) RunAndReturn(run func(context.Context, *big.Int) (*types.Block, error)) *Client_BlockByNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
+// BlockReceipts provides a mock function with given fields: ctx, blockNrOrHash
+func (_m *Client) BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error) {
+	ret := _m.Called(ctx, blockNrOrHash)
+
+	if len(ret) == 0 {
+		panic("no return value specified for BlockReceipts")
+	}
+
+	var r0 []*types.Receipt
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, rpc.BlockNumberOrHash) ([]*types.Receipt, error)); ok {
+		return rf(ctx, blockNrOrHash)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, rpc.BlockNumberOrHash) []*types.Receipt); ok {
+		r0 = rf(ctx, blockNrOrHash)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]*types.Receipt)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, rpc.BlockNumberOrHash) error); ok {
+		r1 = rf(ctx, blockNrOrHash)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_BlockReceipts_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'BlockReceipts'
+type Client_BlockReceipts_Call struct {
+	*mock.Call
+}
+
+// BlockReceipts is a helper method to define mock.On call
+//   - ctx context.Context
+//   - blockNrOrHash rpc.BlockNumberOrHash
+func (_e *Client_Expecter) BlockReceipts(ctx interface{}, blockNrOrHash interface{}) *Client_BlockReceipts_Call {
+	return &Client_BlockReceipts_Call{Call: _e.mock.On("BlockReceipts", ctx, blockNrOrHash)}
+}
+
+func (_c *Client_BlockReceipts_Call) Run(run func(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash)) *Client_BlockReceipts_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(rpc.BlockNumberOrHash))
+	})
+	return _c
+}
+
+func (_c *Client_BlockReceipts_Call) Return(_a0 []*types.Receipt, _a1 error) *Client_BlockReceipts_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_BlockReceipts_Call) RunAndReturn(run func(context.Context, rpc.BlockNumberOrHash) ([]*types.Receipt, error)) *Client_BlockReceipts_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // Close provides a mock function with given fields:
 func (_m *Client) Close() {
 	_m.Called()
@@ -57,6 +178,65 @@ func (_c *Client_Close_Call) RunAndReturn(run func()) *Client_Close_Call {
 	return _c
 }
 
+// HeaderByNumber provides a mock function with given fields: ctx, number
+func (_m *Client) HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error) {
+	ret := _m.Called(ctx, number)
+
+	if len(ret) == 0 {
+		panic("no return value specified for HeaderByNumber")
+	}
+
+	var r0 *types.Header
+	var r1 error
+	if rf, ok := ret.Get(0).(func(context.Context, *big.Int) (*types.Header, error)); ok {
+		return rf(ctx, number)
+	}
+	if rf, ok := ret.Get(0).(func(context.Context, *big.Int) *types.Header); ok {
+		r0 = rf(ctx, number)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*types.Header)
+		}
+	}
+
+	if rf, ok := ret.Get(1).(func(context.Context, *big.Int) error); ok {
+		r1 = rf(ctx, number)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Client_HeaderByNumber_Call is a *mock.Call that shadows Run/Return methods with type explicit version for method 'HeaderByNumber'
+type Client_HeaderByNumber_Call struct {
+	*mock.Call
+}
+
+// HeaderByNumber is a helper method to define mock.On call
+//   - ctx context.Context
+//   - number *big.Int
+func (_e *Client_Expecter) HeaderByNumber(ctx interface{}, number interface{}) *Client_HeaderByNumber_Call {
+	return &Client_HeaderByNumber_Call{Call: _e.mock.On("HeaderByNumber", ctx, number)}
+}
+
+func (_c *Client_HeaderByNumber_Call) Run(run func(ctx context.Context, number *big.Int)) *Client_HeaderByNumber_Call {
+	_c.Call.Run(func(args mock.Arguments) {
+		run(args[0].(context.Context), args[1].(*big.Int))
+	})
+	return _c
+}
+
+func (_c *Client_HeaderByNumber_Call) Return(_a0 *types.Header, _a1 error) *Client_HeaderByNumber_Call {
+	_c.Call.Return(_a0, _a1)
+	return _c
+}
+
+func (_c *Client_HeaderByNumber_Call) RunAndReturn(run func(context.Context, *big.Int) (*types.Header, error)) *Client_HeaderByNumber_Call {
+	_c.Call.Return(run)
+	return _c
+}
+
 // TransactionByHash provides a mock function with given fields: ctx, txHash
 func (_m *Client) TransactionByHash(ctx context.Context, txHash common.Hash) (*types.Transaction, bool, error) {
 	ret := _m.Called(ctx, txHash)
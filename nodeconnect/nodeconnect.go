@@ -5,13 +5,15 @@ import (
 	"encoding/hex"
 	"eth-fetcher/database/models"
 	"fmt"
-
+	"math/big"
 	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
 	"go.uber.org/zap"
 	"gopkg.in/guregu/null.v4"
 )
@@ -19,15 +21,24 @@ import (
 type Node struct {
 	Client Client
 	log    *zap.SugaredLogger
+
+	// mu guards Client, which Reconnect replaces.
+	mu sync.RWMutex
 }
 
 type Client interface {
 	ethereum.TransactionReader
+	BlockByNumber(ctx context.Context, number *big.Int) (*types.Block, error)
+	BlockReceipts(ctx context.Context, blockNrOrHash rpc.BlockNumberOrHash) ([]*types.Receipt, error)
+	HeaderByNumber(ctx context.Context, number *big.Int) (*types.Header, error)
 	Close()
 }
 
 const zeroAddress = "0x0000000000000000000000000000000000000000"
 
+// reconnectGrace is how long the requests of a replaced client can still finish.
+const reconnectGrace = time.Minute
+
 func (n *Node) GetTransaction(txID string) (*models.Transaction, error) {
 	txHash := common.HexToHash(txID)
 	tx := &models.Transaction{
@@ -58,13 +69,18 @@ func (n *Node) GetTransaction(txID string) (*models.Transaction, error) {
 func (n *Node) getTxReceiptData(txHash common.Hash, tx *models.Transaction, wg *sync.WaitGroup, ec chan error, m *sync.Mutex) {
 	defer wg.Done()
 
-	txReceipt, err := n.Client.TransactionReceipt(context.Background(), txHash)
+	txReceipt, err := n.client().TransactionReceipt(context.Background(), txHash)
 	if err != nil {
 		ec <- err
 		return
 	}
 
 	m.Lock()
+	setReceiptData(tx, txReceipt)
+	m.Unlock()
+}
+
+func setReceiptData(tx *models.Transaction, txReceipt *types.Receipt) {
 	tx.BlockHash = txReceipt.BlockHash.Hex()
 	tx.TxStatus = int(txReceipt.Status)
 	tx.BlockNumber = txReceipt.BlockNumber.Int64()
@@ -73,13 +89,12 @@ func (n *Node) getTxReceiptData(txHash common.Hash, tx *models.Transaction, wg *
 		tx.ContractAddress = null.NewString(contractAddress, true)
 	}
 	tx.LogsCount = len(txReceipt.Logs)
-	m.Unlock()
 }
 
 func (n *Node) getTxData(txHash common.Hash, tx *models.Transaction, wg *sync.WaitGroup, ec chan error, m *sync.Mutex) {
 	defer wg.Done()
 
-	t, _, err := n.Client.TransactionByHash(context.Background(), txHash)
+	t, _, err := n.client().TransactionByHash(context.Background(), txHash)
 	if err != nil {
 		ec <- err
 		return
@@ -92,6 +107,11 @@ func (n *Node) getTxData(txHash common.Hash, tx *models.Transaction, wg *sync.Wa
 	}
 
 	m.Lock()
+	setTxData(tx, t, from)
+	m.Unlock()
+}
+
+func setTxData(tx *models.Transaction, t *types.Transaction, from common.Address) {
 	tx.From = from.Hex()
 	if t.To() != nil {
 		tx.To = null.NewString(t.To().Hex(), true)
@@ -100,11 +120,84 @@ func (n *Node) getTxData(txHash common.Hash, tx *models.Transaction, wg *sync.Wa
 	_ = hex.Encode(sDec, t.Data())
 	tx.Input = fmt.Sprintf("0x%s", string(sDec))
 	tx.Value = t.Value().Int64()
-	m.Unlock()
+}
+
+// GetBlockTransactions retrieves all transactions of a block together with their receipts.
+func (n *Node) GetBlockTransactions(blockNumber int64) ([]*models.Transaction, error) {
+	number := big.NewInt(blockNumber)
+
+	block, err := n.client().BlockByNumber(context.Background(), number)
+	if err != nil {
+		return nil, fmt.Errorf("error getting block %d:%w", blockNumber, err)
+	}
+
+	receipts, err := n.client().BlockReceipts(context.Background(), rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(blockNumber)))
+	if err != nil {
+		return nil, fmt.Errorf("error getting receipts of block %d:%w", blockNumber, err)
+	}
+
+	if len(receipts) != len(block.Transactions()) {
+		return nil, fmt.Errorf("block %d has %d transactions but %d receipts", blockNumber, len(block.Transactions()), len(receipts))
+	}
+
+	transactions := make([]*models.Transaction, 0, len(receipts))
+	for i, t := range block.Transactions() {
+		from, err := types.Sender(types.LatestSignerForChainID(t.ChainId()), t)
+		if err != nil {
+			return nil, fmt.Errorf("error getting sender of transaction %s:%w", t.Hash().Hex(), err)
+		}
+
+		tx := &models.Transaction{TxHash: t.Hash().Hex()}
+		setTxData(tx, t, from)
+		setReceiptData(tx, receipts[i])
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+// FinalizedBlockNumber returns the number of the latest finalized block.
+func (n *Node) FinalizedBlockNumber() (int64, error) {
+	header, err := n.client().HeaderByNumber(context.Background(), big.NewInt(int64(rpc.FinalizedBlockNumber)))
+	if err != nil {
+		return 0, fmt.Errorf("error getting finalized block:%w", err)
+	}
+
+	return header.Number.Int64(), nil
 }
 
 func (n *Node) Close() {
-	n.Client.Close()
+	n.client().Close()
+}
+
+// Reconnect connects to the node at url and replaces the client. The previous client
+// is kept if the node cannot be reached.
+func (n *Node) Reconnect(url string) error {
+	client, err := ethclient.Dial(url)
+	if err != nil {
+		return err
+	}
+	_, err = client.NetworkID(context.Background())
+	if err != nil {
+		client.Close()
+		return err
+	}
+
+	n.mu.Lock()
+	old := n.Client
+	n.Client = client
+	n.mu.Unlock()
+
+	time.AfterFunc(reconnectGrace, old.Close)
+
+	return nil
+}
+
+func (n *Node) client() Client {
+	n.mu.RLock()
+	defer n.mu.RUnlock()
+
+	return n.Client
 }
 
 func NewNode(url string, log *zap.SugaredLogger) *Node {
@@ -117,7 +210,7 @@ func NewNode(url string, log *zap.SugaredLogger) *Node {
 	}
 
 	return &Node{
-		client,
-		log,
+		Client: client,
+		log:    log,
 	}
 }
@@ -5,10 +5,14 @@ import (
 	node "eth-fetcher/nodeconnect"
 	"eth-fetcher/nodeconnect/mocks"
 	"fmt"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
 	"testing"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
@@ -155,3 +159,75 @@ func TestNode_GetTransaction_FailOnTransactionReceipt(t *testing.T) {
 	assert.ErrorContains(t, err, expected.Error())
 	assert.Nil(t, tx)
 }
+
+func TestNode_GetBlockTransactions_Success(t *testing.T) {
+	txn := getTransactionResponse()
+	txr := getTransactionReceiptResponse()
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(17973645)}).WithBody([]*types.Transaction{txn}, nil)
+	client := mocks.NewClient(t)
+
+	client.EXPECT().BlockByNumber(mock.Anything, big.NewInt(17973645)).Return(block, nil)
+	client.EXPECT().BlockReceipts(mock.Anything, rpc.BlockNumberOrHashWithNumber(17973645)).Return([]*types.Receipt{txr}, nil)
+
+	node := &node.Node{Client: client}
+	txs, err := node.GetBlockTransactions(17973645)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 1)
+	assert.Equal(t, txn.Hash().Hex(), txs[0].TxHash)
+	assert.Equal(t, "0x425Db51efE6971d86512e892BeABA90Bc920Cdda", txs[0].From)
+	assert.Equal(t, "0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D", txs[0].To.String)
+	assert.Equal(t, int64(17973645), txs[0].BlockNumber)
+	assert.Equal(t, 1, txs[0].LogsCount)
+	assert.Equal(t, int64(200000000000000000), txs[0].Value)
+}
+
+func TestNode_GetBlockTransactions_ReceiptsMismatch(t *testing.T) {
+	txn := getTransactionResponse()
+	block := types.NewBlockWithHeader(&types.Header{Number: big.NewInt(17973645)}).WithBody([]*types.Transaction{txn}, nil)
+	client := mocks.NewClient(t)
+
+	client.EXPECT().BlockByNumber(mock.Anything, mock.Anything).Return(block, nil)
+	client.EXPECT().BlockReceipts(mock.Anything, mock.Anything).Return([]*types.Receipt{}, nil)
+
+	node := &node.Node{Client: client}
+	txs, err := node.GetBlockTransactions(17973645)
+	assert.Error(t, err)
+	assert.Nil(t, txs)
+}
+
+func TestNode_FinalizedBlockNumber(t *testing.T) {
+	client := mocks.NewClient(t)
+
+	client.EXPECT().HeaderByNumber(mock.Anything, big.NewInt(int64(rpc.FinalizedBlockNumber))).
+		Return(&types.Header{Number: big.NewInt(19000000)}, nil)
+
+	node := &node.Node{Client: client}
+	number, err := node.FinalizedBlockNumber()
+	assert.NoError(t, err)
+	assert.Equal(t, int64(19000000), number)
+}
+
+func TestNode_Reconnect(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			ID     json.RawMessage `json:"id"`
+			Method string          `json:"method"`
+		}
+		_ = json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"jsonrpc":"2.0","id":%s,"result":"5"}`, req.ID)
+	}))
+	defer server.Close()
+
+	client := mocks.NewClient(t)
+	node := &node.Node{Client: client}
+
+	// The client is kept if the node cannot be reached
+	err := node.Reconnect("http://127.0.0.1:1")
+	assert.Error(t, err)
+	assert.Equal(t, client, node.Client)
+
+	err = node.Reconnect(server.URL)
+	assert.NoError(t, err)
+	assert.NotEqual(t, client, node.Client)
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"eth-fetcher/config"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fsnotify/fsnotify"
+	"go.uber.org/zap"
+)
+
+// reloadDelay collects the file events of a single config file update.
+const reloadDelay = 200 * time.Millisecond
+
+// watchConfig reloads the configuration on SIGHUP and when the config file changes.
+// The reloadable settings are passed to apply, changes of the other settings are
+// logged as they need a restart.
+func watchConfig(cfg *config.Config, log *zap.SugaredLogger, apply func(old, next *config.Config) error) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+
+	file := configLoader.File()
+	var (
+		events chan fsnotify.Event
+		errs   chan error
+	)
+	if file != "" {
+		watcher, err := fsnotify.NewWatcher()
+		if err == nil {
+			// The directory is watched to see files replaced by editors and the
+			// symlink swaps of mounted Kubernetes ConfigMaps
+			err = watcher.Add(filepath.Dir(file))
+		}
+		if err != nil {
+			log.Errorf("error watching config file %s, reload with SIGHUP: %v", file, err)
+		} else {
+			events, errs = watcher.Events, watcher.Errors
+		}
+	}
+
+	timer := time.NewTimer(reloadDelay)
+	timer.Stop()
+
+	go func() {
+		for {
+			select {
+			case <-hup:
+				cfg = reloadConfig(cfg, log, apply)
+			case event := <-events:
+				if changesConfig(file, event) {
+					timer.Reset(reloadDelay)
+				}
+			case err := <-errs:
+				log.Errorf("error watching config file %s: %v", file, err)
+			case <-timer.C:
+				cfg = reloadConfig(cfg, log, apply)
+			}
+		}
+	}()
+}
+
+// changesConfig reports whether the event of the watched directory changes the config
+// file, other files like a SQLite database next to it are ignored.
+func changesConfig(file string, event fsnotify.Event) bool {
+	if event.Op == fsnotify.Chmod {
+		return false
+	}
+	name := filepath.Base(event.Name)
+
+	// Kubernetes swaps the ..data symlink the files of a ConfigMap point through
+	return name == filepath.Base(file) || name == kubernetesDataDir
+}
+
+// kubernetesDataDir is the symlink to the current files of a mounted ConfigMap.
+const kubernetesDataDir = "..data"
+
+// reloadConfig returns the running configuration with the reloaded settings applied,
+// or cfg if it cannot be loaded or applied. Settings that need a restart keep their
+// running values, so their changes are reported again until the restart.
+func reloadConfig(cfg *config.Config, log *zap.SugaredLogger, apply func(old, next *config.Config) error) *config.Config {
+	next, err := configLoader.Load()
+	if err != nil {
+		log.Errorf("error reloading config, keeping the current one: %v", err)
+		return cfg
+	}
+
+	reload, restart := config.Changes(cfg, next)
+	if len(restart) > 0 {
+		log.Warnf("changes of %s need a restart", strings.Join(restart, ", "))
+	}
+	if len(reload) == 0 {
+		if len(restart) == 0 {
+			log.Info("reloaded config, nothing changed")
+		}
+		return cfg
+	}
+
+	next = config.Reloaded(cfg, next)
+	err = apply(cfg, next)
+	if err != nil {
+		log.Errorf("error applying reloaded config: %v", err)
+		return cfg
+	}
+	log.Infof("reloaded %s", strings.Join(reload, ", "))
+
+	return next
+}
@@ -0,0 +1,31 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestChangesConfig(t *testing.T) {
+	file := "/etc/eth-fetcher/config.yaml"
+
+	tests := map[string]struct {
+		event fsnotify.Event
+		want  bool
+	}{
+		"write":           {fsnotify.Event{Name: "/etc/eth-fetcher/config.yaml", Op: fsnotify.Write}, true},
+		"replaced":        {fsnotify.Event{Name: "/etc/eth-fetcher/config.yaml", Op: fsnotify.Create}, true},
+		"chmod":           {fsnotify.Event{Name: "/etc/eth-fetcher/config.yaml", Op: fsnotify.Chmod}, false},
+		"configmap swap":  {fsnotify.Event{Name: "/etc/eth-fetcher/..data", Op: fsnotify.Create}, true},
+		"sqlite database": {fsnotify.Event{Name: "/etc/eth-fetcher/eth-fetcher.db", Op: fsnotify.Write}, false},
+		"sqlite journal":  {fsnotify.Event{Name: "/etc/eth-fetcher/eth-fetcher.db-journal", Op: fsnotify.Create}, false},
+		"editor backup":   {fsnotify.Event{Name: "/etc/eth-fetcher/config.yaml~", Op: fsnotify.Create}, false},
+	}
+
+	for name, test := range tests {
+		t.Run(name, func(t *testing.T) {
+			assert.Equal(t, test.want, changesConfig(file, test.event))
+		})
+	}
+}
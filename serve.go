@@ -0,0 +1,142 @@
+package main
+
+import (
+	"errors"
+	"eth-fetcher/app"
+	"eth-fetcher/auth"
+	"eth-fetcher/config"
+	"eth-fetcher/database/models"
+	"eth-fetcher/handlers"
+	"eth-fetcher/helpers/labels"
+	node "eth-fetcher/nodeconnect"
+	"fmt"
+	"os"
+	"os/signal"
+	"time"
+
+	"go.uber.org/zap"
+)
+
+// serve runs the HTTP API until the process is interrupted.
+func serve(cfg *config.Config, args []string, log *zap.SugaredLogger) error {
+	if len(args) > 0 {
+		return errors.New("usage: serve")
+	}
+
+	var signing app.SigningKeyPolicy
+	if cfg.JWT.Algorithm != "HS256" {
+		signing = app.SigningKeyPolicy{
+			Algorithm: cfg.JWT.Algorithm,
+			Rotation:  cfg.JWT.KeyRotation,
+			TokenTTL:  cfg.JWT.Duration,
+			Secret:    cfg.JWT.Secret,
+		}
+	}
+
+	tg := node.NewNode(cfg.NodeURL(), log)
+	a, err := newApp(cfg, tg, log,
+		app.WithRetention(app.RetentionPolicy{
+			TransactionTTL: time.Duration(cfg.Retention.Days) * 24 * time.Hour,
+			MaxUserHistory: cfg.Retention.MaxUserHistory,
+			Interval:       cfg.Retention.Interval,
+			DryRun:         cfg.Retention.DryRun,
+		}),
+		app.WithRegistration(cfg.Registration),
+		app.WithRefreshTokenTTL(cfg.JWT.RefreshDuration),
+		app.WithSigningKeys(signing),
+	)
+	if err != nil {
+		return err
+	}
+
+	err = a.RotateSigningKeys()
+	if err != nil {
+		return err
+	}
+
+	disabled, err := a.DisableSeedUsers()
+	for _, username := range disabled {
+		log.Warnf("DISABLED user %s, its password was still its name as created by older versions. "+
+			"Reset its password with: users reset-password %s, then: users enable %s", username, username, username)
+	}
+	if err != nil {
+		log.Errorf("error disabling the users created by older versions: %v", err)
+	}
+
+	hasAdmin, err := a.HasAdmin()
+	if err != nil {
+		log.Errorf("error checking for an admin user: %v", err)
+	} else if !hasAdmin {
+		log.Warn("there is no enabled admin user, create one with: users add -role admin <username>")
+	}
+
+	for _, f := range cfg.LabelFiles {
+		l, err := labels.ParseFile(f)
+		if err != nil {
+			return fmt.Errorf("error reading address labels from %s: %w", f, err)
+		}
+		err = a.ImportAddressLabels(l)
+		if err != nil {
+			return fmt.Errorf("error importing address labels from %s: %w", f, err)
+		}
+		log.Infof("imported %d address labels from %s", len(l), f)
+	}
+
+	err = a.ResumeRunningBackfills()
+	if err != nil {
+		log.Errorf("error resuming backfill jobs: %v", err)
+	}
+
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, os.Interrupt)
+	go func() {
+		for range c {
+			a.Shutdown()
+			os.Exit(1)
+		}
+	}()
+	authOpts := []auth.Option{auth.WithAPIKeys(a), auth.WithRevocationList(a)}
+	if signing.Algorithm != "" {
+		authOpts = append(authOpts, auth.WithSigningKeys(signing.Algorithm, a))
+	}
+	jwtAuth := auth.NewJWTAuth(cfg.JWT.Secret, cfg.JWT.Duration, authOpts...)
+	var authenticator handlers.Auth = jwtAuth
+	if cfg.OIDC.Issuer != "" {
+		authenticator, err = auth.NewOIDCAuth(auth.OIDCConfig{
+			Issuer:      cfg.OIDC.Issuer,
+			Audience:    cfg.OIDC.Audience,
+			GroupsClaim: cfg.OIDC.GroupsClaim,
+			RoleGroups: map[models.Role][]string{
+				models.RoleReader:  cfg.OIDC.ReaderGroups,
+				models.RoleAnalyst: cfg.OIDC.AnalystGroups,
+				models.RoleAdmin:   cfg.OIDC.AdminGroups,
+			},
+		}, jwtAuth, a)
+		if err != nil {
+			return err
+		}
+		log.Infof("accepting the tokens of OIDC issuer %s", cfg.OIDC.Issuer)
+	}
+	handler := handlers.NewHTTP(a, cfg.APIPort, authenticator, log)
+	handler.SetCORSOrigins(cfg.CORSOrigins)
+	handler.InitRoutes()
+
+	watchConfig(cfg, log, func(old, next *config.Config) error {
+		setLogLevel(next.LogLevel)
+		handler.SetCORSOrigins(next.CORSOrigins)
+		if next.NodeLimits != old.NodeLimits {
+			a.SetNodeFetchLimits(next.NodeLimits.Concurrency, next.NodeLimits.ConcurrencyPerUser, next.NodeLimits.MaxPending)
+		}
+		if next.NodeURL() != old.NodeURL() {
+			err := tg.Reconnect(next.NodeURL())
+			if err != nil {
+				return fmt.Errorf("error connecting to the eth node: %w", err)
+			}
+		}
+		return nil
+	})
+
+	handler.Run()
+
+	return nil
+}
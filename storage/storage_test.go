@@ -0,0 +1,799 @@
+package storage_test
+
+import (
+	"math"
+	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+	"gopkg.in/guregu/null.v4"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+
+	"eth-fetcher/app"
+	"eth-fetcher/database"
+	"eth-fetcher/database/models"
+	"eth-fetcher/storage"
+)
+
+const (
+	hash1 = "0x9b2f6a3c2e1aed2cccf92ba666c22d053ad0d8a5da7aa1fd5477dcd6577b4524"
+	hash2 = "0x5a57e3051cb92e2d482515b07e7b3d1851722a74654657bd64a14c39ca3f9cf2"
+	hash3 = "0x71b9e2b44d40498c08a62988fac776d0eac0b5b9613c37f9f6f9a4b888a8b057"
+	hash4 = "0x2d4c5ab8e3a1f6b7c9d0e1f2a3b4c5d6e7f8091a2b3c4d5e6f708192a3b4c5d6"
+
+	address1 = "0xf29a6c0f8ee500dc87d0d4eb8b26a6fac7a76767"
+	address2 = "0xb0428bf0d49eb5c2239a815b43e59e124b84e303"
+)
+
+var tx1 = &models.Transaction{
+	TxHash:      hash1,
+	TxStatus:    1,
+	BlockHash:   "0x92557f7e29c39cae6be013ffc817620fcd5233b68405cdfc6e0b5528261e81e5",
+	BlockNumber: 20,
+	From:        address1,
+	To:          null.NewString(address2, true),
+	LogsCount:   1,
+	Input:       "0x",
+	Value:       50000000000000000,
+}
+
+var tx2 = &models.Transaction{
+	TxHash:          hash2,
+	TxStatus:        1,
+	BlockHash:       "0x1a2f5b0f6f3c5e1d0d2c26b2ea0c2c39c4b0a8c1b4f3e5d7a8b6c4d2e0f1a3b5",
+	BlockNumber:     10,
+	From:            address2,
+	ContractAddress: null.NewString(address1, true),
+	Input:           "0x60806040",
+}
+
+var tx3 = &models.Transaction{
+	TxHash:      hash3,
+	TxStatus:    0,
+	BlockHash:   "0x3c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d3e4f5a6b7c8d9e0f1a2b3c4d",
+	BlockNumber: 30,
+	From:        address2,
+	To:          null.NewString(address2, true),
+	Value:       1,
+}
+
+// backends returns the DSN of a fresh store for every test of the conformance suite.
+// SQL databases are migrated to the latest schema. PostgreSQL is only tested when
+// TEST_POSTGRES_DSN is set, its tables are dropped before every test.
+var backends = map[string]func(t *testing.T) string{
+	"memory": func(t *testing.T) string {
+		return "memory://"
+	},
+	"sqlite": func(t *testing.T) string {
+		dsn := "sqlite://" + filepath.Join(t.TempDir(), "eth-fetcher.db")
+		migrate(t, dsn)
+		return dsn
+	},
+	"postgres": func(t *testing.T) string {
+		dsn := os.Getenv("TEST_POSTGRES_DSN")
+		if dsn == "" {
+			t.Skip("TEST_POSTGRES_DSN is not set")
+		}
+		dropPostgresTables(t, dsn)
+		migrate(t, dsn)
+		return dsn
+	},
+}
+
+var conformance = map[string]func(t *testing.T, db app.DB){
+	"Transactions":          testTransactions,
+	"TransactionsByAddress": testTransactionsByAddress,
+	"Users":                 testUsers,
+	"UserTransactions":      testUserTransactions,
+	"UserViews":             testUserViews,
+	"PruneTransactions":     testPruneTransactions,
+	"TrimUserTransactions":  testTrimUserTransactions,
+	"AddressLabels":         testAddressLabels,
+	"BackfillJobs":          testBackfillJobs,
+	"DeleteUser":            testDeleteUser,
+	"APIKeys":               testAPIKeys,
+	"Tokens":                testTokens,
+	"SigningKeys":           testSigningKeys,
+	"ExternalUsers":         testExternalUsers,
+}
+
+func TestConformance(t *testing.T) {
+	for backend, dsn := range backends {
+		t.Run(backend, func(t *testing.T) {
+			for name, test := range conformance {
+				t.Run(name, func(t *testing.T) {
+					db, err := storage.Open(dsn(t))
+					require.NoError(t, err)
+					t.Cleanup(func() {
+						db.Close()
+					})
+
+					createUsers(t, db)
+					test(t, db)
+				})
+			}
+		})
+	}
+}
+
+// createUsers creates the users the tests share, the password of each user is its name.
+func createUsers(t *testing.T, db app.DB) {
+	for _, user := range []struct {
+		name string
+		role models.Role
+	}{
+		{"alice", models.RoleAdmin},
+		{"bob", models.RoleAnalyst},
+		{"carol", models.RoleReader},
+		{"dave", models.RoleReader},
+	} {
+		pass, err := bcrypt.GenerateFromPassword([]byte(user.name), bcrypt.MinCost)
+		require.NoError(t, err)
+		require.NoError(t, db.CreateUser(&models.User{Username: user.name, Password: string(pass), Role: user.role}))
+	}
+}
+
+func dropPostgresTables(t *testing.T, dsn string) {
+	db, err := gorm.Open(postgres.Open(dsn), &gorm.Config{})
+	require.NoError(t, err)
+
+	err = db.Migrator().DropTable("schema_migrations", "user_viewed_transactions", &models.Transaction{}, &models.User{}, &models.AddressLabel{}, &models.BackfillJob{})
+	require.NoError(t, err)
+
+	sqlDB, err := db.DB()
+	require.NoError(t, err)
+	sqlDB.Close()
+}
+
+func migrate(t *testing.T, dsn string) {
+	c, err := database.Open(dsn)
+	require.NoError(t, err)
+	defer c.Close()
+
+	require.NoError(t, c.MigrateUp())
+}
+
+func TestOpen_UnsupportedScheme(t *testing.T) {
+	db, err := storage.Open("mysql://localhost/db")
+	assert.Error(t, err)
+	assert.Nil(t, db)
+}
+
+func testTransactions(t *testing.T, db app.DB) {
+	err := db.SaveTransactions([]*models.Transaction{tx1, tx2})
+	require.NoError(t, err)
+
+	transactions, err := db.GetTransactionsByHashes([]string{hash1, hash2, hash3})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, tx2}, transactions)
+
+	// Saving an existing transaction overwrites it
+	updated := *tx1
+	updated.TxStatus = 0
+	err = db.SaveTransactions([]*models.Transaction{&updated, tx3})
+	require.NoError(t, err)
+
+	transactions, err = db.GetTransactionsByHashes([]string{hash1})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{&updated}, transactions)
+
+	transactions, err = db.GetAllTransactions()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{&updated, tx2, tx3}, transactions)
+
+	// Pages are ordered by hash and continue after the last hash
+	transactions, err = db.GetTransactionsPage("", 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2, tx3}, transactions)
+	transactions, err = db.GetTransactionsPage(hash3, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{&updated}, transactions)
+	transactions, err = db.GetTransactionsPage(hash1, 2)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	// Saving nothing is a no-op
+	assert.NoError(t, db.SaveTransactions(nil))
+}
+
+func testTransactionsByAddress(t *testing.T, db app.DB) {
+	err := db.SaveTransactions([]*models.Transaction{tx1, tx2, tx3})
+	require.NoError(t, err)
+
+	// Transactions are ordered by block number
+	transactions, err := db.GetTransactionsByAddress(address1, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2, tx1}, transactions)
+
+	transactions, err = db.GetTransactionsByAddress(address2, nil, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2, tx1, tx3}, transactions)
+
+	transactions, err = db.GetTransactionsByAddress("0x0000000000000000000000000000000000000000", nil, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	// Pages continue after the cursor
+	transactions, err = db.GetTransactionsByAddress(address2, nil, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2, tx1}, transactions)
+	transactions, err = db.GetTransactionsByAddress(address2, &models.TransactionCursor{BlockNumber: tx1.BlockNumber, TxHash: tx1.TxHash}, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx3}, transactions)
+
+	stats, err := db.GetAddressStats(address2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, stats.TransactionCount)
+	assert.Equal(t, 2, stats.SentCount)
+	assert.Equal(t, 2, stats.ReceivedCount)
+	assert.Equal(t, 0, stats.ContractCreationCount)
+	assert.Equal(t, int64(10), stats.FirstSeenBlock)
+	assert.Equal(t, int64(30), stats.LastSeenBlock)
+	assert.Equal(t, tx1.Value+tx3.Value, stats.TotalValueIn.Int64())
+
+	stats, err = db.GetAddressStats("0x0000000000000000000000000000000000000000")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, stats.TransactionCount)
+
+	// Addresses are stored in lowercase
+	checksummed := &models.Transaction{
+		TxHash:          hash4,
+		BlockNumber:     40,
+		From:            "0xF29A6c0f8eE500dC87d0d4EB8B26a6faC7A76767",
+		ContractAddress: null.NewString("0x7A250d5630B4cF539739dF2C5dAcb4c659F2488D", true),
+	}
+	require.NoError(t, db.SaveTransactions([]*models.Transaction{checksummed}))
+	transactions, err = db.GetTransactionsByAddress("0x7a250d5630b4cf539739df2c5dacb4c659f2488d", nil, 10)
+	assert.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, address1, transactions[0].From)
+
+	// Value totals do not overflow 64 bits
+	whale := "0x00000000219ab540356cbb839cbe05303d7705fa"
+	large := []*models.Transaction{
+		{TxHash: "0x" + strings.Repeat("a", 64), BlockNumber: 50, From: address1, To: null.NewString(whale, true), Value: math.MaxInt64},
+		{TxHash: "0x" + strings.Repeat("b", 64), BlockNumber: 51, From: address1, To: null.NewString(whale, true), Value: math.MaxInt64},
+	}
+	require.NoError(t, db.SaveTransactions(large))
+	stats, err = db.GetAddressStats(whale)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, stats.ReceivedCount)
+	assert.Equal(t, int64(50), stats.FirstSeenBlock)
+	assert.Equal(t, int64(51), stats.LastSeenBlock)
+	want := new(big.Int).Mul(big.NewInt(math.MaxInt64), big.NewInt(2))
+	assert.Equal(t, want.String(), stats.TotalValueIn.String())
+	assert.Equal(t, "0", stats.TotalValueOut.String())
+}
+
+func testUsers(t *testing.T, db app.DB) {
+	user, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	assert.NotEmpty(t, user.ID)
+	assert.Equal(t, models.RoleAdmin, user.Role)
+	assert.NoError(t, bcrypt.CompareHashAndPassword([]byte(user.Password), []byte("alice")))
+
+	byID, err := db.GetUserByID(user.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, user.Username, byID.Username)
+
+	bob, err := db.GetUserByUsername("bob")
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAnalyst, bob.Role)
+
+	_, err = db.GetUserByUsername("mallory")
+	assert.Error(t, err)
+
+	_, err = db.GetUserByID("unknown")
+	assert.Error(t, err)
+
+	users, err := db.GetUsers()
+	assert.NoError(t, err)
+	var names []string
+	for _, u := range users {
+		names = append(names, u.Username)
+	}
+	assert.Equal(t, []string{"alice", "bob", "carol", "dave"}, names)
+
+	erin := &models.User{Username: "erin", Password: "hash", Role: models.RoleReader}
+	err = db.CreateUser(erin)
+	require.NoError(t, err)
+	assert.NotEmpty(t, erin.ID)
+
+	// Usernames are unique
+	assert.Error(t, db.CreateUser(&models.User{Username: "erin"}))
+
+	err = db.UpdateUserPassword(erin.ID, "new hash")
+	assert.NoError(t, err)
+	byID, err = db.GetUserByID(erin.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "new hash", byID.Password)
+
+	assert.Error(t, db.UpdateUserPassword("unknown", "hash"))
+	assert.False(t, byID.Disabled)
+	assert.False(t, byID.CreatedAt.IsZero())
+
+	err = db.SetUserDisabled(erin.ID, true)
+	assert.NoError(t, err)
+	byID, err = db.GetUserByID(erin.ID)
+	assert.NoError(t, err)
+	assert.True(t, byID.Disabled)
+
+	assert.Error(t, db.SetUserDisabled("unknown", true))
+
+	err = db.SetUserRole(erin.ID, models.RoleAnalyst)
+	assert.NoError(t, err)
+	byID, err = db.GetUserByID(erin.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.RoleAnalyst, byID.Role)
+
+	assert.Error(t, db.SetUserRole("unknown", models.RoleAdmin))
+}
+
+func testExternalUsers(t *testing.T, db app.DB) {
+	// Local users have no external ID
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	assert.False(t, alice.Issuer.Valid)
+	assert.False(t, alice.Subject.Valid)
+	_, err = db.GetUserByExternalID("", "")
+	assert.Error(t, err)
+
+	frank := &models.User{
+		Username: "frank",
+		Role:     models.RoleReader,
+		Issuer:   null.StringFrom("https://sso.example.com"),
+		Subject:  null.StringFrom("00u1"),
+	}
+	require.NoError(t, db.CreateUser(frank))
+	// Local users are not required to have unique external IDs
+	require.NoError(t, db.CreateUser(&models.User{Username: "grace", Role: models.RoleReader}))
+
+	byID, err := db.GetUserByExternalID("https://sso.example.com", "00u1")
+	require.NoError(t, err)
+	assert.Equal(t, frank.ID, byID.ID)
+	assert.Equal(t, "frank", byID.Username)
+	assert.Equal(t, "https://sso.example.com", byID.Issuer.String)
+
+	_, err = db.GetUserByExternalID("https://other.example.com", "00u1")
+	assert.Error(t, err)
+	_, err = db.GetUserByExternalID("https://sso.example.com", "00u2")
+	assert.Error(t, err)
+
+	// A subject of an issuer has a single user
+	assert.Error(t, db.CreateUser(&models.User{
+		Username: "frank2",
+		Issuer:   null.StringFrom("https://sso.example.com"),
+		Subject:  null.StringFrom("00u1"),
+	}))
+	require.NoError(t, db.CreateUser(&models.User{
+		Username: "frank3",
+		Issuer:   null.StringFrom("https://other.example.com"),
+		Subject:  null.StringFrom("00u1"),
+	}))
+}
+
+func testDeleteUser(t *testing.T, db app.DB) {
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+	carol, err := db.GetUserByUsername("carol")
+	require.NoError(t, err)
+
+	require.NoError(t, db.SaveTransactions([]*models.Transaction{tx1}))
+	require.NoError(t, db.AddUserTransactions(bob.ID, []*models.Transaction{tx1}))
+	require.NoError(t, db.AddUserTransactions(carol.ID, []*models.Transaction{tx1}))
+	require.NoError(t, db.SaveAddressLabels([]*models.AddressLabel{
+		{Address: address1, UserID: bob.ID, Name: "Private"},
+		{Address: address2, Name: "Global"},
+	}))
+	require.NoError(t, db.CreateAPIKey(&models.APIKey{UserID: bob.ID, Name: "batch", Prefix: "ethf_0000", Hash: "hash"}))
+	require.NoError(t, db.CreateRefreshToken(&models.RefreshToken{UserID: bob.ID, FamilyID: "family", Hash: "hash",
+		ExpiresAt: time.Now().Add(time.Hour).UTC()}))
+
+	err = db.DeleteUser(bob.ID)
+	require.NoError(t, err)
+
+	_, err = db.GetUserByID(bob.ID)
+	assert.Error(t, err)
+	transactions, err := db.GetUserTransactions(bob.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+	labels, err := db.GetAddressLabels(bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AddressLabel{{Address: address2, Name: "Global"}}, labels)
+	keys, err := db.GetAPIKeys(bob.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, keys)
+	_, err = db.GetRefreshTokenByHash("hash")
+	assert.Error(t, err)
+
+	// The history of other users and the transactions are kept
+	transactions, err = db.GetUserTransactions(carol.ID)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 1)
+
+	assert.Error(t, db.DeleteUser(bob.ID))
+}
+
+func testAPIKeys(t *testing.T, db app.DB) {
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+	carol, err := db.GetUserByUsername("carol")
+	require.NoError(t, err)
+
+	expiresAt := time.Now().Add(time.Hour).UTC().Truncate(time.Millisecond)
+	key1 := &models.APIKey{UserID: bob.ID, Name: "batch", Prefix: "ethf_0001", Hash: "hash1",
+		Scopes: []models.Scope{models.ScopeLabels, models.ScopeTransactions}, ExpiresAt: &expiresAt}
+	require.NoError(t, db.CreateAPIKey(key1))
+	assert.NotEmpty(t, key1.ID)
+	key2 := &models.APIKey{UserID: bob.ID, Name: "cron", Prefix: "ethf_0002", Hash: "hash2"}
+	require.NoError(t, db.CreateAPIKey(key2))
+	require.NoError(t, db.CreateAPIKey(&models.APIKey{UserID: carol.ID, Name: "batch", Prefix: "ethf_0003", Hash: "hash3"}))
+
+	// Hashes are unique
+	assert.Error(t, db.CreateAPIKey(&models.APIKey{UserID: carol.ID, Name: "copy", Prefix: "ethf_0001", Hash: "hash1"}))
+
+	keys, err := db.GetAPIKeys(bob.ID)
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, "batch", keys[0].Name)
+	assert.Equal(t, []models.Scope{models.ScopeLabels, models.ScopeTransactions}, keys[0].Scopes)
+	assert.True(t, expiresAt.Equal(*keys[0].ExpiresAt))
+	assert.Nil(t, keys[0].LastUsedAt)
+	assert.Equal(t, "cron", keys[1].Name)
+	assert.Empty(t, keys[1].Scopes)
+	assert.Nil(t, keys[1].ExpiresAt)
+
+	key, err := db.GetAPIKeyByHash("hash1")
+	require.NoError(t, err)
+	assert.Equal(t, key1.ID, key.ID)
+	assert.Equal(t, bob.ID, key.UserID)
+	_, err = db.GetAPIKeyByHash("unknown")
+	assert.Error(t, err)
+
+	usedAt := time.Now().UTC().Truncate(time.Millisecond)
+	require.NoError(t, db.TouchAPIKey(key1.ID, usedAt))
+	key, err = db.GetAPIKeyByHash("hash1")
+	require.NoError(t, err)
+	require.NotNil(t, key.LastUsedAt)
+	assert.True(t, usedAt.Equal(*key.LastUsedAt))
+
+	// Keys are deleted by their owner only
+	assert.Error(t, db.DeleteAPIKey(carol.ID, key1.ID))
+	require.NoError(t, db.DeleteAPIKey(bob.ID, key1.ID))
+	_, err = db.GetAPIKeyByHash("hash1")
+	assert.Error(t, err)
+	keys, err = db.GetAPIKeys(bob.ID)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func testTokens(t *testing.T, db app.DB) {
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+	carol, err := db.GetUserByUsername("carol")
+	require.NoError(t, err)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	token1 := &models.RefreshToken{UserID: bob.ID, FamilyID: "family1", Hash: "hash1", ExpiresAt: now.Add(time.Hour)}
+	require.NoError(t, db.CreateRefreshToken(token1))
+	assert.NotEmpty(t, token1.ID)
+	require.NoError(t, db.CreateRefreshToken(&models.RefreshToken{UserID: bob.ID, FamilyID: "family1", Hash: "hash2", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, db.CreateRefreshToken(&models.RefreshToken{UserID: bob.ID, FamilyID: "family2", Hash: "hash3", ExpiresAt: now.Add(-time.Hour)}))
+	require.NoError(t, db.CreateRefreshToken(&models.RefreshToken{UserID: carol.ID, FamilyID: "family3", Hash: "hash4", ExpiresAt: now.Add(time.Hour)}))
+
+	// Hashes are unique
+	assert.Error(t, db.CreateRefreshToken(&models.RefreshToken{UserID: carol.ID, FamilyID: "family3", Hash: "hash1", ExpiresAt: now}))
+
+	token, err := db.GetRefreshTokenByHash("hash1")
+	require.NoError(t, err)
+	assert.Equal(t, token1.ID, token.ID)
+	assert.Equal(t, "family1", token.FamilyID)
+	assert.True(t, now.Add(time.Hour).Equal(token.ExpiresAt))
+	assert.Nil(t, token.UsedAt)
+	_, err = db.GetRefreshTokenByHash("unknown")
+	assert.Error(t, err)
+
+	// Tokens are used once
+	require.NoError(t, db.UseRefreshToken(token1.ID, now))
+	assert.Error(t, db.UseRefreshToken(token1.ID, now))
+	token, err = db.GetRefreshTokenByHash("hash1")
+	require.NoError(t, err)
+	require.NotNil(t, token.UsedAt)
+	assert.True(t, now.Equal(*token.UsedAt))
+
+	require.NoError(t, db.DeleteRefreshTokenFamily("family1"))
+	_, err = db.GetRefreshTokenByHash("hash2")
+	assert.Error(t, err)
+	_, err = db.GetRefreshTokenByHash("hash3")
+	assert.NoError(t, err)
+
+	require.NoError(t, db.RevokeToken(&models.RevokedToken{ID: "jti1", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, db.RevokeToken(&models.RevokedToken{ID: "jti1", ExpiresAt: now.Add(time.Hour)}))
+	require.NoError(t, db.RevokeToken(&models.RevokedToken{ID: "jti2", ExpiresAt: now.Add(-time.Hour)}))
+	revoked, err := db.IsTokenRevoked("jti1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+	revoked, err = db.IsTokenRevoked("jti3")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+
+	// Expired refresh tokens and revoked tokens are pruned
+	pruned, err := db.PruneTokens(now)
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), pruned)
+	_, err = db.GetRefreshTokenByHash("hash3")
+	assert.Error(t, err)
+	revoked, err = db.IsTokenRevoked("jti2")
+	assert.NoError(t, err)
+	assert.False(t, revoked)
+	revoked, err = db.IsTokenRevoked("jti1")
+	assert.NoError(t, err)
+	assert.True(t, revoked)
+
+	require.NoError(t, db.DeleteUserRefreshTokens(carol.ID))
+	_, err = db.GetRefreshTokenByHash("hash4")
+	assert.Error(t, err)
+}
+
+func testSigningKeys(t *testing.T, db app.DB) {
+	keys, err := db.GetSigningKeys()
+	require.NoError(t, err)
+	assert.Empty(t, keys)
+
+	now := time.Now().UTC().Truncate(time.Millisecond)
+	key1 := &models.SigningKey{Algorithm: "RS256", PublicKey: []byte{1, 2}, PrivateKey: []byte{3, 4}, CreatedAt: now.Add(-time.Hour)}
+	require.NoError(t, db.CreateSigningKey(key1))
+	assert.NotEmpty(t, key1.ID)
+	key2 := &models.SigningKey{Algorithm: "ES256", PublicKey: []byte{5}, PrivateKey: []byte{6}, CreatedAt: now}
+	require.NoError(t, db.CreateSigningKey(key2))
+
+	keys, err = db.GetSigningKeys()
+	require.NoError(t, err)
+	require.Len(t, keys, 2)
+	assert.Equal(t, key1.ID, keys[0].ID)
+	assert.Equal(t, "RS256", keys[0].Algorithm)
+	assert.Equal(t, []byte{1, 2}, keys[0].PublicKey)
+	assert.Equal(t, []byte{3, 4}, keys[0].PrivateKey)
+	assert.True(t, now.Add(-time.Hour).Equal(keys[0].CreatedAt))
+	assert.Equal(t, key2.ID, keys[1].ID)
+
+	require.NoError(t, db.DeleteSigningKey(key1.ID))
+	assert.Error(t, db.DeleteSigningKey(key1.ID))
+	keys, err = db.GetSigningKeys()
+	assert.NoError(t, err)
+	assert.Len(t, keys, 1)
+}
+
+func testUserTransactions(t *testing.T, db app.DB) {
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+
+	err = db.SaveTransactions([]*models.Transaction{tx1})
+	require.NoError(t, err)
+
+	// Viewing a transaction again does not duplicate it, unknown transactions are stored
+	assert.NoError(t, db.AddUserTransactions(alice.ID, []*models.Transaction{tx1}))
+	assert.NoError(t, db.AddUserTransactions(alice.ID, []*models.Transaction{tx1, tx3}))
+
+	transactions, err := db.GetUserTransactions(alice.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, tx3}, transactions)
+
+	transactions, err = db.GetTransactionsByHashes([]string{hash3})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx3}, transactions)
+
+	transactions, err = db.GetUserTransactions(bob.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+}
+
+func testUserViews(t *testing.T, db app.DB) {
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+
+	require.NoError(t, db.SaveTransactions([]*models.Transaction{tx1, tx2}))
+
+	earlier := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	later := earlier.Add(time.Hour)
+	err = db.RestoreUserViews(alice.ID, []*models.TransactionView{
+		{TxHash: hash1, ViewedAt: later},
+		{TxHash: hash2, ViewedAt: earlier},
+	})
+	require.NoError(t, err)
+
+	// Restoring again keeps the later time of each view
+	err = db.RestoreUserViews(alice.ID, []*models.TransactionView{
+		{TxHash: hash1, ViewedAt: earlier},
+		{TxHash: hash2, ViewedAt: later.Add(time.Hour)},
+	})
+	require.NoError(t, err)
+
+	views, err := db.GetUserViews(alice.ID)
+	require.NoError(t, err)
+	require.Len(t, views, 2)
+	assert.Equal(t, hash1, views[0].TxHash)
+	assert.True(t, later.Equal(views[0].ViewedAt), views[0].ViewedAt)
+	assert.Equal(t, hash2, views[1].TxHash)
+	assert.True(t, later.Add(time.Hour).Equal(views[1].ViewedAt), views[1].ViewedAt)
+}
+
+func testPruneTransactions(t *testing.T, db app.DB) {
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+
+	tx4 := *tx3
+	tx4.TxHash = hash4
+
+	err = db.SaveTransactions([]*models.Transaction{tx1, tx3, &tx4})
+	require.NoError(t, err)
+	// Private labels keep the transactions too
+	err = db.SaveAddressLabels([]*models.AddressLabel{{Address: address1, UserID: "user1", Name: "Exchange"}})
+	require.NoError(t, err)
+	require.NoError(t, db.AddUserTransactions(bob.ID, []*models.Transaction{tx3}))
+
+	time.Sleep(10 * time.Millisecond)
+	cutoff := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	require.NoError(t, db.AddUserTransactions(alice.ID, []*models.Transaction{&tx4}))
+
+	// A dry run only counts tx3, tx1 is labelled and tx4 was viewed after the cutoff
+	pruned, err := db.PruneTransactions(cutoff, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	transactions, err := db.GetAllTransactions()
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 3)
+
+	pruned, err = db.PruneTransactions(cutoff, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), pruned)
+
+	transactions, err = db.GetAllTransactions()
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, &tx4}, transactions)
+
+	transactions, err = db.GetUserTransactions(bob.ID)
+	assert.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	transactions, err = db.GetUserTransactions(alice.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{&tx4}, transactions)
+}
+
+func testTrimUserTransactions(t *testing.T, db app.DB) {
+	alice, err := db.GetUserByUsername("alice")
+	require.NoError(t, err)
+	bob, err := db.GetUserByUsername("bob")
+	require.NoError(t, err)
+
+	// Viewing tx1 again makes tx2 the oldest entry of alice
+	for _, tx := range []*models.Transaction{tx1, tx2, tx3, tx1} {
+		require.NoError(t, db.AddUserTransactions(alice.ID, []*models.Transaction{tx}))
+		time.Sleep(10 * time.Millisecond)
+	}
+	require.NoError(t, db.AddUserTransactions(bob.ID, []*models.Transaction{tx2}))
+
+	trimmed, err := db.TrimUserTransactions(2, true)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), trimmed)
+
+	transactions, err := db.GetUserTransactions(alice.ID)
+	assert.NoError(t, err)
+	assert.Len(t, transactions, 3)
+
+	trimmed, err = db.TrimUserTransactions(2, false)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), trimmed)
+
+	transactions, err = db.GetUserTransactions(alice.ID)
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.Transaction{tx1, tx3}, transactions)
+
+	transactions, err = db.GetUserTransactions(bob.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2}, transactions)
+
+	// The transactions themselves are kept
+	transactions, err = db.GetTransactionsByHashes([]string{hash2})
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.Transaction{tx2}, transactions)
+}
+
+func testAddressLabels(t *testing.T, db app.DB) {
+	global := &models.AddressLabel{Address: address1, Name: "Exchange"}
+	private := &models.AddressLabel{Address: address2, UserID: "user1", Name: "My wallet"}
+	other := &models.AddressLabel{Address: address2, UserID: "user2", Name: "Their wallet"}
+
+	err := db.SaveAddressLabels([]*models.AddressLabel{global, private, other})
+	require.NoError(t, err)
+
+	// Saving an existing label renames it
+	renamed := &models.AddressLabel{Address: address1, Name: "Hot wallet"}
+	err = db.SaveAddressLabels([]*models.AddressLabel{renamed})
+	require.NoError(t, err)
+
+	labels, err := db.GetAddressLabels("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AddressLabel{private, renamed}, labels)
+
+	labels, err = db.GetAddressLabels("")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AddressLabel{renamed}, labels)
+
+	labels, err = db.FindAddressLabels("user2", []string{address1, address2, "0x0000000000000000000000000000000000000000"})
+	assert.NoError(t, err)
+	assert.ElementsMatch(t, []*models.AddressLabel{renamed, other}, labels)
+
+	err = db.DeleteAddressLabel("user1", address2)
+	assert.NoError(t, err)
+
+	labels, err = db.GetAddressLabels("user1")
+	assert.NoError(t, err)
+	assert.Equal(t, []*models.AddressLabel{renamed}, labels)
+
+	// Deleting a missing label is not an error
+	assert.NoError(t, db.DeleteAddressLabel("user1", address2))
+}
+
+func testBackfillJobs(t *testing.T, db app.DB) {
+	first := &models.BackfillJob{FromBlock: 1, ToBlock: 10, Checkpoint: 1, Status: models.BackfillStatusRunning}
+	err := db.SaveBackfillJob(first)
+	require.NoError(t, err)
+	assert.NotEmpty(t, first.ID)
+
+	time.Sleep(10 * time.Millisecond)
+
+	second := &models.BackfillJob{UserID: "user1", Address: address1, FromBlock: 11, ToBlock: 20, Checkpoint: 11, Status: models.BackfillStatusRunning}
+	err = db.SaveBackfillJob(second)
+	require.NoError(t, err)
+
+	job, err := db.GetBackfillJob(second.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, "user1", job.UserID)
+	assert.Equal(t, address1, job.Address)
+
+	// Saving an existing job updates it
+	first.Checkpoint = 11
+	first.Transactions = 5
+	first.Status = models.BackfillStatusCompleted
+	err = db.SaveBackfillJob(first)
+	require.NoError(t, err)
+
+	job, err = db.GetBackfillJob(first.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(11), job.Checkpoint)
+	assert.Equal(t, int64(5), job.Transactions)
+	assert.Equal(t, models.BackfillStatusCompleted, job.Status)
+	assert.False(t, job.CreatedAt.IsZero())
+
+	jobs, err := db.GetBackfillJobs("")
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 2) {
+		assert.Equal(t, second.ID, jobs[0].ID)
+		assert.Equal(t, first.ID, jobs[1].ID)
+	}
+
+	jobs, err = db.GetBackfillJobs(models.BackfillStatusRunning)
+	assert.NoError(t, err)
+	if assert.Len(t, jobs, 1) {
+		assert.Equal(t, second.ID, jobs[0].ID)
+	}
+
+	_, err = db.GetBackfillJob("unknown")
+	assert.Error(t, err)
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"bufio"
+	"errors"
+	"eth-fetcher/config"
+	"eth-fetcher/database/models"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"go.uber.org/zap"
+)
+
+const usersUsage = "usage: users list | add [-role reader|analyst|admin] [-password <password>] <username> | reset-password [-password <password>] <username> | enable <username>"
+
+// users lists, adds, enables and changes the passwords of users. Passwords not given with
+// -password are read from the first line of stdin.
+func users(cfg *config.Config, args []string, log *zap.SugaredLogger) error {
+	if len(args) == 0 {
+		return errors.New(usersUsage)
+	}
+
+	flags := flag.NewFlagSet("users "+args[0], flag.ContinueOnError)
+	role := flags.String("role", string(models.RoleReader), "role of the user: reader, analyst or admin")
+	password := flags.String("password", "", "password of the user, read from stdin when empty")
+	err := flags.Parse(args[1:])
+	if err != nil {
+		return errors.New(usersUsage)
+	}
+
+	switch args[0] {
+	case "list":
+		if flags.NArg() != 0 {
+			return errors.New(usersUsage)
+		}
+	case "enable":
+		if flags.NArg() != 1 {
+			return errors.New(usersUsage)
+		}
+	case "add", "reset-password":
+		if flags.NArg() != 1 {
+			return errors.New(usersUsage)
+		}
+		if *password == "" {
+			*password, err = readPassword(os.Stdin)
+			if err != nil {
+				return err
+			}
+		}
+	default:
+		return errors.New(usersUsage)
+	}
+
+	a, err := newOfflineApp(cfg, log)
+	if err != nil {
+		return err
+	}
+	defer a.Shutdown()
+
+	switch args[0] {
+	case "list":
+		list, err := a.GetUsers()
+		if err != nil {
+			return err
+		}
+		w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+		fmt.Fprintln(w, "ID\tUSERNAME\tROLE\tDISABLED")
+		for _, user := range list {
+			fmt.Fprintf(w, "%s\t%s\t%s\t%t\n", user.ID, user.Username, user.Role, user.Disabled)
+		}
+		return w.Flush()
+	case "add":
+		user, err := a.CreateUser(flags.Arg(0), *password, models.Role(*role))
+		if err != nil {
+			return err
+		}
+		log.Infof("created user %s with ID %s", user.Username, user.ID)
+	case "reset-password":
+		err = a.ResetPassword(flags.Arg(0), *password)
+		if err != nil {
+			return err
+		}
+		log.Infof("changed the password of %s", flags.Arg(0))
+	case "enable":
+		err = a.EnableUser(flags.Arg(0))
+		if err != nil {
+			return err
+		}
+		log.Infof("enabled %s", flags.Arg(0))
+	}
+
+	return nil
+}
+
+func readPassword(r io.Reader) (string, error) {
+	line, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && !errors.Is(err, io.EOF) {
+		return "", err
+	}
+
+	return strings.TrimRight(line, "\r\n"), nil
+}